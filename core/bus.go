@@ -0,0 +1,256 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"findme/schema"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// ChatEventsChannel -> Redis channel the app replicas relay their chat events over
+	ChatEventsChannel = "chat:events"
+
+	// PresenceTTL -> Time a user stays online on a replica that stopped sending heartbeats
+	PresenceTTL = 60 * time.Second
+
+	// presenceInterval -> Time between the presence heartbeats of a replica
+	presenceInterval = PresenceTTL / 3
+
+	// AwayAfter -> Time without any frame from a user's sockets after which they show as away
+	AwayAfter = 5 * time.Minute
+
+	// ChatReplayKey -> Redis sorted set of the recent chat events, scored by their seq, for event streams resuming
+	// after a dropped connection
+	ChatReplayKey = "chat:replay"
+
+	// ReplayWindow -> Age of the oldest event kept for replay, older streams are told to resync
+	ReplayWindow = 2 * time.Minute
+
+	// ReplaySize -> Most events kept for replay, whatever their age
+	ReplaySize = 5000
+)
+
+// Presence states of a user
+const (
+	PresenceOnline  = "online"
+	PresenceAway    = "away"
+	PresenceOffline = "offline"
+)
+
+// Kinds of the messages relayed between the replicas
+const (
+	BusEvent       = "event"
+	BusKick        = "kick"
+	BusSubscribe   = "subscribe"
+	BusUnsubscribe = "unsubscribe"
+)
+
+// BusMessage -> A hub action relayed to the other replicas, Instance is the id of the replica that published it
+type BusMessage struct {
+	Instance string             `json:"instance"`
+	Kind     string             `json:"kind"`
+	ChatID   string             `json:"chat_id,omitempty"`
+	UserID   string             `json:"user_id,omitempty"`
+	Reason   string             `json:"reason,omitempty"`
+	Data     *schema.WSEnvelope `json:"data,omitempty"`
+}
+
+// ChatBus -> Relays the chat hub events between the app replicas and tracks the users connected to any of them
+type ChatBus interface {
+	PublishEvent(msg *BusMessage) error
+	SubscribeEvents(ctx context.Context) <-chan *BusMessage
+	Heartbeat(instance string, users map[string]string, ttl time.Duration) error
+	RemovePresence(instance string, users []string) error
+	Presence(users []string) (map[string]string, error)
+	AppendReplay(msg *BusMessage) error
+	Replay(after int64) ([]*BusMessage, error)
+}
+
+func presenceKey(uid string) string {
+	return "presence:" + uid
+}
+
+// PublishEvent -> Publishes a hub action on the chat events channel
+func (c *RDB) PublishEvent(msg *BusMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
+	defer cancel()
+
+	if err := c.Cache.Publish(ctx, ChatEventsChannel, data).Err(); err != nil {
+		c.logError("Failed to publish chat event", err)
+		return err
+	}
+	return nil
+}
+
+// SubscribeEvents -> Streams the hub actions published by every replica until ctx is done,
+// the redis client resubscribes on its own after a lost connection
+func (c *RDB) SubscribeEvents(ctx context.Context) <-chan *BusMessage {
+	pubsub := c.Cache.Subscribe(ctx, ChatEventsChannel)
+	out := make(chan *BusMessage, 100)
+
+	go func() {
+		defer close(out)
+
+		for raw := range pubsub.Channel() {
+			var msg BusMessage
+			if err := json.Unmarshal([]byte(raw.Payload), &msg); err != nil {
+				slog.WarnContext(ctx, "Dropped an invalid chat event", "component", "chat", "err", err)
+				continue
+			}
+
+			select {
+			case out <- &msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		_ = pubsub.Close()
+	}()
+
+	return out
+}
+
+// AppendReplay -> Adds an event to the replay buffer, dropping the events past the replay window or size
+func (c *RDB) AppendReplay(msg *BusMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
+	defer cancel()
+
+	cutoff := strconv.FormatInt(time.Now().Add(-ReplayWindow).UnixMicro(), 10)
+	pipe := c.Cache.Pipeline()
+	pipe.ZAdd(ctx, ChatReplayKey, redis.Z{Score: float64(msg.Data.Seq), Member: data})
+	pipe.ZRemRangeByScore(ctx, ChatReplayKey, "-inf", "("+cutoff)
+	pipe.ZRemRangeByRank(ctx, ChatReplayKey, 0, -ReplaySize-1)
+	pipe.Expire(ctx, ChatReplayKey, 2*ReplayWindow)
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.logError("Failed to buffer chat event", err)
+		return err
+	}
+	return nil
+}
+
+// Replay -> Returns the buffered events published after the given seq, oldest first
+func (c *RDB) Replay(after int64) ([]*BusMessage, error) {
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
+	defer cancel()
+
+	members, err := c.Cache.ZRangeByScore(ctx, ChatReplayKey, &redis.ZRangeBy{Min: "(" + strconv.FormatInt(after, 10), Max: "+inf"}).Result()
+	if err != nil && err != redis.Nil {
+		c.logError("Failed to replay chat events", err)
+		return nil, err
+	}
+
+	msgs := make([]*BusMessage, 0, len(members))
+	for _, member := range members {
+		var msg BusMessage
+		if err := json.Unmarshal([]byte(member), &msg); err != nil || msg.Data == nil {
+			continue
+		}
+		msgs = append(msgs, &msg)
+	}
+	return msgs, nil
+}
+
+// presenceMember -> Member of the presence set of a user for a replica in the given state
+func presenceMember(instance, status string) string {
+	return instance + "|" + status
+}
+
+// Heartbeat -> Marks the users (id -> online or away) as connected to the replica for ttl,
+// each user has a sorted set of "<replica>|<state>" members scored by expiry
+func (c *RDB) Heartbeat(instance string, users map[string]string, ttl time.Duration) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
+	defer cancel()
+
+	expiry := float64(time.Now().Add(ttl).Unix())
+	pipe := c.Cache.Pipeline()
+	for uid, status := range users {
+		pipe.ZRem(ctx, presenceKey(uid), presenceMember(instance, PresenceOnline), presenceMember(instance, PresenceAway))
+		pipe.ZAdd(ctx, presenceKey(uid), redis.Z{Score: expiry, Member: presenceMember(instance, status)})
+		pipe.Expire(ctx, presenceKey(uid), ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.logError("Failed to refresh presence", err)
+		return err
+	}
+	return nil
+}
+
+// RemovePresence -> Marks the users as no longer connected to the replica
+func (c *RDB) RemovePresence(instance string, users []string) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
+	defer cancel()
+
+	pipe := c.Cache.Pipeline()
+	for _, uid := range users {
+		pipe.ZRem(ctx, presenceKey(uid), presenceMember(instance, PresenceOnline), presenceMember(instance, PresenceAway))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.logError("Failed to remove presence", err)
+		return err
+	}
+	return nil
+}
+
+// Presence -> Returns the state of each user, online when active on a live replica,
+// away when only idle sockets are left and offline otherwise
+func (c *RDB) Presence(users []string) (map[string]string, error) {
+	presence := make(map[string]string, len(users))
+	if len(users) == 0 {
+		return presence, nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
+	defer cancel()
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	pipe := c.Cache.Pipeline()
+	members := make([]*redis.StringSliceCmd, len(users))
+	for i, uid := range users {
+		members[i] = pipe.ZRangeByScore(ctx, presenceKey(uid), &redis.ZRangeBy{Min: now, Max: "+inf"})
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		c.logError("Failed to fetch presence", err)
+		return nil, err
+	}
+
+	for i, uid := range users {
+		presence[uid] = PresenceOffline
+		for _, member := range members[i].Val() {
+			if strings.HasSuffix(member, "|"+PresenceOnline) {
+				presence[uid] = PresenceOnline
+				break
+			}
+			presence[uid] = PresenceAway
+		}
+	}
+	return presence, nil
+}
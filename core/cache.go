@@ -5,7 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -16,6 +16,7 @@ import (
 )
 
 type Cache interface {
+	WithContext(ctx context.Context) Cache
 	CheckHealth() error
 	CacheSkills(skills []model.Skill)
 	CachePlans(plans []schema.ViewPlansResp) error
@@ -28,14 +29,25 @@ type Cache interface {
 
 type RDB struct {
 	Cache *redis.Client
+	ctx   context.Context
 }
 
 func NewRDB(rdb *redis.Client) *RDB {
-	return &RDB{Cache: rdb}
+	return &RDB{Cache: rdb, ctx: context.Background()}
+}
+
+// WithContext -> Returns a copy of the cache bound to the context, so commands are traced under the request span
+func (c *RDB) WithContext(ctx context.Context) Cache {
+	return &RDB{Cache: c.Cache, ctx: requestContext(ctx)}
+}
+
+// logError -> Logs a failed command along with the request fields carried by the cache context
+func (c *RDB) logError(msg string, err error) {
+	slog.ErrorContext(c.ctx, msg, "component", "cache", "err", err)
 }
 
 func (c *RDB) CheckHealth() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
 	defer cancel()
 
 	_, err := c.Cache.Ping(ctx).Result()
@@ -49,21 +61,21 @@ func (c *RDB) CacheSkills(skills []model.Skill) {
 	for _, skill := range skills {
 		skillName[skill.Name] = skill.ID
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.ctx, 10*time.Second)
 	defer cancel()
 	if _, err := c.Cache.HSet(ctx, "skills", skillName).Result(); err != nil {
-		log.Printf("[ERROR] [RDB] An error occured while trying to set skills in redis -> %v", err)
+		c.logError("Failed to set skills in redis", err)
 	}
 }
 
 // RetrieveCachedSkills -> Retrieve cached skills from rdb if possible
 func (c *RDB) RetrieveCachedSkills(skills []string) (map[string]string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
 	defer cancel()
 
 	skill, err := c.Cache.HMGet(ctx, "skills", skills...).Result()
 	if err != nil {
-		log.Printf("[ERROR] [RDB] Failed to receive skills from redis -> %s", err)
+		c.logError("Failed to receive skills from redis", err)
 		return nil, err
 	}
 
@@ -86,17 +98,17 @@ func (c *RDB) AddNewSkillToCache(newskills []*model.Skill) {
 	for _, skill := range newskills {
 		skills[skill.Name] = skill.ID
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
 	defer cancel()
 
 	if _, err := c.Cache.HSet(ctx, "skills", skills).Result(); err != nil {
-		log.Printf("An error occured while trying to set new skill in redis -> %v", err)
+		c.logError("Failed to set new skill in redis", err)
 	}
 }
 
 // SetOTP -> Set OTP for password reset temporary in rdb
 func (c *RDB) SetOTP(otp string, userID string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
 	defer cancel()
 
 	if _, err := c.Cache.HGet(ctx, "otps", otp).Result(); err != redis.Nil {
@@ -109,7 +121,7 @@ func (c *RDB) SetOTP(otp string, userID string) error {
 	}
 
 	if _, err := c.Cache.HSetEXWithArgs(ctx, "otps", otps, otp, userID).Result(); err != nil {
-		log.Printf("An error occured while trying to set otp in redis -> %v", err)
+		c.logError("Failed to set otp in redis", err)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to set otp."}
 	}
 	return nil
@@ -117,7 +129,7 @@ func (c *RDB) SetOTP(otp string, userID string) error {
 
 // GetOTP -> Verify if OTP provided exists in rdb and returns the userID
 func (c *RDB) GetOTP(otp string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
 	defer cancel()
 
 	userID, err := c.Cache.HGetDel(ctx, "otps", otp).Result()
@@ -136,17 +148,17 @@ func (c *RDB) GetOTP(otp string) (string, error) {
 
 // CachePlans -> Caches the plans in rdb
 func (c *RDB) CachePlans(plans []schema.ViewPlansResp) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.ctx, 10*time.Second)
 	defer cancel()
 
 	data, err := json.Marshal(plans)
 	if err != nil {
-		log.Printf("Failed to marshal plans -> %v", err.Error())
+		c.logError("Failed to marshal plans", err)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to cache plans."}
 	}
 
 	if _, err := c.Cache.SetEx(ctx, "plans", data, 24*time.Hour).Result(); err != nil {
-		log.Printf("An error occured while trying to set plans in redis -> %v", err.Error())
+		c.logError("Failed to set plans in redis", err)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to set plans in cache."}
 	}
 
@@ -155,7 +167,7 @@ func (c *RDB) CachePlans(plans []schema.ViewPlansResp) error {
 
 // RetrieveCachedPlans -> Retreives the cached plans from rdb
 func (c *RDB) RetrieveCachedPlans() ([]schema.ViewPlansResp, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.ctx, 10*time.Second)
 	defer cancel()
 
 	data, err := c.Cache.Get(ctx, "plans").Result()
@@ -168,7 +180,7 @@ func (c *RDB) RetrieveCachedPlans() ([]schema.ViewPlansResp, error) {
 
 	var plans []schema.ViewPlansResp
 	if err := json.Unmarshal([]byte(data), &plans); err != nil {
-		log.Printf("Failed to Unmarshal cached plans -> %v", err.Error())
+		c.logError("Failed to unmarshal cached plans", err)
 		return nil, &CustomMessage{http.StatusInternalServerError, "Failed to parse cached plans."}
 	}
 
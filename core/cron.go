@@ -1,7 +1,12 @@
 package core
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"maps"
+	"net/url"
+	"slices"
+	"sync"
 	"time"
 
 	"findme/model"
@@ -9,23 +14,205 @@ import (
 	"github.com/robfig/cron/v3"
 )
 
+const (
+	// DigestDelay -> Time a message stays unread before it goes out in a digest, so quick replies don't cause one
+	DigestDelay = 10 * time.Minute
+
+	// digestMaxAge -> Oldest unread message reported by a digest
+	digestMaxAge = 7 * 24 * time.Hour
+
+	// digestChatMessages -> Latest messages of each chat quoted in a digest, the others are only counted
+	digestChatMessages = 3
+)
+
 type CronWorker interface {
 	TrialEndingReminders() error
+	MessageDigests() error
+	LastRuns() map[string]time.Time
 }
 
 type Cron struct {
 	DB    DB
 	Email Email
 	Cron  *cron.Cron
+
+	// AppURL -> Public address of the api, prefixed to the unsubscribe links
+	AppURL string
+	// Notify -> Writes the trial reminders to the notification center too (skipped when nil)
+	Notify Notifier
+	// Presence -> Tells the connected users apart, who get no digest (every user is taken as offline when nil)
+	Presence func(users []string) (map[string]string, error)
+
+	mu       sync.Mutex
+	lastRuns map[string]time.Time
 }
 
 func NewCron(db DB, email Email, cron *cron.Cron) *Cron {
-	return &Cron{db, email, cron}
+	return &Cron{DB: db, Email: email, Cron: cron, lastRuns: make(map[string]time.Time)}
+}
+
+// LastRuns -> Returns the last time each job ran
+func (c *Cron) LastRuns() map[string]time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	runs := make(map[string]time.Time, len(c.lastRuns))
+	maps.Copy(runs, c.lastRuns)
+	return runs
+}
+
+// track -> Wraps a job recording its last run and duration
+func (c *Cron) track(job string, fn func()) func() {
+	return func() {
+		start := time.Now()
+		c.mu.Lock()
+		c.lastRuns[job] = start
+		c.mu.Unlock()
+
+		fn()
+		CronJobDuration.WithLabelValues(job).Observe(time.Since(start).Seconds())
+	}
+}
+
+// MessageDigests -> Schedules the email digests of the messages received while offline
+func (c *Cron) MessageDigests() error {
+	_, err := c.Cron.AddFunc("*/5 * * * *", c.track("message_digests", func() {
+		sent, err := c.SendDigests(time.Now())
+		if err != nil {
+			return
+		}
+		if sent > 0 {
+			slog.Info("Sent message digests", "component", "cron", "job", "message_digests", "users", sent)
+		}
+	}))
+
+	return err
+}
+
+// SendDigests -> Emails every offline user whose digest is due their unread messages grouped by chat, leaving out
+// the chats they muted (or only want mentions from). Returns the number of digests sent
+func (c *Cron) SendDigests(now time.Time) (int, error) {
+	until := now.Add(-DigestDelay)
+
+	var pending []model.PendingMessage
+	if err := c.DB.FetchPendingMessages(now.Add(-digestMaxAge), until, &pending); err != nil {
+		return 0, err
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	// The messages come sorted by user
+	var users []string
+	byUser := make(map[string][]model.PendingMessage)
+	for _, msg := range pending {
+		if byUser[msg.UserID] == nil {
+			users = append(users, msg.UserID)
+		}
+		byUser[msg.UserID] = append(byUser[msg.UserID], msg)
+	}
+
+	presence := make(map[string]string)
+	if c.Presence != nil {
+		var err error
+		if presence, err = c.Presence(users); err != nil {
+			slog.Warn("Failed to fetch presence, skipping the digests", "component", "cron", "err", err)
+			return 0, err
+		}
+	}
+
+	sent := 0
+	for _, uid := range users {
+		msgs := byUser[uid]
+		user := msgs[0]
+		if !digestDue(user.DigestFrequency, user.DigestSentAt, now) {
+			continue
+		}
+		if status, ok := presence[uid]; ok && status != PresenceOffline {
+			continue
+		}
+
+		chats := digestChats(msgs, now)
+		if len(chats) == 0 {
+			continue
+		}
+
+		token, err := UnsubscribeToken(c.DB, uid, user.UnsubscribeToken)
+		if err != nil {
+			continue
+		}
+
+		c.Email.QueueMessageDigest(context.Background(), user.Username, chats, c.AppURL+"/unsubscribe?token="+url.QueryEscape(token), user.Email)
+		// Messages newer than until are still too recent, the next digest picks them up
+		if err := c.DB.UpdateDigestSent(uid, until); err != nil {
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// digestDue -> Whether a user with the given frequency and last digest gets one now. sentAt is the time of the last
+// message the digest held, the digest itself went out DigestDelay after it
+func digestDue(frequency string, sentAt *time.Time, now time.Time) bool {
+	var every time.Duration
+	switch frequency {
+	case model.DigestInstant:
+		return true
+	case model.DigestHourly:
+		every = time.Hour
+	case model.DigestDaily:
+		every = 24 * time.Hour
+	default:
+		return false
+	}
+	return sentAt == nil || now.Sub(*sentAt) >= every+DigestDelay
+}
+
+// digestChats -> Groups the pending messages of a user by chat, the most recently active chat first
+func digestChats(msgs []model.PendingMessage, now time.Time) []DigestChat {
+	var order []string
+	chats := make(map[string]*DigestChat)
+	for _, msg := range msgs {
+		member := model.ChatUser{MutedUntil: msg.MutedUntil, NotifyLevel: msg.NotifyLevel}
+		if !member.ShouldNotify(msg.Mentioned, now) {
+			continue
+		}
+		// A mention goes in the digest when either category has it on
+		if !msg.MessagesDigest && !(msg.Mentioned && msg.MentionsDigest) {
+			continue
+		}
+
+		chat := chats[msg.ChatID]
+		if chat == nil {
+			name := msg.ChatName
+			if !msg.IsGroup {
+				name = msg.FromName
+			}
+			chat = &DigestChat{Name: name}
+			chats[msg.ChatID] = chat
+		} else {
+			order = slices.DeleteFunc(order, func(id string) bool { return id == msg.ChatID })
+		}
+		order = append(order, msg.ChatID)
+
+		chat.Unread++
+		chat.Messages = append(chat.Messages, DigestMessage{From: msg.FromName, Message: msg.Message, Sent: msg.CreatedAt})
+		if len(chat.Messages) > digestChatMessages {
+			chat.Messages = chat.Messages[1:]
+		}
+	}
+
+	digest := make([]DigestChat, len(order))
+	for i, chatID := range order {
+		digest[len(order)-1-i] = *chats[chatID]
+	}
+	return digest
 }
 
 func (c *Cron) TrialEndingReminders() error {
-	_, err := c.Cron.AddFunc("0 9 * * *", func() {
-		log.Println("[CRON] Running trial ending reminders...")
+	_, err := c.Cron.AddFunc("0 9 * * *", c.track("trial_ending_reminders", func() {
+		slog.Info("Running trial ending reminders...", "component", "cron", "job", "trial_ending_reminders")
 		twoDays := time.Now().Add(time.Hour * 24 * 2)
 		today := time.Now()
 
@@ -42,15 +229,18 @@ func (c *Cron) TrialEndingReminders() error {
 
 		for i, user := range users {
 			ids[i] = user.ID
-			c.Email.QueueNotifyFreeTrialEnding(user.UserName, user.FreeTrial.Format("January 2, 2006"), "", user.Email)
+			c.Email.QueueNotifyFreeTrialEnding(context.Background(), user.UserName, user.FreeTrial.Format("January 2, 2006"), "", user.Email)
+			if c.Notify != nil {
+				c.Notify.Notify(context.Background(), user.ID, model.NotifyTrialEnding, "Free trial ending", "Your free trial ends on "+user.FreeTrial.Format("January 2, 2006")+", subscribe to keep the premium features.", nil)
+			}
 		}
 
-		log.Printf("[CRON] Sent Trial ending reminders to %v users", len(users))
+		slog.Info("Sent trial ending reminders", "component", "cron", "job", "trial_ending_reminders", "users", len(users))
 
 		if err := c.DB.UpdateSentReminder(ids); err != nil {
 			return
 		}
-	})
+	}))
 
 	return err
 }
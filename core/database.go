@@ -1,17 +1,25 @@
 package core
 
 import (
+	"context"
+	"database/sql"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"findme/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DB interface {
+	WithContext(ctx context.Context) DB
 	CheckHealth() error
 	FetchAllSkills(skills *[]model.Skill) error
 	AddUser(user *model.User) error
@@ -25,6 +33,7 @@ type DB interface {
 	CheckExistingAppReq(pid, uid string) (error, bool)
 	VerifyUser(user *model.User, username string) error
 	SaveUser(user *model.User) error
+	UpdateLastSeen(uid string, seen time.Time) error
 	FetchUser(user *model.User, uid string) error
 	FetchUserPreloadSP(user *model.User, uid string) error
 	FetchUserPreloadS(user *model.User, uid string) error
@@ -76,19 +85,72 @@ type DB interface {
 	FindExistingSkills(skills *[]*model.Skill, skill []string) error
 	FindExistingGitID(user *model.User, gitid int64) error
 	AddMessage(msg *model.UserMessage) error
-	GetChatHistory(chatID string, chat *model.Chat) error
+	FetchChatPreloadU(chatID string, chat *model.Chat) error
+	FetchChatMessages(chatID, uid, before, after string, limit int, msgs *[]model.UserMessage) error
+	SearchMessages(uid, chatID, query string, limit int, hits *[]model.MessageHit) error
+	FetchReplies(msgID string, msgs *[]model.UserMessage) error
+	FetchReplyCounts(msgIDs []string, counts *[]model.ReplyCount) error
+	AddReaction(reaction *model.MessageReaction) error
+	RemoveReaction(msgID, uid, emoji string) error
+	FetchReactionCounts(msgIDs []string, uid string, counts *[]model.ReactionCount) error
+	AddMessageAttachments(msg *model.UserMessage, ids []string) error
+	AddAttachment(attachment *model.Attachment) error
+	FetchAttachment(id string, attachment *model.Attachment) error
+	FetchAttachments(msgIDs []string, attachments *[]model.Attachment) error
 	FetchChat(chatID string, chat *model.Chat) error
-	FetchUserPreloadCM(user *model.User, uid string) error
+	CheckChatMember(chatID, uid string) error
+	FetchUserChatIDs(uid string, ids *[]string) error
+	FetchPendingMessages(since, until time.Time, msgs *[]model.PendingMessage) error
+	UpdateDigestSent(uid string, sentAt time.Time) error
+	SetUnsubscribeToken(uid, token string) error
+	UnsubscribeDigest(token string) error
+	CheckUnsubscribeToken(token string) error
+	AddNotification(notification *model.Notification) error
+	FetchNotificationPreferences(uid string, prefs *[]model.NotificationPreference) error
+	FetchNotificationPreference(uid, category string, pref *model.NotificationPreference) error
+	SaveNotificationPreference(pref *model.NotificationPreference) error
+	UnsubscribeEmail(token, category string) error
+	FetchNotifications(uid, before string, limit int, notifications *[]model.Notification) error
+	CountUnreadNotifications(uid string, count *int64) error
+	MarkNotificationRead(id, uid string, readAt time.Time) error
+	MarkAllNotificationsRead(uid string, readAt time.Time) error
+	DeleteNotification(id, uid string) error
+	FetchChatSummaries(uid string, archived bool, chats *[]model.ChatSummary) error
+	UpdateChatMember(chatID, uid string, fields map[string]any) error
+	CountPinnedChats(uid string, count *int64) error
+	FetchLastMsg(chatID string, msg *model.UserMessage) error
+	MarkChatRead(chatID, uid, msgID string, sent time.Time) error
+	FetchReadCursors(chatID string, cursors *[]model.ChatUser) error
 	FetchUserPreloadC(user *model.User, uid string) error
 	FetchMsg(msg *model.UserMessage, mid string) error
-	SaveMsg(msg *model.UserMessage) error
+	EditMsg(msg *model.UserMessage, text string) error
+	FetchMsgRevisions(msgID string, revisions *[]model.MessageRevision) error
+	FetchRevisions(msgIDs []string, revisions *[]model.MessageRevision) error
+	FetchChatExport(chatID, uid string, after *model.UserMessage, limit int, msgs *[]model.UserMessage) error
 	SaveChat(chat *model.Chat) error
-	DeleteMsg(msg *model.UserMessage) error
+	RemoveMsg(msg *model.UserMessage, by string) error
+	HideMsg(msgID, uid string) error
+	FetchMentionedMembers(chatID string, usernames []string, members *[]model.ChatUser) error
+	AddMentions(mentions []model.MessageMention) error
+	FetchMentions(msgIDs []string, mentions *[]model.MessageMention) error
 	FindChat(uid, fid string, chat *model.Chat) error
 	AddUserChat(chat *model.Chat, user *model.User) error
 	RemoveUserChat(chat *model.Chat, user *model.User) error
 	LeaveChat(chat *model.Chat, user *model.User) error
 	DeleteChat(chat *model.Chat) error
+	FetchChatMember(chatID, uid string, member *model.ChatUser) error
+	FetchChatMembers(chatID string, members *[]model.ChatUser) error
+	SetMemberRole(chatID, uid, role string) error
+	TransferChatOwner(chat *model.Chat, uid string) error
+	AddChatInvite(invite *model.ChatInvite) error
+	FetchChatInvite(id string, invite *model.ChatInvite) error
+	FetchChatInviteByToken(token string, invite *model.ChatInvite) error
+	FetchChatInvites(chatID string, invites *[]model.ChatInvite) error
+	RevokeChatInvite(invite *model.ChatInvite) error
+	JoinChatInvite(invite *model.ChatInvite, uid string, approval bool) error
+	FetchJoinRequests(chatID string, reqs *[]model.ChatJoinRequest) error
+	FetchJoinRequest(chatID, uid string, req *model.ChatJoinRequest) error
+	ReviewJoinRequest(req *model.ChatJoinRequest, approve bool) error
 	AddTransaction(transc *model.Transactions) error
 	FetchTransaction(paystackRef string, transc *model.Transactions) error
 	AddSubscription(sub *model.Subscriptions) error
@@ -98,6 +160,20 @@ type DB interface {
 	FetchTrialEndingUsers(users *[]model.User, limit, today time.Time) error
 	UpdateSentReminder(ids []string) error
 	FetchSub(sub *model.Subscriptions, sid string) error
+	AddWebhook(hook *model.Webhook) error
+	CountWebhooks(uid string, count *int64) error
+	FetchWebhook(id string, hook *model.Webhook) error
+	FetchUserWebhooks(uid string, hooks *[]model.Webhook) error
+	FetchEventWebhooks(users []string, projectID string, hooks *[]model.Webhook) error
+	UpdateWebhook(hook *model.Webhook, fields map[string]any) error
+	DeleteWebhook(hook *model.Webhook) error
+	RecordWebhookFailure(id string, now time.Time, hook *model.Webhook) error
+	ResetWebhookFailures(id string) error
+	AddWebhookDeliveries(deliveries []model.WebhookDelivery) error
+	FetchWebhookDelivery(id string, delivery *model.WebhookDelivery) error
+	FetchWebhookDeliveries(webhookID string, limit int, deliveries *[]model.WebhookDelivery) error
+	SaveWebhookDelivery(delivery *model.WebhookDelivery) error
+	ClaimWebhookDeliveries(now, leaseUntil time.Time, limit int, ids *[]string) error
 }
 
 type GormDB struct {
@@ -108,6 +184,16 @@ func NewGormDB(db *gorm.DB) *GormDB {
 	return &GormDB{DB: db}
 }
 
+// logError -> Logs a failed query along with the request fields carried by the db context
+func (db *GormDB) logError(msg string, err error, args ...any) {
+	slog.ErrorContext(db.DB.Statement.Context, msg, append([]any{"component", "db", "err", err}, args...)...)
+}
+
+// WithContext -> Returns a copy of the db bound to the context, so queries are traced under the request span
+func (db *GormDB) WithContext(ctx context.Context) DB {
+	return &GormDB{DB: db.DB.WithContext(requestContext(ctx))}
+}
+
 func (db *GormDB) CheckHealth() error {
 	var ping string
 	err := db.DB.Raw("SELECT 1").Scan(&ping).Error
@@ -125,7 +211,7 @@ func (db *GormDB) FetchAllSkills(skills *[]model.Skill) error {
 // AddUser -> Adds a user to the db
 func (db *GormDB) AddUser(user *model.User) error {
 	if err := db.DB.Create(user).Error; err != nil {
-		log.Println("Failed to create user err -> ", err.Error())
+		db.logError("Failed to create user", err)
 		return &CustomMessage{Code: http.StatusInternalServerError, Message: "Failed to create new user."}
 	}
 	return nil
@@ -134,7 +220,7 @@ func (db *GormDB) AddUser(user *model.User) error {
 // FindUsers -> Retrieves a list of users from the db with their skills preloaded
 func (db *GormDB) FindUsers(users *[]model.User, ids []string) error {
 	if err := db.DB.Preload("Skills").Where("id IN ?", ids).Find(users).Error; err != nil {
-		log.Println(err)
+		db.logError("Failed to retrieve users", err)
 		return &CustomMessage{Code: http.StatusInternalServerError, Message: "Failed to retrieve users."}
 	}
 	return nil
@@ -220,10 +306,19 @@ func (db *GormDB) VerifyUser(user *model.User, username string) error {
 	return nil
 }
 
+// UpdateLastSeen -> Records when the user last had a socket open, without touching updated_at
+func (db *GormDB) UpdateLastSeen(uid string, seen time.Time) error {
+	if err := db.DB.Model(&model.User{}).Where("id = ?", uid).UpdateColumn("last_seen", seen).Error; err != nil {
+		db.logError("Failed to update last seen", err, "target_user_id", uid)
+		return &CustomMessage{Code: http.StatusInternalServerError, Message: "Failed to update last seen."}
+	}
+	return nil
+}
+
 // SaveUser -> Saves a user to the db after changes to the record
 func (db *GormDB) SaveUser(user *model.User) error {
 	if err := db.DB.Save(user).Error; err != nil {
-		log.Println("Failed to save user with id -> ", user.ID, "err -> ", err.Error())
+		db.logError("Failed to save user", err, "target_user_id", user.ID)
 		return &CustomMessage{Code: http.StatusInternalServerError, Message: "Failed to save user."}
 	}
 	return nil
@@ -380,7 +475,7 @@ func (db *GormDB) SearchUsersBySKills(users *[]model.User, skills []string, uid
 // AddFriendReq -> Adds a friend req to the db
 func (db *GormDB) AddFriendReq(req *model.FriendReq) error {
 	if err := db.DB.Create(req).Error; err != nil {
-		log.Println("Failed to create friend req err -> ", err.Error())
+		db.logError("Failed to create friend req", err)
 		return &CustomMessage{Code: http.StatusInternalServerError, Message: "Failed to create request."}
 	}
 	return nil
@@ -450,7 +545,7 @@ func (db *GormDB) UpdateFriendReqAccept(req *model.FriendReq, user, friend *mode
 // DeleteFriendReq -> Deletes a friend req from the db
 func (db *GormDB) DeleteFriendReq(req *model.FriendReq) error {
 	if err := db.DB.Unscoped().Delete(req).Error; err != nil {
-		log.Println("Failed to delete friend req with id -> ", req.ID, "err -> ", err.Error())
+		db.logError("Failed to delete friend req", err, "req_id", req.ID)
 		return &CustomMessage{Code: http.StatusInternalServerError, Message: "Failed to delete friend request."}
 	}
 	return nil
@@ -470,7 +565,7 @@ func (db *GormDB) DeleteFriend(user, friend *model.User, chat *model.Chat) error
 		}
 		return nil
 	}); err != nil {
-		log.Println("Failed to delete user friend with ids -> ", user.ID, friend.ID, "err -> ", err.Error())
+		db.logError("Failed to delete user friend", err, "target_user_id", user.ID, "friend_id", friend.ID)
 		return &CustomMessage{Code: http.StatusInternalServerError, Message: "Failed to delete friend."}
 	}
 	return nil
@@ -495,7 +590,7 @@ func (db *GormDB) DeleteSkills(user *model.User, skills []*model.Skill) error {
 // DeleteUser -> Deletes a user from the db
 func (db *GormDB) DeleteUser(user *model.User) error {
 	if err := db.DB.Delete(user).Error; err != nil {
-		log.Println("Failed to delete user with id -> ", user.ID, "err -> ", err.Error())
+		db.logError("Failed to delete user", err, "target_user_id", user.ID)
 		return &CustomMessage{Code: http.StatusInternalServerError, Message: "Failed to delete user."}
 	}
 	return nil
@@ -504,7 +599,7 @@ func (db *GormDB) DeleteUser(user *model.User) error {
 // AddProject -> Adds a project to the db
 func (db *GormDB) AddProject(project *model.Project) error {
 	if err := db.DB.Create(project).Error; err != nil {
-		log.Println("Failed to create project err -> ", err.Error())
+		db.logError("Failed to create project", err)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to create project."}
 	}
 	return nil
@@ -609,7 +704,7 @@ func (db *GormDB) EditProject(project *model.Project, skills []*model.Skill) err
 // SaveProject -> Saves a project in the db
 func (db *GormDB) SaveProject(project *model.Project) error {
 	if err := db.DB.Save(project).Error; err != nil {
-		log.Println("Failed to save project with id -> ", project.ID, "err -> ", err.Error())
+		db.logError("Failed to save project", err, "project_id", project.ID)
 		return &CustomMessage{Code: http.StatusInternalServerError, Message: "Failed to save project."}
 	}
 	return nil
@@ -671,7 +766,7 @@ func (db *GormDB) RemoveBookmarkedProject(user *model.User, project *model.Proje
 // AddProjectApplicationReq -> Add a project application to the db
 func (db *GormDB) AddProjectApplicationReq(req *model.ProjectReq) error {
 	if err := db.DB.Save(req).Error; err != nil {
-		log.Println("Failed to save project application req with id -> ", req.ID, "err -> ", err.Error())
+		db.logError("Failed to save project application req", err, "req_id", req.ID)
 		return &CustomMessage{Code: http.StatusInternalServerError, Message: "Failed to send project application request."}
 	}
 	return nil
@@ -751,9 +846,10 @@ func (db *GormDB) UpdateProjectApplicationAcceptF(req *model.ProjectReq, user1,
 		if err := tx.Model(user2).Association("Chats").Append(chat); err != nil {
 			return err
 		}
-		return nil
+
+		return tx.Model(&model.ChatUser{}).Where("chat_id = ? AND user_id = ?", chat.ID, user1.ID).Update("role", model.RoleOwner).Error
 	}); err != nil {
-		log.Println(err)
+		db.logError("Failed to accept project application", err, "req_id", req.ID, "project_id", project.ID)
 		return &CustomMessage{Code: http.StatusInternalServerError, Message: "Failed to update project application status."}
 	}
 	return nil
@@ -774,7 +870,7 @@ func (db *GormDB) FetchProjectAppPreloadFU(req *model.ProjectReq, rid string) er
 // DeleteProjectApplicationReq -> Deletes a project application from the db
 func (db *GormDB) DeleteProjectApplicationReq(req *model.ProjectReq) error {
 	if err := db.DB.Unscoped().Delete(req).Error; err != nil {
-		log.Println("Failed to delete project application req with id -> ", req.ID, "err -> ", err.Error())
+		db.logError("Failed to delete project application req", err, "req_id", req.ID)
 		return &CustomMessage{Code: http.StatusInternalServerError, Message: "Failed to delete project application request."}
 	}
 	return nil
@@ -783,7 +879,7 @@ func (db *GormDB) DeleteProjectApplicationReq(req *model.ProjectReq) error {
 // ClearProjectApplication -> Clears all applications on a project from the db
 func (db *GormDB) ClearProjectApplication(req []*model.ProjectReq) error {
 	if err := db.DB.Unscoped().Delete(req).Error; err != nil {
-		log.Println("Failed to clear project applications for project with id -> ", req[0].ProjectID, "err -> ", err.Error())
+		db.logError("Failed to clear project applications", err, "project_id", req[0].ProjectID)
 		return &CustomMessage{Code: http.StatusInternalServerError, Message: "Failed to clear project applications."}
 	}
 	return nil
@@ -792,7 +888,7 @@ func (db *GormDB) ClearProjectApplication(req []*model.ProjectReq) error {
 // DeleteProject -> Deletes a project from the db
 func (db *GormDB) DeleteProject(project *model.Project) error {
 	if err := db.DB.Delete(project).Error; err != nil {
-		log.Println("Failed to delete project with id -> ", project.ID, "err -> ", err.Error())
+		db.logError("Failed to delete project", err, "project_id", project.ID)
 
 		return &CustomMessage{Code: http.StatusInternalServerError, Message: "Failed to delete project."}
 	}
@@ -854,25 +950,263 @@ func (db *GormDB) CheckExistingUsername(username string) error {
 // AddMessage -> Adds a message to the db
 func (db *GormDB) AddMessage(msg *model.UserMessage) error {
 	if err := db.DB.Create(msg).Error; err != nil {
-		log.Println("Failed to create msg err -> ", err.Error())
+		db.logError("Failed to create msg", err, "chat_id", msg.ChatID)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to send message."}
 	}
 	return nil
 }
 
-// GetChatHistory -> Retrieves the chat history for a chat from the db
-func (db *GormDB) GetChatHistory(chatID string, chat *model.Chat) error {
-	if err := db.DB.Preload("Messages").Preload("Users").Where("id = ?", chatID).First(chat).Error; err != nil {
-		log.Println(chatID, err)
+// FetchChatPreloadU -> Retrieves a chat with its members from the db
+func (db *GormDB) FetchChatPreloadU(chatID string, chat *model.Chat) error {
+	if err := db.DB.Preload("Users").Where("id = ?", chatID).First(chat).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return &CustomMessage{http.StatusNotFound, "Chat not found."}
 		} else {
+			db.logError("Failed to retrieve chat", err, "chat_id", chatID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to get chat."}
+		}
+	}
+	return nil
+}
+
+// FetchChatMessages -> Retrieves a page of the chat history as seen by a member (without the messages they deleted for
+// themselves), oldest first, with the sender of each message. The page holds the latest messages, the ones right before the message with id before, or the ones right after the message with id after
+func (db *GormDB) FetchChatMessages(chatID, uid, before, after string, limit int, msgs *[]model.UserMessage) error {
+	query := db.DB.Preload("FromUser", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id", "username")
+	}).Where("chat_id = ?", chatID).
+		Where("NOT EXISTS (SELECT 1 FROM hidden_messages h WHERE h.msg_id = user_messages.id AND h.user_id = ?)", uid)
+
+	cursorID, op, order := before, "<", "created_at DESC, id DESC"
+	if after != "" {
+		cursorID, op, order = after, ">", "created_at ASC, id ASC"
+	}
+
+	if cursorID != "" {
+		var cursor model.UserMessage
+		if err := db.DB.Select("id", "created_at").Where("id = ? AND chat_id = ?", cursorID, chatID).First(&cursor).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &CustomMessage{http.StatusNotFound, "Msg not found."}
+			}
+			db.logError("Failed to fetch the history cursor", err, "chat_id", chatID)
 			return &CustomMessage{http.StatusInternalServerError, "Failed to get chat history."}
 		}
+		query = query.Where(fmt.Sprintf("(created_at %[1]s ? OR (created_at = ? AND id %[1]s ?))", op), cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+	}
+
+	if err := query.Order(order).Limit(limit).Find(msgs).Error; err != nil {
+		db.logError("Failed to retrieve chat history", err, "chat_id", chatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to get chat history."}
+	}
+
+	if op == "<" {
+		slices.Reverse(*msgs)
+	}
+	return nil
+}
+
+// FetchReplies -> Retrieves the thread of a message, oldest reply first
+func (db *GormDB) FetchReplies(msgID string, msgs *[]model.UserMessage) error {
+	if err := db.DB.Preload("FromUser", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id", "username")
+	}).Where("reply_to_id = ?", msgID).Order("created_at ASC, id ASC").Find(msgs).Error; err != nil {
+		db.logError("Failed to retrieve replies", err, "msg_id", msgID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to get the thread."}
+	}
+	return nil
+}
+
+// FetchReplyCounts -> Counts the replies to each of the messages
+func (db *GormDB) FetchReplyCounts(msgIDs []string, counts *[]model.ReplyCount) error {
+	if len(msgIDs) == 0 {
+		return nil
+	}
+	if err := db.DB.Model(&model.UserMessage{}).Select("reply_to_id AS msg_id, COUNT(*) AS count").
+		Where("reply_to_id IN ?", msgIDs).Group("reply_to_id").Scan(counts).Error; err != nil {
+		db.logError("Failed to count replies", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to get chat history."}
+	}
+	return nil
+}
+
+// AddReaction -> Adds a reaction to a message, a user can't react twice with the same emoji
+func (db *GormDB) AddReaction(reaction *model.MessageReaction) error {
+	res := db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction)
+	if res.Error != nil {
+		db.logError("Failed to add reaction", res.Error, "msg_id", reaction.MsgID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to add reaction."}
+	}
+	if res.RowsAffected == 0 {
+		return &CustomMessage{http.StatusConflict, "Reaction already added."}
+	}
+	return nil
+}
+
+// RemoveReaction -> Removes the reaction of a user with an emoji from a message
+func (db *GormDB) RemoveReaction(msgID, uid, emoji string) error {
+	res := db.DB.Where("msg_id = ? AND user_id = ? AND emoji = ?", msgID, uid, emoji).Delete(&model.MessageReaction{})
+	if res.Error != nil {
+		db.logError("Failed to remove reaction", res.Error, "msg_id", msgID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to remove reaction."}
+	}
+	if res.RowsAffected == 0 {
+		return &CustomMessage{http.StatusNotFound, "Reaction not found."}
+	}
+	return nil
+}
+
+// FetchReactionCounts -> Counts the reactions to each of the messages by emoji, in the order the emojis were first used
+func (db *GormDB) FetchReactionCounts(msgIDs []string, uid string, counts *[]model.ReactionCount) error {
+	if len(msgIDs) == 0 {
+		return nil
+	}
+	if err := db.DB.Model(&model.MessageReaction{}).
+		Select("msg_id, emoji, COUNT(*) AS count, MAX(CASE WHEN user_id = ? THEN 1 ELSE 0 END) AS mine", uid).
+		Where("msg_id IN ?", msgIDs).Group("msg_id, emoji").Order("MIN(created_at)").Scan(counts).Error; err != nil {
+		db.logError("Failed to count reactions", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to get chat history."}
+	}
+	return nil
+}
+
+// AddMessageAttachments -> Adds a message along with the attachments sent with it, which must have been
+// uploaded to the same chat by the sender and not sent yet
+func (db *GormDB) AddMessageAttachments(msg *model.UserMessage, ids []string) error {
+	ids = slices.Compact(slices.Sorted(slices.Values(ids)))
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(msg).Error; err != nil {
+			db.logError("Failed to create msg", err, "chat_id", msg.ChatID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to send message."}
+		}
+
+		res := tx.Model(&model.Attachment{}).
+			Where("id IN ? AND chat_id = ? AND uploader_id = ? AND msg_id IS NULL", ids, msg.ChatID, msg.FromID).
+			Update("msg_id", msg.ID)
+		if res.Error != nil {
+			db.logError("Failed to attach files", res.Error, "chat_id", msg.ChatID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to send message."}
+		}
+		if res.RowsAffected != int64(len(ids)) {
+			return &CustomMessage{http.StatusNotFound, "Attachment not found."}
+		}
+		return nil
+	})
+}
+
+// AddAttachment -> Adds an uploaded file to the db
+func (db *GormDB) AddAttachment(attachment *model.Attachment) error {
+	if err := db.DB.Create(attachment).Error; err != nil {
+		db.logError("Failed to create attachment", err, "chat_id", attachment.ChatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to upload the file."}
 	}
 	return nil
 }
 
+// FetchAttachment -> Retrieves an attachment from the db
+func (db *GormDB) FetchAttachment(id string, attachment *model.Attachment) error {
+	if err := db.DB.Where("id = ?", id).First(attachment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &CustomMessage{http.StatusNotFound, "Attachment not found."}
+		}
+		db.logError("Failed to fetch attachment", err, "attachment_id", id)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch attachment."}
+	}
+	return nil
+}
+
+// FetchAttachments -> Retrieves the attachments of the messages
+func (db *GormDB) FetchAttachments(msgIDs []string, attachments *[]model.Attachment) error {
+	if len(msgIDs) == 0 {
+		return nil
+	}
+	if err := db.DB.Where("msg_id IN ?", msgIDs).Order("created_at ASC").Find(attachments).Error; err != nil {
+		db.logError("Failed to fetch attachments", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to get chat history."}
+	}
+	return nil
+}
+
+// messageSearchQuery -> Ranked full-text search over the chats of a user, the text is html escaped before
+// ts_headline marks the matched terms so the highlight can be rendered as is
+var messageSearchQuery = fmt.Sprintf(`
+SELECT m.id, m.chat_id, m.from_id, u.username AS from_name, m.message, m.created_at, m.updated_at,
+	ts_headline('%[1]s', replace(replace(replace(m.message, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), q,
+		'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS highlight
+FROM user_messages m
+JOIN chat_users cu ON cu.chat_id = m.chat_id AND cu.user_id = @uid
+JOIN users u ON u.id = m.from_id
+CROSS JOIN websearch_to_tsquery('%[1]s', @query) q
+WHERE m.deleted_at IS NULL AND m.removed_at IS NULL AND m.search @@ q AND (@chat = '' OR m.chat_id = @chat)
+	AND NOT EXISTS (SELECT 1 FROM hidden_messages h WHERE h.msg_id = m.id AND h.user_id = @uid)
+ORDER BY ts_rank(m.search, q) DESC, m.created_at DESC
+LIMIT @limit`, model.MessageSearchConfig)
+
+// SearchMessages -> Searches the messages of a chat, or of every chat of the user when chatID is empty.
+// Postgres ranks the matches with the message search vectors, other databases (the sqlite test db) fall back to LIKE
+func (db *GormDB) SearchMessages(uid, chatID, query string, limit int, hits *[]model.MessageHit) error {
+	var err error
+	if db.DB.Dialector.Name() == "postgres" {
+		err = db.DB.Raw(messageSearchQuery, sql.Named("uid", uid), sql.Named("query", query),
+			sql.Named("chat", chatID), sql.Named("limit", limit)).Scan(hits).Error
+	} else {
+		terms := searchTerms(query)
+		tx := db.DB.Table("user_messages m").
+			Select("m.id, m.chat_id, m.from_id, u.username AS from_name, m.message, m.created_at, m.updated_at").
+			Joins("JOIN chat_users cu ON cu.chat_id = m.chat_id AND cu.user_id = ?", uid).
+			Joins("JOIN users u ON u.id = m.from_id").
+			Where("m.deleted_at IS NULL AND m.removed_at IS NULL").
+			Where("NOT EXISTS (SELECT 1 FROM hidden_messages h WHERE h.msg_id = m.id AND h.user_id = ?)", uid)
+		if chatID != "" {
+			tx = tx.Where("m.chat_id = ?", chatID)
+		}
+		for _, term := range terms {
+			tx = tx.Where(`m.message LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(term)+"%")
+		}
+		if err = tx.Order("m.created_at DESC").Limit(limit).Scan(hits).Error; err == nil {
+			for i := range *hits {
+				(*hits)[i].Highlight = highlightTerms((*hits)[i].Message, terms)
+			}
+		}
+	}
+
+	if err != nil {
+		db.logError("Failed to search messages", err, "chat_id", chatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to search messages."}
+	}
+	return nil
+}
+
+var (
+	likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
+// searchTerms -> Splits a search into its words, dropping the web search operators
+func searchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		term := strings.Trim(field, `"-`)
+		if term != "" && !strings.EqualFold(term, "or") {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// highlightTerms -> Escapes a message and marks every occurrence of the terms, the same output as the postgres ts_headline
+func highlightTerms(msg string, terms []string) string {
+	escaped := htmlEscaper.Replace(msg)
+	if len(terms) == 0 {
+		return escaped
+	}
+
+	patterns := make([]string, len(terms))
+	for i, term := range terms {
+		patterns[i] = regexp.QuoteMeta(htmlEscaper.Replace(term))
+	}
+	return regexp.MustCompile("(?i)"+strings.Join(patterns, "|")).ReplaceAllString(escaped, "<mark>$0</mark>")
+}
+
 // FetchChat -> Retrieves a chat from the db
 func (db *GormDB) FetchChat(chatID string, chat *model.Chat) error {
 	if err := db.DB.Where("id = ?", chatID).First(chat).Error; err != nil {
@@ -885,14 +1219,301 @@ func (db *GormDB) FetchChat(chatID string, chat *model.Chat) error {
 	return nil
 }
 
-// FetchUserPreloadCM -> Retrieves a user with the user's chat, chat's messages and members preloaded
-func (db *GormDB) FetchUserPreloadCM(user *model.User, uid string) error {
-	if err := db.DB.Preload("Chats.Messages").Preload("Chats.Users").Where("id = ?", uid).First(user).Error; err != nil {
+// chatSummariesQuery -> The chat list of a user, with the last message (not deleted for them), the unread count and the other member
+// of the direct chats, in a single round trip. Plain SQL so it runs on both postgres and the sqlite test db.
+const chatSummariesQuery = `
+SELECT c.id AS chat_id, c.name, c."group" AS is_group, cu.last_read_at,
+	cu.muted_until, cu.archived, cu.pin_position, cu.notify_level,
+	(SELECT COUNT(*) FROM user_messages m
+		WHERE m.chat_id = c.id AND m.deleted_at IS NULL AND m.removed_at IS NULL AND m.from_id <> cu.user_id
+		AND (cu.last_read_at IS NULL OR m.created_at > cu.last_read_at)) AS unread,
+	(SELECT COUNT(*) FROM message_mentions mm JOIN user_messages m ON m.id = mm.msg_id
+		WHERE mm.chat_id = c.id AND mm.user_id = cu.user_id AND m.deleted_at IS NULL AND m.removed_at IS NULL
+		AND (cu.last_read_at IS NULL OR m.created_at > cu.last_read_at)) AS unread_mentions,
+	lm.id AS last_msg_id, lm.message AS last_msg, lm.from_id AS last_from_id, COALESCE(lu.username, '') AS last_from_name,
+	lm.created_at AS last_sent, lm.updated_at AS last_edited, lm.edited_at AS last_edited_at, lm.removed_at AS last_removed_at,
+	p.id AS peer_id, p.username AS peer_name, p.last_seen AS peer_last_seen, p.hide_presence AS peer_hidden
+FROM chat_users cu
+JOIN chats c ON c.id = cu.chat_id AND c.deleted_at IS NULL
+LEFT JOIN user_messages lm ON lm.id = (
+	SELECT m.id FROM user_messages m WHERE m.chat_id = c.id AND m.deleted_at IS NULL
+	AND NOT EXISTS (SELECT 1 FROM hidden_messages h WHERE h.msg_id = m.id AND h.user_id = cu.user_id)
+	ORDER BY m.created_at DESC, m.id DESC LIMIT 1)
+LEFT JOIN users lu ON lu.id = lm.from_id
+LEFT JOIN users p ON NOT c."group" AND p.id = (
+	SELECT o.user_id FROM chat_users o WHERE o.chat_id = c.id AND o.user_id <> cu.user_id LIMIT 1)
+WHERE cu.user_id = ? AND cu.archived = ?
+ORDER BY CASE WHEN cu.pin_position IS NULL THEN 1 ELSE 0 END, cu.pin_position, COALESCE(lm.created_at, c.created_at) DESC`
+
+const pendingMessagesQuery = `
+SELECT u.id AS user_id, u.username, u.email, u.digest_frequency, u.digest_sent_at, u.unsubscribe_token,
+	COALESCE(pm.digest, TRUE) AS messages_digest, COALESCE(pn.digest, TRUE) AS mentions_digest,
+	c.id AS chat_id, c.name AS chat_name, c."group" AS is_group, cu.muted_until, cu.notify_level,
+	m.id AS msg_id, m.message, fu.username AS from_name, m.created_at,
+	EXISTS (SELECT 1 FROM message_mentions mm WHERE mm.msg_id = m.id AND mm.user_id = u.id) AS mentioned
+FROM chat_users cu
+JOIN users u ON u.id = cu.user_id AND u.deleted_at IS NULL
+JOIN chats c ON c.id = cu.chat_id AND c.deleted_at IS NULL
+JOIN user_messages m ON m.chat_id = c.id AND m.deleted_at IS NULL AND m.removed_at IS NULL AND NOT m.system
+	AND m.from_id <> u.id
+JOIN users fu ON fu.id = m.from_id
+LEFT JOIN notification_preferences pm ON pm.user_id = u.id AND pm.category = 'messages'
+LEFT JOIN notification_preferences pn ON pn.user_id = u.id AND pn.category = 'mentions'
+WHERE u.digest_frequency <> 'off' AND m.created_at > ? AND m.created_at <= ?
+	AND (cu.last_read_at IS NULL OR m.created_at > cu.last_read_at)
+	AND (u.last_seen IS NULL OR m.created_at > u.last_seen)
+	AND (u.digest_sent_at IS NULL OR m.created_at > u.digest_sent_at)
+	AND NOT EXISTS (SELECT 1 FROM hidden_messages h WHERE h.msg_id = m.id AND h.user_id = u.id)
+ORDER BY u.id, m.created_at, m.id`
+
+// FetchPendingMessages -> Retrieves the messages sent between since and until that their receivers haven't read,
+// nor been online for, since their last digest. Users with the digests off are left out
+func (db *GormDB) FetchPendingMessages(since, until time.Time, msgs *[]model.PendingMessage) error {
+	if err := db.DB.Raw(pendingMessagesQuery, since, until).Scan(msgs).Error; err != nil {
+		db.logError("Failed to fetch pending messages", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the pending messages."}
+	}
+	return nil
+}
+
+// UpdateDigestSent -> Records that the messages of a user up to sentAt were sent in a digest
+func (db *GormDB) UpdateDigestSent(uid string, sentAt time.Time) error {
+	if err := db.DB.Model(&model.User{}).Where("id = ?", uid).UpdateColumn("digest_sent_at", sentAt).Error; err != nil {
+		db.logError("Failed to update digest time", err, "user_id", uid)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to update the digest."}
+	}
+	return nil
+}
+
+// SetUnsubscribeToken -> Gives a user the token of their unsubscribe links
+func (db *GormDB) SetUnsubscribeToken(uid, token string) error {
+	if err := db.DB.Model(&model.User{}).Where("id = ?", uid).UpdateColumn("unsubscribe_token", token).Error; err != nil {
+		db.logError("Failed to set unsubscribe token", err, "user_id", uid)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to create the unsubscribe link."}
+	}
+	return nil
+}
+
+// UnsubscribeDigest -> Turns off the message digests of the user with the unsubscribe token
+func (db *GormDB) UnsubscribeDigest(token string) error {
+	res := db.DB.Model(&model.User{}).Where("unsubscribe_token = ?", token).UpdateColumn("digest_frequency", model.DigestOff)
+	if res.Error != nil {
+		db.logError("Failed to unsubscribe from digests", res.Error)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to unsubscribe."}
+	}
+	if res.RowsAffected == 0 {
+		return &CustomMessage{http.StatusNotFound, "Invalid unsubscribe link."}
+	}
+	return nil
+}
+
+// CheckUnsubscribeToken -> Checks that the unsubscribe token belongs to a user
+func (db *GormDB) CheckUnsubscribeToken(token string) error {
+	var count int64
+	if err := db.DB.Model(&model.User{}).Where("unsubscribe_token = ?", token).Count(&count).Error; err != nil {
+		db.logError("Failed to check unsubscribe token", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to check the unsubscribe link."}
+	}
+	if count == 0 {
+		return &CustomMessage{http.StatusNotFound, "Invalid unsubscribe link."}
+	}
+	return nil
+}
+
+// AddNotification -> Records a notification of a user
+func (db *GormDB) AddNotification(notification *model.Notification) error {
+	if err := db.DB.Create(notification).Error; err != nil {
+		db.logError("Failed to add notification", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to add notification."}
+	}
+	return nil
+}
+
+// FetchNotificationPreferences -> Retrieves the preferences a user changed, the other categories have every channel on
+func (db *GormDB) FetchNotificationPreferences(uid string, prefs *[]model.NotificationPreference) error {
+	if err := db.DB.Where("user_id = ?", uid).Find(prefs).Error; err != nil {
+		db.logError("Failed to fetch notification preferences", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the notification preferences."}
+	}
+	return nil
+}
+
+// FetchNotificationPreference -> Retrieves the preference of a user for a category, the default one when they never changed it
+func (db *GormDB) FetchNotificationPreference(uid, category string, pref *model.NotificationPreference) error {
+	if err := db.DB.Where("user_id = ? AND category = ?", uid, category).First(pref).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return &CustomMessage{http.StatusNotFound, "User not found."}
-		} else {
-			return &CustomMessage{http.StatusInternalServerError, "Failed to fetch user chats."}
+			*pref = model.DefaultPreference(uid, category)
+			return nil
+		}
+		db.logError("Failed to fetch notification preference", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the notification preferences."}
+	}
+	return nil
+}
+
+// SaveNotificationPreference -> Creates or replaces the preference of a user for a category
+func (db *GormDB) SaveNotificationPreference(pref *model.NotificationPreference) error {
+	if err := db.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(pref).Error; err != nil {
+		db.logError("Failed to save notification preference", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to update the notification preferences."}
+	}
+	return nil
+}
+
+// UnsubscribeEmail -> Turns off the emails of a category for the user with the unsubscribe token
+func (db *GormDB) UnsubscribeEmail(token, category string) error {
+	var user model.User
+	if err := db.DB.Select("id").Where("unsubscribe_token = ?", token).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &CustomMessage{http.StatusNotFound, "Invalid unsubscribe link."}
+		}
+		db.logError("Failed to fetch user by unsubscribe token", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to unsubscribe."}
+	}
+
+	var pref model.NotificationPreference
+	if err := db.FetchNotificationPreference(user.ID, category, &pref); err != nil {
+		return err
+	}
+	pref.Email = false
+	return db.SaveNotificationPreference(&pref)
+}
+
+// FetchNotifications -> Retrieves a page of the notifications of a user, newest first. The page holds the latest
+// notifications or the ones right before the notification with id before
+func (db *GormDB) FetchNotifications(uid, before string, limit int, notifications *[]model.Notification) error {
+	query := db.DB.Where("user_id = ?", uid)
+	if before != "" {
+		var cursor model.Notification
+		if err := db.DB.Select("id", "created_at").Where("id = ? AND user_id = ?", before, uid).First(&cursor).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &CustomMessage{http.StatusNotFound, "Notification not found."}
+			}
+			db.logError("Failed to fetch notification", err)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to fetch notifications."}
 		}
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+	}
+
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(notifications).Error; err != nil {
+		db.logError("Failed to fetch notifications", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch notifications."}
+	}
+	return nil
+}
+
+// CountUnreadNotifications -> Counts the notifications of a user not read yet, the badge of the notification center
+func (db *GormDB) CountUnreadNotifications(uid string, count *int64) error {
+	if err := db.DB.Model(&model.Notification{}).Where("user_id = ? AND read_at IS NULL", uid).Count(count).Error; err != nil {
+		db.logError("Failed to count unread notifications", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to count unread notifications."}
+	}
+	return nil
+}
+
+// MarkNotificationRead -> Marks a notification of a user as read, a notification already read keeps its read time
+func (db *GormDB) MarkNotificationRead(id, uid string, readAt time.Time) error {
+	var notification model.Notification
+	if err := db.DB.Where("id = ? AND user_id = ?", id, uid).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &CustomMessage{http.StatusNotFound, "Notification not found."}
+		}
+		db.logError("Failed to fetch notification", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to update the notification."}
+	}
+	if notification.ReadAt != nil {
+		return nil
+	}
+
+	if err := db.DB.Model(&notification).UpdateColumn("read_at", readAt).Error; err != nil {
+		db.logError("Failed to mark notification as read", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to update the notification."}
+	}
+	return nil
+}
+
+// MarkAllNotificationsRead -> Marks every unread notification of a user as read
+func (db *GormDB) MarkAllNotificationsRead(uid string, readAt time.Time) error {
+	if err := db.DB.Model(&model.Notification{}).Where("user_id = ? AND read_at IS NULL", uid).UpdateColumn("read_at", readAt).Error; err != nil {
+		db.logError("Failed to mark notifications as read", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to update the notifications."}
+	}
+	return nil
+}
+
+// DeleteNotification -> Deletes a notification of a user
+func (db *GormDB) DeleteNotification(id, uid string) error {
+	res := db.DB.Where("id = ? AND user_id = ?", id, uid).Delete(&model.Notification{})
+	if res.Error != nil {
+		db.logError("Failed to delete notification", res.Error)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to delete the notification."}
+	}
+	if res.RowsAffected == 0 {
+		return &CustomMessage{http.StatusNotFound, "Notification not found."}
+	}
+	return nil
+}
+
+// FetchChatSummaries -> Retrieves the chat list (or the archived chats) of a user, the pinned chats by their position
+// then the rest most recently active first
+func (db *GormDB) FetchChatSummaries(uid string, archived bool, chats *[]model.ChatSummary) error {
+	if err := db.DB.Raw(chatSummariesQuery, uid, archived).Scan(chats).Error; err != nil {
+		db.logError("Failed to fetch user chats", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch user chats."}
+	}
+	return nil
+}
+
+// UpdateChatMember -> Updates the preferences of a member of a chat
+func (db *GormDB) UpdateChatMember(chatID, uid string, fields map[string]any) error {
+	res := db.DB.Model(&model.ChatUser{}).Where("chat_id = ? AND user_id = ?", chatID, uid).Updates(fields)
+	if res.Error != nil {
+		db.logError("Failed to update chat preferences", res.Error, "chat_id", chatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to update the chat preferences."}
+	}
+	if res.RowsAffected == 0 {
+		return &CustomMessage{http.StatusForbidden, "You aren't a member of this chat."}
+	}
+	return nil
+}
+
+// CountPinnedChats -> Counts the chats a user pinned
+func (db *GormDB) CountPinnedChats(uid string, count *int64) error {
+	if err := db.DB.Model(&model.ChatUser{}).Where("user_id = ? AND pin_position IS NOT NULL", uid).Count(count).Error; err != nil {
+		db.logError("Failed to count pinned chats", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to update the chat preferences."}
+	}
+	return nil
+}
+
+// FetchLastMsg -> Retrieves the last message of a chat
+func (db *GormDB) FetchLastMsg(chatID string, msg *model.UserMessage) error {
+	if err := db.DB.Where("chat_id = ?", chatID).Order("created_at DESC, id DESC").First(msg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &CustomMessage{http.StatusNotFound, "Msg not found."}
+		}
+		db.logError("Failed to fetch the last msg", err, "chat_id", chatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch msg."}
+	}
+	return nil
+}
+
+// MarkChatRead -> Moves the read cursor of a member up to a message, cursors never move backwards
+func (db *GormDB) MarkChatRead(chatID, uid, msgID string, sent time.Time) error {
+	if err := db.DB.Model(&model.ChatUser{}).
+		Where("chat_id = ? AND user_id = ? AND (last_read_at IS NULL OR last_read_at < ?)", chatID, uid, sent).
+		Updates(map[string]any{"last_read_id": msgID, "last_read_at": sent}).Error; err != nil {
+		db.logError("Failed to mark chat read", err, "chat_id", chatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to mark chat as read."}
+	}
+	return nil
+}
+
+// FetchReadCursors -> Retrieves the read cursor of every member of a chat
+func (db *GormDB) FetchReadCursors(chatID string, cursors *[]model.ChatUser) error {
+	if err := db.DB.Where("chat_id = ?", chatID).Find(cursors).Error; err != nil {
+		db.logError("Failed to fetch read cursors", err, "chat_id", chatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch read receipts."}
 	}
 	return nil
 }
@@ -921,11 +1542,60 @@ func (db *GormDB) FetchMsg(msg *model.UserMessage, mid string) error {
 	return nil
 }
 
-// SaveMsg -> Saves a message in the db
-func (db *GormDB) SaveMsg(msg *model.UserMessage) error {
-	if err := db.DB.Save(msg).Error; err != nil {
-		log.Println("Failed to save msg with id -> ", msg.ID, "err -> ", err.Error())
-		return &CustomMessage{http.StatusInternalServerError, "Failed to edit msg."}
+// EditMsg -> Changes the text of a message, keeping the previous one as a revision
+func (db *GormDB) EditMsg(msg *model.UserMessage, text string) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		revision := model.MessageRevision{MsgID: msg.ID, EditorID: msg.FromID, Message: msg.Message}
+		if err := tx.Create(&revision).Error; err != nil {
+			db.logError("Failed to save msg revision", err, "msg_id", msg.ID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to edit msg."}
+		}
+
+		now := time.Now()
+		msg.Message, msg.EditedAt = text, &now
+		if err := tx.Save(msg).Error; err != nil {
+			db.logError("Failed to save msg", err, "msg_id", msg.ID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to edit msg."}
+		}
+		return nil
+	})
+}
+
+// FetchMsgRevisions -> Retrieves the previous texts of a message, oldest first
+func (db *GormDB) FetchMsgRevisions(msgID string, revisions *[]model.MessageRevision) error {
+	if err := db.DB.Where("msg_id = ?", msgID).Order("created_at ASC").Find(revisions).Error; err != nil {
+		db.logError("Failed to fetch msg revisions", err, "msg_id", msgID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the edit history."}
+	}
+	return nil
+}
+
+// FetchRevisions -> Retrieves the earlier versions of the messages, oldest first
+func (db *GormDB) FetchRevisions(msgIDs []string, revisions *[]model.MessageRevision) error {
+	if len(msgIDs) == 0 {
+		return nil
+	}
+	if err := db.DB.Where("msg_id IN ?", msgIDs).Order("created_at ASC").Find(revisions).Error; err != nil {
+		db.logError("Failed to fetch msg revisions", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the edit history."}
+	}
+	return nil
+}
+
+// FetchChatExport -> Retrieves a batch of the chat history visible to the user, oldest first, starting right after
+// the last message of the previous batch (from the start when nil)
+func (db *GormDB) FetchChatExport(chatID, uid string, after *model.UserMessage, limit int, msgs *[]model.UserMessage) error {
+	query := db.DB.Preload("FromUser", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id", "username")
+	}).Where("chat_id = ?", chatID).
+		Where("NOT EXISTS (SELECT 1 FROM hidden_messages h WHERE h.msg_id = user_messages.id AND h.user_id = ?)", uid)
+	if after != nil {
+		query = query.Where("(created_at > ? OR (created_at = ? AND id > ?))", after.CreatedAt, after.CreatedAt, after.ID)
+	}
+
+	if err := query.Order("created_at ASC, id ASC").Limit(limit).Find(msgs).Error; err != nil {
+		db.logError("Failed to export chat history", err, "chat_id", chatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to export the chat."}
 	}
 	return nil
 }
@@ -933,25 +1603,103 @@ func (db *GormDB) SaveMsg(msg *model.UserMessage) error {
 // SaveChat -> Saves a chat in the db
 func (db *GormDB) SaveChat(chat *model.Chat) error {
 	if err := db.DB.Save(chat).Error; err != nil {
-		log.Printf("Failed to save chat with id -> %v, err -> %v", chat.ID, err.Error())
+		db.logError("Failed to save chat", err, "chat_id", chat.ID)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to edit chat."}
 	}
 	return nil
 }
 
-// DeleteMsg -> Deletes a message in the db
-func (db *GormDB) DeleteMsg(msg *model.UserMessage) error {
-	if err := db.DB.Delete(msg).Error; err != nil {
-		log.Println("Failed to delete msg with id -> ", msg.ID, "err -> ", err.Error())
+// RemoveMsg -> Deletes a message for everyone, leaving a tombstone in the chat. The text is kept as a revision
+// for moderation, the reactions and attachments go away with it
+func (db *GormDB) RemoveMsg(msg *model.UserMessage, by string) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		revision := model.MessageRevision{MsgID: msg.ID, EditorID: by, Message: msg.Message}
+		if err := tx.Create(&revision).Error; err != nil {
+			db.logError("Failed to save msg revision", err, "msg_id", msg.ID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to delete msg."}
+		}
+
+		if err := tx.Where("msg_id = ?", msg.ID).Delete(&model.MessageReaction{}).Error; err != nil {
+			db.logError("Failed to delete msg reactions", err, "msg_id", msg.ID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to delete msg."}
+		}
+		if err := tx.Where("msg_id = ?", msg.ID).Delete(&model.Attachment{}).Error; err != nil {
+			db.logError("Failed to delete msg attachments", err, "msg_id", msg.ID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to delete msg."}
+		}
+		if err := tx.Where("msg_id = ?", msg.ID).Delete(&model.MessageMention{}).Error; err != nil {
+			db.logError("Failed to delete msg mentions", err, "msg_id", msg.ID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to delete msg."}
+		}
+
+		now := time.Now()
+		msg.Message, msg.RemovedAt, msg.RemovedByID = "", &now, &by
+		if err := tx.Save(msg).Error; err != nil {
+			db.logError("Failed to delete msg", err, "msg_id", msg.ID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to delete msg."}
+		}
+		return nil
+	})
+}
+
+// HideMsg -> Deletes a message for a single member
+func (db *GormDB) HideMsg(msgID, uid string) error {
+	hidden := model.HiddenMessage{MsgID: msgID, UserID: uid}
+	if err := db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&hidden).Error; err != nil {
+		db.logError("Failed to hide msg", err, "msg_id", msgID)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to delete msg."}
 	}
 	return nil
 }
 
+// FetchMentionedMembers -> Retrieves the members of a chat with one of the usernames (case insensitive), along with
+// their username and email
+func (db *GormDB) FetchMentionedMembers(chatID string, usernames []string, members *[]model.ChatUser) error {
+	lower := make([]string, len(usernames))
+	for i, name := range usernames {
+		lower[i] = strings.ToLower(name)
+	}
+
+	if err := db.DB.Preload("User", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id", "username", "email")
+	}).Joins("JOIN users u ON u.id = chat_users.user_id AND u.deleted_at IS NULL").
+		Where("chat_users.chat_id = ? AND LOWER(u.username) IN ?", chatID, lower).
+		Find(members).Error; err != nil {
+		db.logError("Failed to fetch mentioned members", err, "chat_id", chatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the mentioned members."}
+	}
+	return nil
+}
+
+// AddMentions -> Records the members mentioned in a message
+func (db *GormDB) AddMentions(mentions []model.MessageMention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+	if err := db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&mentions).Error; err != nil {
+		db.logError("Failed to save mentions", err, "msg_id", mentions[0].MsgID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to save the mentions."}
+	}
+	return nil
+}
+
+// FetchMentions -> Retrieves the members mentioned in messages with their usernames
+func (db *GormDB) FetchMentions(msgIDs []string, mentions *[]model.MessageMention) error {
+	if len(msgIDs) == 0 {
+		return nil
+	}
+	if err := db.DB.Preload("User", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id", "username")
+	}).Where("msg_id IN ?", msgIDs).Order("created_at ASC").Find(mentions).Error; err != nil {
+		db.logError("Failed to fetch mentions", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the mentions."}
+	}
+	return nil
+}
+
 // FindChat -> Finds an existing chat between two users with the messages preloaded
 func (db *GormDB) FindChat(uid, fid string, chat *model.Chat) error {
 	if err := db.DB.
-		Preload("Messages").
 		Preload("Users").
 		Joins("JOIN chat_users cu1 ON cu1.chat_id = chats.id AND cu1.user_id = ?", uid).
 		Joins("JOIN chat_users cu2 ON cu2.chat_id = chats.id AND cu2.user_id = ?", fid).
@@ -960,17 +1708,39 @@ func (db *GormDB) FindChat(uid, fid string, chat *model.Chat) error {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return &CustomMessage{http.StatusNotFound, "Chat for friend not found."}
 		} else {
-			log.Println(err.Error())
+			db.logError("Failed to retrieve chat between friends", err)
 			return &CustomMessage{http.StatusInternalServerError, "Failed to fetch chat for friend."}
 		}
 	}
 	return nil
 }
 
+// CheckChatMember -> Checks that a user is a member of a chat
+func (db *GormDB) CheckChatMember(chatID, uid string) error {
+	var count int64
+	if err := db.DB.Model(&model.ChatUser{}).Where("chat_id = ? AND user_id = ?", chatID, uid).Count(&count).Error; err != nil {
+		db.logError("Failed to check chat membership", err, "chat_id", chatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to check chat membership."}
+	}
+	if count == 0 {
+		return &CustomMessage{http.StatusForbidden, "You aren't a member of this chat."}
+	}
+	return nil
+}
+
+// FetchUserChatIDs -> Retrieves the ids of every chat a user is a member of
+func (db *GormDB) FetchUserChatIDs(uid string, ids *[]string) error {
+	if err := db.DB.Model(&model.ChatUser{}).Where("user_id = ?", uid).Pluck("chat_id", ids).Error; err != nil {
+		db.logError("Failed to fetch user chat ids", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch user chats."}
+	}
+	return nil
+}
+
 // AddUserChat -> Adds a user to a chat group in the db
 func (db *GormDB) AddUserChat(chat *model.Chat, user *model.User) error {
 	if err := db.DB.Model(chat).Association("Users").Append(user); err != nil {
-		log.Printf("Unable to add user with id -> %v to chat with id -> %v, Error: %v", user.ID, chat.ID, err.Error())
+		db.logError("Unable to add user to chat", err, "target_user_id", user.ID, "chat_id", chat.ID)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to add user to chat."}
 	}
 	return nil
@@ -979,7 +1749,7 @@ func (db *GormDB) AddUserChat(chat *model.Chat, user *model.User) error {
 // RemoveUserChat -> Removes a user from a chat group in the db
 func (db *GormDB) RemoveUserChat(chat *model.Chat, user *model.User) error {
 	if err := db.DB.Model(chat).Association("Users").Delete(user); err != nil {
-		log.Printf("Unable to remove user with id -> %v from chat with id -> %v, Error: %v", user.ID, chat.ID, err.Error())
+		db.logError("Unable to remove user from chat", err, "target_user_id", user.ID, "chat_id", chat.ID)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to remove user from chat."}
 	}
 	return nil
@@ -988,7 +1758,7 @@ func (db *GormDB) RemoveUserChat(chat *model.Chat, user *model.User) error {
 // LeaveChat -> Leave a chat group
 func (db *GormDB) LeaveChat(chat *model.Chat, user *model.User) error {
 	if err := db.DB.Model(chat).Association("Users").Delete(user); err != nil {
-		log.Println("Failed to remove user from chat with ID -> ", err.Error())
+		db.logError("Failed to leave chat", err, "chat_id", chat.ID)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to leave chat."}
 	}
 	return nil
@@ -997,15 +1767,211 @@ func (db *GormDB) LeaveChat(chat *model.Chat, user *model.User) error {
 // DeleteChat -> Delete a chat group in the db
 func (db *GormDB) DeleteChat(chat *model.Chat) error {
 	if err := db.DB.Delete(chat).Error; err != nil {
-		log.Printf("Unable to delete a group chat with id -> %v , err -> %v ", chat.ID, err.Error())
+		db.logError("Unable to delete group chat", err, "chat_id", chat.ID)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to delete chat."}
 	}
 	return nil
 }
 
+// FetchChatMember -> Retrieves the membership of a user in a chat with their username preloaded
+func (db *GormDB) FetchChatMember(chatID, uid string, member *model.ChatUser) error {
+	if err := db.DB.Preload("User", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id", "username")
+	}).Where("chat_id = ? AND user_id = ?", chatID, uid).First(member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &CustomMessage{http.StatusForbidden, "You aren't a member of this chat."}
+		}
+		db.logError("Failed to fetch chat member", err, "chat_id", chatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to check chat membership."}
+	}
+	return nil
+}
+
+// FetchChatMembers -> Retrieves the members of a chat with their usernames preloaded
+func (db *GormDB) FetchChatMembers(chatID string, members *[]model.ChatUser) error {
+	if err := db.DB.Preload("User", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id", "username")
+	}).Where("chat_id = ?", chatID).Find(members).Error; err != nil {
+		db.logError("Failed to fetch chat members", err, "chat_id", chatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the chat members."}
+	}
+	return nil
+}
+
+// SetMemberRole -> Changes the role of a member of a group
+func (db *GormDB) SetMemberRole(chatID, uid, role string) error {
+	res := db.DB.Model(&model.ChatUser{}).Where("chat_id = ? AND user_id = ?", chatID, uid).Update("role", role)
+	if res.Error != nil {
+		db.logError("Failed to set member role", res.Error, "chat_id", chatID, "target_user_id", uid)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to update the member role."}
+	}
+	if res.RowsAffected == 0 {
+		return &CustomMessage{http.StatusNotFound, "User isn't a member of this chat."}
+	}
+	return nil
+}
+
+// TransferChatOwner -> Makes a member the owner of a group, the previous owner stays on as an admin
+func (db *GormDB) TransferChatOwner(chat *model.Chat, uid string) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.ChatUser{}).Where("chat_id = ? AND user_id = ?", chat.ID, uid).Update("role", model.RoleOwner)
+		if res.Error != nil {
+			db.logError("Failed to transfer chat ownership", res.Error, "chat_id", chat.ID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to transfer ownership."}
+		}
+		if res.RowsAffected == 0 {
+			return &CustomMessage{http.StatusNotFound, "User isn't a member of this chat."}
+		}
+
+		if chat.OwnerID != nil {
+			if err := tx.Model(&model.ChatUser{}).Where("chat_id = ? AND user_id = ?", chat.ID, *chat.OwnerID).
+				Update("role", model.RoleAdmin).Error; err != nil {
+				db.logError("Failed to transfer chat ownership", err, "chat_id", chat.ID)
+				return &CustomMessage{http.StatusInternalServerError, "Failed to transfer ownership."}
+			}
+		}
+
+		chat.OwnerID = &uid
+		if err := tx.Model(chat).Update("owner_id", uid).Error; err != nil {
+			db.logError("Failed to transfer chat ownership", err, "chat_id", chat.ID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to transfer ownership."}
+		}
+		return nil
+	})
+}
+
+// AddChatInvite -> Creates an invite link of a group
+func (db *GormDB) AddChatInvite(invite *model.ChatInvite) error {
+	if err := db.DB.Create(invite).Error; err != nil {
+		db.logError("Failed to create chat invite", err, "chat_id", invite.ChatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to create the invite link."}
+	}
+	return nil
+}
+
+// FetchChatInvite -> Retrieves an invite link by its id
+func (db *GormDB) FetchChatInvite(id string, invite *model.ChatInvite) error {
+	if err := db.DB.Where("id = ?", id).First(invite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &CustomMessage{http.StatusNotFound, "Invite link not found."}
+		}
+		db.logError("Failed to fetch chat invite", err, "invite_id", id)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the invite link."}
+	}
+	return nil
+}
+
+// FetchChatInviteByToken -> Retrieves an invite link by its token with the chat preloaded
+func (db *GormDB) FetchChatInviteByToken(token string, invite *model.ChatInvite) error {
+	if err := db.DB.Preload("Chat").Where("token = ?", token).First(invite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &CustomMessage{http.StatusNotFound, "Invite link not found."}
+		}
+		db.logError("Failed to fetch chat invite", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the invite link."}
+	}
+	return nil
+}
+
+// FetchChatInvites -> Retrieves the invite links of a group that weren't revoked, newest first
+func (db *GormDB) FetchChatInvites(chatID string, invites *[]model.ChatInvite) error {
+	if err := db.DB.Where("chat_id = ? AND revoked_at IS NULL", chatID).Order("created_at DESC").Find(invites).Error; err != nil {
+		db.logError("Failed to fetch chat invites", err, "chat_id", chatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the invite links."}
+	}
+	return nil
+}
+
+// RevokeChatInvite -> Stops an invite link from working
+func (db *GormDB) RevokeChatInvite(invite *model.ChatInvite) error {
+	now := time.Now()
+	if err := db.DB.Model(invite).Update("revoked_at", now).Error; err != nil {
+		db.logError("Failed to revoke chat invite", err, "invite_id", invite.ID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to revoke the invite link."}
+	}
+	invite.RevokedAt = &now
+	return nil
+}
+
+// JoinChatInvite -> Uses an invite link to join its group, or to ask to join it when the group needs approval.
+// The use is counted with a conditional update so concurrent joins can't go over the cap
+func (db *GormDB) JoinChatInvite(invite *model.ChatInvite, uid string, approval bool) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.ChatInvite{}).
+			Where("id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?) AND (max_uses = 0 OR uses < max_uses)", invite.ID, time.Now()).
+			Update("uses", gorm.Expr("uses + 1"))
+		if res.Error != nil {
+			db.logError("Failed to use chat invite", res.Error, "invite_id", invite.ID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to join the chat."}
+		}
+		if res.RowsAffected == 0 {
+			return &CustomMessage{http.StatusGone, "This invite link has expired."}
+		}
+
+		var err error
+		if approval {
+			res = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.ChatJoinRequest{ChatID: invite.ChatID, UserID: uid, InviteID: invite.ID})
+			if err = res.Error; err == nil && res.RowsAffected == 0 {
+				return &CustomMessage{http.StatusConflict, "You already asked to join this chat."}
+			}
+		} else {
+			err = tx.Create(&model.ChatUser{ChatID: invite.ChatID, UserID: uid, Role: model.RoleMember}).Error
+		}
+		if err != nil {
+			db.logError("Failed to join chat", err, "chat_id", invite.ChatID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to join the chat."}
+		}
+		return nil
+	})
+}
+
+// FetchJoinRequests -> Retrieves the pending join requests of a group with the usernames preloaded, oldest first
+func (db *GormDB) FetchJoinRequests(chatID string, reqs *[]model.ChatJoinRequest) error {
+	if err := db.DB.Preload("User", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id", "username")
+	}).Where("chat_id = ?", chatID).Order("created_at ASC").Find(reqs).Error; err != nil {
+		db.logError("Failed to fetch join requests", err, "chat_id", chatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the join requests."}
+	}
+	return nil
+}
+
+// FetchJoinRequest -> Retrieves the join request of a user with the username preloaded
+func (db *GormDB) FetchJoinRequest(chatID, uid string, req *model.ChatJoinRequest) error {
+	if err := db.DB.Preload("User", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id", "username")
+	}).Where("chat_id = ? AND user_id = ?", chatID, uid).First(req).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &CustomMessage{http.StatusNotFound, "Join request not found."}
+		}
+		db.logError("Failed to fetch join request", err, "chat_id", chatID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the join request."}
+	}
+	return nil
+}
+
+// ReviewJoinRequest -> Approves (adding the user to the group) or declines a join request
+func (db *GormDB) ReviewJoinRequest(req *model.ChatJoinRequest, approve bool) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("chat_id = ? AND user_id = ?", req.ChatID, req.UserID).Delete(&model.ChatJoinRequest{}).Error; err != nil {
+			db.logError("Failed to delete join request", err, "chat_id", req.ChatID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to review the join request."}
+		}
+		if !approve {
+			return nil
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.ChatUser{ChatID: req.ChatID, UserID: req.UserID, Role: model.RoleMember}).Error; err != nil {
+			db.logError("Failed to add approved member", err, "chat_id", req.ChatID)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to review the join request."}
+		}
+		return nil
+	})
+}
+
 func (db *GormDB) AddTransaction(transc *model.Transactions) error {
 	if err := db.DB.Create(transc).Error; err != nil {
-		log.Println("An error occured while trying to create a transaction -> ", err.Error())
+		db.logError("Failed to create transaction", err)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to initiate transaction"}
 	}
 	return nil
@@ -1046,7 +2012,7 @@ func (db *GormDB) AddTranscSub(transc *model.Transactions, sub *model.Subscripti
 
 		return nil
 	}); err != nil {
-		log.Println("[DB TRANSACTION] An error occured, err -> ", err.Error())
+		db.logError("Subscription transaction failed", err, "sub_id", sub.ID)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to finish transaction and create subscription"}
 	}
 	return nil
@@ -1069,7 +2035,7 @@ func (db *GormDB) AddTranscSaveSub(transc *model.Transactions, sub *model.Subscr
 
 		return nil
 	}); err != nil {
-		log.Println("[DB TRANSACTION] An error occured, err -> ", err.Error())
+		db.logError("Subscription transaction failed", err, "sub_id", sub.ID)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to finish transaction and create subscription"}
 	}
 	return nil
@@ -1087,7 +2053,7 @@ func (db *GormDB) AddFailedSub(sub *model.Subscriptions, user *model.User) error
 
 		return nil
 	}); err != nil {
-		log.Println("[DB TRANSACTION] An error occured, err -> ", err.Error())
+		db.logError("Subscription transaction failed", err, "sub_id", sub.ID)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to create a Failed subscription"}
 	}
 	return nil
@@ -1095,7 +2061,7 @@ func (db *GormDB) AddFailedSub(sub *model.Subscriptions, user *model.User) error
 
 func (db *GormDB) FetchTrialEndingUsers(users *[]model.User, limit, today time.Time) error {
 	if err := db.DB.Where("freetrial <= ? AND freetrial > ? AND trialreminder = ?", limit, today, false).Find(users).Error; err != nil {
-		log.Println("[DB] Failed to retrieve users with ending trial, err -> ", err.Error())
+		db.logError("Failed to retrieve users with ending trial", err)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to retrieve users with ending trial"}
 	}
 
@@ -1104,7 +2070,7 @@ func (db *GormDB) FetchTrialEndingUsers(users *[]model.User, limit, today time.T
 
 func (db *GormDB) UpdateSentReminder(ids []string) error {
 	if err := db.DB.Model(&model.User{}).Where("id IN ?", ids).Update("trialreminder", true).Error; err != nil {
-		log.Println("[DB] Failed to update users trialreminder field, err -> ", err.Error())
+		db.logError("Failed to update users trialreminder field", err)
 		return &CustomMessage{http.StatusInternalServerError, "Failed to update users trialreminder field"}
 	}
 
@@ -1121,3 +2087,175 @@ func (db *GormDB) FetchSub(sub *model.Subscriptions, sid string) error {
 	}
 	return nil
 }
+
+// AddWebhook -> Records a webhook of a user or of one of their projects
+func (db *GormDB) AddWebhook(hook *model.Webhook) error {
+	if err := db.DB.Create(hook).Error; err != nil {
+		db.logError("Failed to add webhook", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to create the webhook."}
+	}
+	return nil
+}
+
+// CountWebhooks -> Counts the webhooks of a user, those of their projects included
+func (db *GormDB) CountWebhooks(uid string, count *int64) error {
+	if err := db.DB.Model(&model.Webhook{}).Where("user_id = ?", uid).Count(count).Error; err != nil {
+		db.logError("Failed to count webhooks", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to create the webhook."}
+	}
+	return nil
+}
+
+// FetchWebhook -> Retrieves a webhook
+func (db *GormDB) FetchWebhook(id string, hook *model.Webhook) error {
+	if err := db.DB.Where("id = ?", id).First(hook).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &CustomMessage{http.StatusNotFound, "Webhook not found."}
+		}
+		db.logError("Failed to fetch webhook", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the webhook."}
+	}
+	return nil
+}
+
+// FetchUserWebhooks -> Retrieves the webhooks of a user and of their projects, oldest first
+func (db *GormDB) FetchUserWebhooks(uid string, hooks *[]model.Webhook) error {
+	if err := db.DB.Where("user_id = ?", uid).Order("created_at ASC").Find(hooks).Error; err != nil {
+		db.logError("Failed to fetch webhooks", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the webhooks."}
+	}
+	return nil
+}
+
+// FetchEventWebhooks -> Retrieves the active webhooks an event goes to, those of the users (not of their projects)
+// and those of the project (none for an empty project id)
+func (db *GormDB) FetchEventWebhooks(users []string, projectID string, hooks *[]model.Webhook) error {
+	query := db.DB.Where("active")
+	if projectID != "" {
+		query = query.Where("(project_id IS NULL AND user_id IN ?) OR project_id = ?", users, projectID)
+	} else {
+		query = query.Where("project_id IS NULL AND user_id IN ?", users)
+	}
+
+	if err := query.Find(hooks).Error; err != nil {
+		db.logError("Failed to fetch event webhooks", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the webhooks."}
+	}
+	return nil
+}
+
+// UpdateWebhook -> Updates the given fields of a webhook
+func (db *GormDB) UpdateWebhook(hook *model.Webhook, fields map[string]any) error {
+	if err := db.DB.Model(hook).Updates(fields).Error; err != nil {
+		db.logError("Failed to update webhook", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to update the webhook."}
+	}
+	return nil
+}
+
+// DeleteWebhook -> Deletes a webhook along with its delivery log
+func (db *GormDB) DeleteWebhook(hook *model.Webhook) error {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", hook.ID).Delete(&model.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(hook).Error
+	})
+	if err != nil {
+		db.logError("Failed to delete webhook", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to delete the webhook."}
+	}
+	return nil
+}
+
+// RecordWebhookFailure -> Counts a failed delivery of a webhook, disabling it once it failed WebhookMaxFailures
+// times in a row, and retrieves its new state
+func (db *GormDB) RecordWebhookFailure(id string, now time.Time, hook *model.Webhook) error {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Webhook{}).Where("id = ?", id).UpdateColumn("failures", gorm.Expr("failures + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Webhook{}).Where("id = ? AND active AND failures >= ?", id, model.WebhookMaxFailures).
+			UpdateColumns(map[string]any{"active": false, "disabled_at": now}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).First(hook).Error
+	})
+	if err != nil {
+		db.logError("Failed to record webhook failure", err, "webhook_id", id)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to update the webhook."}
+	}
+	return nil
+}
+
+// ResetWebhookFailures -> Clears the failures of a webhook after a successful delivery
+func (db *GormDB) ResetWebhookFailures(id string) error {
+	if err := db.DB.Model(&model.Webhook{}).Where("id = ? AND failures > 0", id).UpdateColumn("failures", 0).Error; err != nil {
+		db.logError("Failed to reset webhook failures", err, "webhook_id", id)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to update the webhook."}
+	}
+	return nil
+}
+
+// AddWebhookDeliveries -> Records the deliveries of an event
+func (db *GormDB) AddWebhookDeliveries(deliveries []model.WebhookDelivery) error {
+	if err := db.DB.Create(&deliveries).Error; err != nil {
+		db.logError("Failed to add webhook deliveries", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to deliver the event."}
+	}
+	return nil
+}
+
+// FetchWebhookDelivery -> Retrieves a delivery with its webhook
+func (db *GormDB) FetchWebhookDelivery(id string, delivery *model.WebhookDelivery) error {
+	if err := db.DB.Preload("Webhook").Where("id = ?", id).First(delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &CustomMessage{http.StatusNotFound, "Delivery not found."}
+		}
+		db.logError("Failed to fetch webhook delivery", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the delivery."}
+	}
+	return nil
+}
+
+// FetchWebhookDeliveries -> Retrieves the latest deliveries of a webhook, newest first
+func (db *GormDB) FetchWebhookDeliveries(webhookID string, limit int, deliveries *[]model.WebhookDelivery) error {
+	if err := db.DB.Where("webhook_id = ?", webhookID).Order("created_at DESC, id DESC").Limit(limit).Find(deliveries).Error; err != nil {
+		db.logError("Failed to fetch webhook deliveries", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the deliveries."}
+	}
+	return nil
+}
+
+// SaveWebhookDelivery -> Records the outcome of a delivery attempt
+func (db *GormDB) SaveWebhookDelivery(delivery *model.WebhookDelivery) error {
+	if err := db.DB.Model(delivery).Select("attempts", "status_code", "error", "status", "delivered_at", "next_attempt_at").Updates(delivery).Error; err != nil {
+		db.logError("Failed to save webhook delivery", err, "delivery_id", delivery.ID)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to update the delivery."}
+	}
+	return nil
+}
+
+// ClaimWebhookDeliveries -> Leases the pending deliveries due for an attempt until leaseUntil, only those still due
+// when claimed (not taken by another instance in between) are returned
+func (db *GormDB) ClaimWebhookDeliveries(now, leaseUntil time.Time, limit int, ids *[]string) error {
+	var due []string
+	if err := db.DB.Model(&model.WebhookDelivery{}).Where("status = ? AND next_attempt_at <= ?", model.StatusPending, now).
+		Order("next_attempt_at").Limit(limit).Pluck("id", &due).Error; err != nil {
+		db.logError("Failed to fetch due webhook deliveries", err)
+		return &CustomMessage{http.StatusInternalServerError, "Failed to fetch the deliveries."}
+	}
+
+	for _, id := range due {
+		res := db.DB.Model(&model.WebhookDelivery{}).Where("id = ? AND status = ? AND next_attempt_at <= ?", id, model.StatusPending, now).
+			UpdateColumn("next_attempt_at", leaseUntil)
+		if res.Error != nil {
+			db.logError("Failed to claim webhook delivery", res.Error, "delivery_id", id)
+			return &CustomMessage{http.StatusInternalServerError, "Failed to update the delivery."}
+		}
+		if res.RowsAffected == 1 {
+			*ids = append(*ids, id)
+		}
+	}
+	return nil
+}
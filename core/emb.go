@@ -2,24 +2,28 @@ package core
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
 	"findme/emb"
 
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Embedding interface {
-	QueueUserCreate(id, bio string, skills, interests []string)
-	QueueUserUpdate(id, bio string, skills, interest []string)
-	QueueUserUpdateStatus(id string, status bool)
-	QueueUserDelete(id string)
-	QueueProjectCreate(id, title, description, uid string, skills []string)
-	QueueProjectUpdate(id, title, description string, skills []string)
-	QueueProjectUpdateStatus(id string, status bool)
-	QueueProjectDelete(id string)
+	QueueUserCreate(ctx context.Context, id, bio string, skills, interests []string)
+	QueueUserUpdate(ctx context.Context, id, bio string, skills, interest []string)
+	QueueUserUpdateStatus(ctx context.Context, id string, status bool)
+	QueueUserDelete(ctx context.Context, id string)
+	QueueProjectCreate(ctx context.Context, id, title, description, uid string, skills []string)
+	QueueProjectUpdate(ctx context.Context, id, title, description string, skills []string)
+	QueueProjectUpdateStatus(ctx context.Context, id string, status bool)
+	QueueProjectDelete(ctx context.Context, id string)
+	CircuitState() string
+	CheckHealth() error
+	Stats() HubStats
 }
 
 type EmbeddingJobType int
@@ -55,6 +59,8 @@ type EmbeddingJob struct {
 	Type        EmbeddingJobType
 	Attempts    int
 	MaxAttempts int
+	JobID       string
+	QueuedBy    trace.SpanContext
 
 	// User fields
 	User *UserEmbedding
@@ -64,18 +70,22 @@ type EmbeddingJob struct {
 }
 
 type EmbeddingHub struct {
-	Jobs       chan *EmbeddingJob
-	Quit       chan bool
-	WorkerPool int
-	GRPCADDR   string
+	Jobs          chan *EmbeddingJob
+	Quit          chan bool
+	WorkerPool    int
+	Conn          *GRPCConn
+	UserClient    emb.UserEmbeddingServiceClient
+	ProjectClient emb.ProjectEmbeddingServiceClient
 }
 
-func NewEmbeddingHub(queueSize, workers int, addr string) *EmbeddingHub {
+func NewEmbeddingHub(queueSize, workers int, conn *GRPCConn) *EmbeddingHub {
 	return &EmbeddingHub{
-		Jobs:       make(chan *EmbeddingJob, queueSize),
-		Quit:       make(chan bool),
-		WorkerPool: workers,
-		GRPCADDR:   addr,
+		Jobs:          make(chan *EmbeddingJob, queueSize),
+		Quit:          make(chan bool),
+		WorkerPool:    workers,
+		Conn:          conn,
+		UserClient:    emb.NewUserEmbeddingServiceClient(conn.Conn),
+		ProjectClient: emb.NewProjectEmbeddingServiceClient(conn.Conn),
 	}
 }
 
@@ -83,36 +93,48 @@ func (e *EmbeddingHub) Run() {
 	for range e.WorkerPool {
 		go e.Worker()
 	}
-	log.Println("[EmbeddingHub] The Embedding hub is up and running")
+	Logger("embedding").Info("The Embedding hub is up and running", "workers", e.WorkerPool)
 }
 
-func (e *EmbeddingHub) Worker() {
-	conn, err := grpc.NewClient(e.GRPCADDR, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		log.Printf("[EmbeddingHub] Failed to connect to ML service -> %v", err.Error())
-		return
-	}
+// CircuitState -> Returns the state of the circuit breaker on the embedding service connection
+func (e *EmbeddingHub) CircuitState() string {
+	return e.Conn.Breaker.State()
+}
 
-	defer conn.Close()
+// CheckHealth -> Checks the embedding service through the gRPC health protocol
+func (e *EmbeddingHub) CheckHealth() error {
+	return e.Conn.CheckHealth()
+}
 
-	userClient := emb.NewUserEmbeddingServiceClient(conn)
-	projectClient := emb.NewProjectEmbeddingServiceClient(conn)
+// Stats -> Returns the queue depth and worker count of the hub
+func (e *EmbeddingHub) Stats() HubStats {
+	return HubStats{Queued: len(e.Jobs), Capacity: cap(e.Jobs), Workers: e.WorkerPool}
+}
 
+func (e *EmbeddingHub) Worker() {
 	for {
 		select {
 		case job := <-e.Jobs:
-			err := e.ProcessJob(job, userClient, projectClient)
+			ctx, span := startJobSpan("embedding.job", job.QueuedBy, attribute.Int("job.type", int(job.Type)), attribute.Int("job.attempt", job.Attempts+1))
+			ctx = WithLogFields(ctx, "component", "embedding", "job_id", job.JobID, "job_type", int(job.Type))
+			err := e.ProcessJob(ctx, job)
+			endSpan(span, err)
 			if err != nil {
 				job.Attempts++
+				RecordJob("embedding", err, job.Attempts <= job.MaxAttempts)
 				if job.Attempts <= job.MaxAttempts {
 					waitTime := time.Duration(job.Attempts*3) * time.Second
-					log.Printf("[EmbeddingJob] Failed, err -> %v retrying in %v", err, waitTime)
+					slog.WarnContext(ctx, "Embedding job failed, retrying", "err", err, "attempt", job.Attempts, "retry_in", waitTime.String())
 
 					go func(j *EmbeddingJob, delay time.Duration) {
 						time.Sleep(delay)
 						e.Jobs <- j
 					}(job, waitTime)
+				} else {
+					slog.ErrorContext(ctx, "Embedding job failed, giving up", "err", err, "attempts", job.Attempts)
 				}
+			} else {
+				RecordJob("embedding", nil, false)
 			}
 		case <-e.Quit:
 			return
@@ -120,38 +142,38 @@ func (e *EmbeddingHub) Worker() {
 	}
 }
 
-func (e *EmbeddingHub) ProcessJob(job *EmbeddingJob, userClient emb.UserEmbeddingServiceClient, projectClient emb.ProjectEmbeddingServiceClient) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+func (e *EmbeddingHub) ProcessJob(ctx context.Context, job *EmbeddingJob) error {
+	ctx, cancel := e.Conn.Context(ctx)
 	defer cancel()
 
 	var err error
 
 	switch job.Type {
 	case CreateUserEmbedding:
-		_, err = userClient.CreateUserEmbedding(ctx, &emb.UserEmbeddingRequest{
+		_, err = e.UserClient.CreateUserEmbedding(ctx, &emb.UserEmbeddingRequest{
 			UserId:    job.User.ID,
 			Bio:       job.User.Bio,
 			Skills:    job.User.Skills,
 			Interests: job.User.Interests,
 		})
 	case UpdateUserEmbedding:
-		_, err = userClient.UpdateUserEmbedding(ctx, &emb.UserEmbeddingRequest{
+		_, err = e.UserClient.UpdateUserEmbedding(ctx, &emb.UserEmbeddingRequest{
 			UserId:    job.User.ID,
 			Bio:       job.User.Bio,
 			Skills:    job.User.Skills,
 			Interests: job.User.Interests,
 		})
 	case UpdateUserStatus:
-		_, err = userClient.UpdateUserStatus(ctx, &emb.UpdateStatusRequest{
+		_, err = e.UserClient.UpdateUserStatus(ctx, &emb.UpdateStatusRequest{
 			Id:     job.User.ID,
 			Status: job.User.Status,
 		})
 	case DeleteUserEmbedding:
-		_, err = userClient.DeleteUserEmbedding(ctx, &emb.DeleteEmbeddingRequest{
+		_, err = e.UserClient.DeleteUserEmbedding(ctx, &emb.DeleteEmbeddingRequest{
 			Id: job.User.ID,
 		})
 	case CreateProjectEmbedding:
-		_, err = projectClient.CreateProjectEmbedding(ctx, &emb.ProjectEmbeddingRequest{
+		_, err = e.ProjectClient.CreateProjectEmbedding(ctx, &emb.ProjectEmbeddingRequest{
 			ProjectId:   job.Project.ID,
 			Title:       job.Project.Title,
 			Description: job.Project.Description,
@@ -159,27 +181,29 @@ func (e *EmbeddingHub) ProcessJob(job *EmbeddingJob, userClient emb.UserEmbeddin
 			UserId:      job.User.ID,
 		})
 	case UpdateProjectEmbedding:
-		_, err = projectClient.UpdateProjectEmbedding(ctx, &emb.ProjectEmbeddingRequest{
+		_, err = e.ProjectClient.UpdateProjectEmbedding(ctx, &emb.ProjectEmbeddingRequest{
 			ProjectId:   job.Project.ID,
 			Title:       job.Project.Title,
 			Description: job.Project.Description,
 			Skills:      job.Project.Skills,
 		})
 	case UpdateProjectStatus:
-		_, err = projectClient.UpdateProjectStatus(ctx, &emb.UpdateStatusRequest{
+		_, err = e.ProjectClient.UpdateProjectStatus(ctx, &emb.UpdateStatusRequest{
 			Id:     job.Project.ID,
 			Status: job.Project.Status,
 		})
 	case DeleteProjectEmbedding:
-		_, err = projectClient.DeleteProjectEmbedding(ctx, &emb.DeleteEmbeddingRequest{
+		_, err = e.ProjectClient.DeleteProjectEmbedding(ctx, &emb.DeleteEmbeddingRequest{
 			Id: job.Project.ID,
 		})
 	}
 	return err
 }
 
-func (e *EmbeddingHub) QueueUserCreate(id, bio string, skills, interests []string) {
+func (e *EmbeddingHub) QueueUserCreate(ctx context.Context, id, bio string, skills, interests []string) {
 	e.Jobs <- &EmbeddingJob{
+		JobID:       uuid.NewString(),
+		QueuedBy:    trace.SpanContextFromContext(ctx),
 		Type:        CreateUserEmbedding,
 		MaxAttempts: 3,
 		User: &UserEmbedding{
@@ -191,8 +215,10 @@ func (e *EmbeddingHub) QueueUserCreate(id, bio string, skills, interests []strin
 	}
 }
 
-func (e *EmbeddingHub) QueueUserUpdate(id, bio string, skills, interest []string) {
+func (e *EmbeddingHub) QueueUserUpdate(ctx context.Context, id, bio string, skills, interest []string) {
 	e.Jobs <- &EmbeddingJob{
+		JobID:       uuid.NewString(),
+		QueuedBy:    trace.SpanContextFromContext(ctx),
 		Type:        UpdateUserEmbedding,
 		MaxAttempts: 3,
 		User: &UserEmbedding{
@@ -204,8 +230,10 @@ func (e *EmbeddingHub) QueueUserUpdate(id, bio string, skills, interest []string
 	}
 }
 
-func (e *EmbeddingHub) QueueUserUpdateStatus(id string, status bool) {
+func (e *EmbeddingHub) QueueUserUpdateStatus(ctx context.Context, id string, status bool) {
 	e.Jobs <- &EmbeddingJob{
+		JobID:       uuid.NewString(),
+		QueuedBy:    trace.SpanContextFromContext(ctx),
 		Type:        UpdateUserStatus,
 		MaxAttempts: 2,
 		User: &UserEmbedding{
@@ -215,8 +243,10 @@ func (e *EmbeddingHub) QueueUserUpdateStatus(id string, status bool) {
 	}
 }
 
-func (e *EmbeddingHub) QueueUserDelete(id string) {
+func (e *EmbeddingHub) QueueUserDelete(ctx context.Context, id string) {
 	e.Jobs <- &EmbeddingJob{
+		JobID:       uuid.NewString(),
+		QueuedBy:    trace.SpanContextFromContext(ctx),
 		Type:        DeleteUserEmbedding,
 		MaxAttempts: 3,
 		User: &UserEmbedding{
@@ -225,8 +255,10 @@ func (e *EmbeddingHub) QueueUserDelete(id string) {
 	}
 }
 
-func (e *EmbeddingHub) QueueProjectCreate(id, title, description, uid string, skills []string) {
+func (e *EmbeddingHub) QueueProjectCreate(ctx context.Context, id, title, description, uid string, skills []string) {
 	e.Jobs <- &EmbeddingJob{
+		JobID:       uuid.NewString(),
+		QueuedBy:    trace.SpanContextFromContext(ctx),
 		Type:        CreateProjectEmbedding,
 		MaxAttempts: 3,
 		Project: &ProjectEmbedding{
@@ -241,8 +273,10 @@ func (e *EmbeddingHub) QueueProjectCreate(id, title, description, uid string, sk
 	}
 }
 
-func (e *EmbeddingHub) QueueProjectUpdate(id, title, description string, skills []string) {
+func (e *EmbeddingHub) QueueProjectUpdate(ctx context.Context, id, title, description string, skills []string) {
 	e.Jobs <- &EmbeddingJob{
+		JobID:       uuid.NewString(),
+		QueuedBy:    trace.SpanContextFromContext(ctx),
 		Type:        UpdateProjectEmbedding,
 		MaxAttempts: 3,
 		Project: &ProjectEmbedding{
@@ -254,8 +288,10 @@ func (e *EmbeddingHub) QueueProjectUpdate(id, title, description string, skills
 	}
 }
 
-func (e *EmbeddingHub) QueueProjectUpdateStatus(id string, status bool) {
+func (e *EmbeddingHub) QueueProjectUpdateStatus(ctx context.Context, id string, status bool) {
 	e.Jobs <- &EmbeddingJob{
+		JobID:       uuid.NewString(),
+		QueuedBy:    trace.SpanContextFromContext(ctx),
 		Type:        UpdateProjectStatus,
 		MaxAttempts: 2,
 		Project: &ProjectEmbedding{
@@ -265,8 +301,10 @@ func (e *EmbeddingHub) QueueProjectUpdateStatus(id string, status bool) {
 	}
 }
 
-func (e *EmbeddingHub) QueueProjectDelete(id string) {
+func (e *EmbeddingHub) QueueProjectDelete(ctx context.Context, id string) {
 	e.Jobs <- &EmbeddingJob{
+		JobID:       uuid.NewString(),
+		QueuedBy:    trace.SpanContextFromContext(ctx),
 		Type:        DeleteProjectEmbedding,
 		MaxAttempts: 3,
 		Project: &ProjectEmbedding{
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type GRPCConfig struct {
+	Addr             string
+	Timeout          time.Duration
+	MaxAttempts      int
+	KeepAlive        time.Duration
+	FailureThreshold int
+	ResetTimeout     time.Duration
+}
+
+// DefaultGRPCConfig -> Default settings for a connection to one of the ML services
+func DefaultGRPCConfig(addr string) GRPCConfig {
+	return GRPCConfig{
+		Addr:             addr,
+		Timeout:          30 * time.Second,
+		MaxAttempts:      3,
+		KeepAlive:        5 * time.Minute,
+		FailureThreshold: 5,
+		ResetTimeout:     30 * time.Second,
+	}
+}
+
+// GRPCConfigFromEnv -> Overrides the default settings with the env variables of the given prefix
+// e.g. EMB_GRPC_ADDR, EMB_GRPC_TIMEOUT, EMB_GRPC_MAX_ATTEMPTS, EMB_GRPC_BREAKER_THRESHOLD, EMB_GRPC_BREAKER_RESET
+func GRPCConfigFromEnv(prefix, addr string) GRPCConfig {
+	cfg := DefaultGRPCConfig(addr)
+
+	if val := os.Getenv(prefix + "_GRPC_ADDR"); val != "" {
+		cfg.Addr = val
+	}
+	if val, err := time.ParseDuration(os.Getenv(prefix + "_GRPC_TIMEOUT")); err == nil && val > 0 {
+		cfg.Timeout = val
+	}
+	if val, err := strconv.Atoi(os.Getenv(prefix + "_GRPC_MAX_ATTEMPTS")); err == nil && val > 0 {
+		cfg.MaxAttempts = val
+	}
+	if val, err := time.ParseDuration(os.Getenv(prefix + "_GRPC_KEEPALIVE")); err == nil && val > 0 {
+		cfg.KeepAlive = val
+	}
+	if val, err := strconv.Atoi(os.Getenv(prefix + "_GRPC_BREAKER_THRESHOLD")); err == nil && val > 0 {
+		cfg.FailureThreshold = val
+	}
+	if val, err := time.ParseDuration(os.Getenv(prefix + "_GRPC_BREAKER_RESET")); err == nil && val > 0 {
+		cfg.ResetTimeout = val
+	}
+
+	return cfg
+}
+
+// GRPCConn -> A single shared connection to a downstream gRPC service guarded by a circuit breaker
+type GRPCConn struct {
+	Name    string
+	Config  GRPCConfig
+	Conn    *grpc.ClientConn
+	Breaker *CircuitBreaker
+}
+
+// NewGRPCConn -> Creates the managed connection, the underlying channel connects lazily and reconnects on its own
+func NewGRPCConn(name string, cfg GRPCConfig) (*GRPCConn, error) {
+	g := &GRPCConn{
+		Name:    name,
+		Config:  cfg,
+		Breaker: NewCircuitBreaker(cfg.FailureThreshold, cfg.ResetTimeout),
+	}
+
+	conn, err := grpc.NewClient(cfg.Addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    cfg.KeepAlive,
+			Timeout: 20 * time.Second,
+		}),
+		grpc.WithDefaultServiceConfig(retryServiceConfig(cfg.MaxAttempts)),
+		grpc.WithChainUnaryInterceptor(g.metricsInterceptor, g.breakerInterceptor),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	g.Conn = conn
+	return g, nil
+}
+
+// Context -> Returns a context carrying the configured deadline for a single call
+func (g *GRPCConn) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(parent, g.Config.Timeout)
+}
+
+// Close -> Closes the underlying connection
+func (g *GRPCConn) Close() error {
+	return g.Conn.Close()
+}
+
+// CheckHealth -> Queries the service with the standard gRPC health checking protocol
+func (g *GRPCConn) CheckHealth() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := grpc_health_v1.NewHealthClient(g.Conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if res.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("%s service is %s", g.Name, res.GetStatus())
+	}
+	return nil
+}
+
+func (g *GRPCConn) metricsInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	GRPCClientDuration.WithLabelValues(g.Name, method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (g *GRPCConn) breakerInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	// Health probes report on the service themselves and shouldn't move the breaker
+	if method == grpc_health_v1.Health_Check_FullMethodName {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	if err := g.Breaker.Allow(); err != nil {
+		return status.Error(codes.Unavailable, fmt.Sprintf("%s: %v", g.Name, err))
+	}
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if isDownstreamFailure(err) {
+		if g.Breaker.Failure() {
+			slog.WarnContext(ctx, "Circuit opened after repeated failures", "component", "grpc", "service", g.Name, "err", err)
+		}
+	} else {
+		g.Breaker.Success()
+	}
+
+	return err
+}
+
+// isDownstreamFailure -> Only errors that point to the service being unhealthy should trip the breaker
+func isDownstreamFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+func retryServiceConfig(maxAttempts int) string {
+	if maxAttempts < 2 {
+		return `{"methodConfig":[{"name":[{}]}]}`
+	}
+	// gRPC caps retries at 5 attempts
+	maxAttempts = min(maxAttempts, 5)
+	return fmt.Sprintf(`{
+		"methodConfig": [{
+			"name": [{}],
+			"retryPolicy": {
+				"maxAttempts": %d,
+				"initialBackoff": "0.2s",
+				"maxBackoff": "2s",
+				"backoffMultiplier": 2,
+				"retryableStatusCodes": ["UNAVAILABLE", "RESOURCE_EXHAUSTED"]
+			}
+		}]
+	}`, maxAttempts)
+}
+
+// CircuitBreaker -> Fails calls fast once a downstream keeps failing, and lets a single probe through after the reset timeout
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	state            string
+	failures         int
+	openedAt         time.Time
+	probing          bool
+	FailureThreshold int
+	ResetTimeout     time.Duration
+}
+
+func NewCircuitBreaker(threshold int, reset time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		state:            CircuitClosed,
+		FailureThreshold: threshold,
+		ResetTimeout:     reset,
+	}
+}
+
+// Allow -> Checks if a call can go through
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.ResetTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return nil
+	case CircuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Success -> Records a successful call and closes the circuit
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = CircuitClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure -> Records a failed call and reports whether it opened the circuit
+func (b *CircuitBreaker) Failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == CircuitHalfOpen || (b.state == CircuitClosed && b.failures >= b.FailureThreshold) {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+		return true
+	}
+	return false
+}
+
+// State -> Returns the current state of the circuit
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.ResetTimeout {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
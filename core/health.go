@@ -0,0 +1,8 @@
+package core
+
+// HubStats -> Queue depth and worker count of a job hub
+type HubStats struct {
+	Queued   int `json:"queued"`
+	Capacity int `json:"capacity"`
+	Workers  int `json:"workers"`
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	// Registers the gif decoder, png and jpeg are registered by the encoders above
+	_ "image/gif"
+)
+
+const (
+	// ThumbnailSize -> Largest side of the image thumbnails
+	ThumbnailSize = 320
+
+	// MaxImagePixels -> Images above this many pixels aren't decoded (decompression bombs)
+	MaxImagePixels = 40_000_000
+)
+
+// Thumbnail -> Decodes an image and scales it down to fit in a ThumbnailSize box, returning the encoded thumbnail
+// (png for the formats that may be transparent, jpeg otherwise) along with the size of the original image
+func Thumbnail(r io.Reader) (thumb *bytes.Buffer, contentType string, width, height int, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", 0, 0, err
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", 0, 0, err
+	}
+	if cfg.Width*cfg.Height > MaxImagePixels {
+		return nil, "", 0, 0, errors.New("image too large")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", 0, 0, err
+	}
+
+	thumb = &bytes.Buffer{}
+	scaled := scaleDown(src, ThumbnailSize)
+	if format == "jpeg" {
+		contentType, err = "image/jpeg", jpeg.Encode(thumb, scaled, &jpeg.Options{Quality: 80})
+	} else {
+		contentType, err = "image/png", png.Encode(thumb, scaled)
+	}
+	return thumb, contentType, cfg.Width, cfg.Height, err
+}
+
+// scaleDown -> Resizes an image to fit in a size x size box by averaging the source pixels under each pixel
+func scaleDown(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return src
+	}
+
+	dw, dh := size, max(h*size/w, 1)
+	if h > w {
+		dw, dh = max(w*size/h, 1), size
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := b.Min.Y+y*h/dh, b.Min.Y+(y+1)*h/dh
+		for x := 0; x < dw; x++ {
+			x0, x1 := b.Min.X+x*w/dw, b.Min.X+(x+1)*w/dw
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			dst.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(bl / n), uint16(a / n)})
+		}
+	}
+	return dst
+}
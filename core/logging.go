@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogLevel -> Minimum level of the app logger, read from LOG_LEVEL and changeable at runtime through LogLevelHandler
+var LogLevel = new(slog.LevelVar)
+
+// SetupLogging -> Installs the JSON logger as the default for both slog and the std log package
+func SetupLogging(w io.Writer) {
+	if level := os.Getenv("LOG_LEVEL"); level != "" {
+		if err := LogLevel.UnmarshalText([]byte(level)); err != nil {
+			LogLevel.Set(slog.LevelInfo)
+		}
+	}
+
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		AddSource:   true,
+		Level:       LogLevel,
+		ReplaceAttr: Redact,
+	})
+	slog.SetDefault(slog.New(&contextHandler{handler}))
+	log.SetFlags(0)
+}
+
+// Logger -> Returns the default logger tagged with the component emitting the records
+func Logger(component string) *slog.Logger {
+	return slog.Default().With("component", component)
+}
+
+type logFieldsKey struct{}
+
+// WithLogFields -> Returns a copy of ctx whose log records carry the given key/value pairs
+func WithLogFields(ctx context.Context, args ...any) context.Context {
+	fields, _ := ctx.Value(logFieldsKey{}).([]slog.Attr)
+
+	var r slog.Record
+	r.Add(args...)
+	merged := make([]slog.Attr, 0, len(fields)+r.NumAttrs())
+	merged = append(merged, fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		merged = append(merged, a)
+		return true
+	})
+
+	return context.WithValue(ctx, logFieldsKey{}, merged)
+}
+
+// contextHandler -> Adds the fields stored on the context and the active trace to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if fields, ok := ctx.Value(logFieldsKey{}).([]slog.Attr); ok {
+			r.AddAttrs(fields...)
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{h.Handler.WithGroup(name)}
+}
+
+const redacted = "[REDACTED]"
+
+var (
+	emailPattern  = regexp.MustCompile(`([A-Za-z0-9._%+\-])[A-Za-z0-9._%+\-]*@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+	secretPattern = regexp.MustCompile(`\b(AUTH_[A-Za-z0-9]+|sk_(live|test)_[A-Za-z0-9]+|gh[opsu]_[A-Za-z0-9]+|eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+)`)
+	bearerPattern = regexp.MustCompile(`(?i)\bBearer\s+[A-Za-z0-9\-._~+/]+=*`)
+	fieldPattern  = regexp.MustCompile(`(?i)"(last4|bin|authorization_code|signature|token|access_token|password|otp)"\s*:\s*"[^"]*"`)
+)
+
+// sensitiveKeys -> Attributes whose values are never logged
+var sensitiveKeys = map[string]bool{
+	"password":           true,
+	"otp":                true,
+	"token":              true,
+	"authorization":      true,
+	"authorization_code": true,
+	"auth_code":          true,
+	"last4":              true,
+	"bin":                true,
+	"card":               true,
+	"signature":          true,
+	"secret":             true,
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	return sensitiveKeys[key] || strings.HasSuffix(key, "_token") || strings.HasSuffix(key, "_secret") || strings.HasSuffix(key, "password")
+}
+
+// Redact -> slog ReplaceAttr hook masking emails, tokens, card details and authorization codes
+func Redact(_ []string, a slog.Attr) slog.Attr {
+	if isSensitiveKey(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, RedactString(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, RedactString(err.Error()))
+		}
+	}
+	return a
+}
+
+// RedactString -> Masks the sensitive values found in a free form string
+func RedactString(s string) string {
+	s = fieldPattern.ReplaceAllString(s, `"$1":"`+redacted+`"`)
+	s = bearerPattern.ReplaceAllString(s, "Bearer "+redacted)
+	s = secretPattern.ReplaceAllString(s, redacted)
+	return emailPattern.ReplaceAllString(s, "$1***@$2")
+}
+
+// LogLevelHandler -> Reads (GET) or changes (PUT with {"level": "debug"}) the log level of the running app
+func LogLevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var payload struct {
+				Level string `json:"level"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || LogLevel.UnmarshalText([]byte(payload.Level)) != nil {
+				http.Error(w, "Invalid log level, use one of debug, info, warn or error.", http.StatusBadRequest)
+				return
+			}
+			slog.Info("Log level changed", "component", "logging", "level", LogLevel.Level().String())
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "Method not allowed.", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"level": LogLevel.Level().String()})
+	})
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"findme/model"
+	"findme/schema"
+)
+
+// MaxMentions -> Members notified for a single message, the mentions past it are left as plain text
+const MaxMentions = 20
+
+// mentionPreviewLength -> Characters of the message sent along a mention notification
+const mentionPreviewLength = 100
+
+// mentionPattern -> An @ not preceded by a word character (so emails aren't matched) followed by a username
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.\-]{1,50})`)
+
+// ParseMentions -> Returns the usernames mentioned in a message, in order and without duplicates
+func ParseMentions(text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		// Punctuation ending a sentence isn't part of the username
+		name := strings.TrimRight(match[1], ".-")
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, name)
+		if len(names) == MaxMentions {
+			break
+		}
+	}
+	return names
+}
+
+// Mentions -> Records the members of a group chat mentioned in a new message and notifies them,
+// by socket and by email when they are offline, unless they muted the chat or turned its notifications off.
+// Failures are logged as the message itself was already sent.
+func (h *ChatHub) Mentions(ctx context.Context, msg *model.UserMessage) []schema.ViewMention {
+	names := ParseMentions(msg.Message)
+	if len(names) == 0 {
+		return nil
+	}
+
+	var chat model.Chat
+	if err := h.DB.WithContext(ctx).FetchChat(msg.ChatID, &chat); err != nil || !chat.Group {
+		return nil
+	}
+
+	var members []model.ChatUser
+	if err := h.DB.WithContext(ctx).FetchMentionedMembers(msg.ChatID, names, &members); err != nil {
+		return nil
+	}
+
+	mentions := make([]model.MessageMention, 0, len(members))
+	views := make([]schema.ViewMention, 0, len(members))
+	for _, member := range members {
+		if member.UserID == msg.FromID {
+			continue
+		}
+		mentions = append(mentions, model.MessageMention{MsgID: msg.ID, UserID: member.UserID, ChatID: msg.ChatID})
+		views = append(views, schema.ViewMention{UserID: member.UserID, Username: member.User.UserName})
+	}
+	if len(mentions) == 0 {
+		return nil
+	}
+	if err := h.DB.WithContext(ctx).AddMentions(mentions); err != nil {
+		return nil
+	}
+
+	var sender model.ChatUser
+	if err := h.DB.WithContext(ctx).FetchChatMember(msg.ChatID, msg.FromID, &sender); err != nil {
+		return views
+	}
+
+	now := time.Now()
+	var notify []model.ChatUser
+	uids := make([]string, 0, len(members))
+	for _, member := range members {
+		if member.UserID != msg.FromID && member.ShouldNotify(true, now) {
+			notify = append(notify, member)
+			uids = append(uids, member.UserID)
+		}
+	}
+	if len(notify) == 0 {
+		return views
+	}
+
+	text := []rune(msg.Message)
+	if len(text) > mentionPreviewLength {
+		text = append(text[:mentionPreviewLength], '…')
+	}
+	payload := schema.WSMention{MsgID: msg.ID, From: sender.User.UserName, Preview: string(text)}
+
+	presence, err := h.Presence(uids)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to fetch the presence of the mentioned members", "component", "chat", "err", err)
+	}
+	for _, member := range notify {
+		h.PublishUser(member.UserID, NewEvent(schema.EventMentioned, msg.ChatID, "", payload))
+
+		if h.Webhooks != nil {
+			h.Webhooks.Dispatch(ctx, model.WebhookMessageMention, []string{member.UserID}, "", schema.WebhookMention{
+				ChatID:    msg.ChatID,
+				ChatName:  chat.Name,
+				MsgID:     msg.ID,
+				From:      payload.From,
+				Mentioned: member.User.UserName,
+				Preview:   payload.Preview,
+			})
+		}
+
+		if h.Email != nil && err == nil && presence[member.UserID] == PresenceOffline && member.User.Email != "" {
+			h.Email.QueueMentionEmail(ctx, sender.User.UserName, member.User.UserName, chat.Name, payload.Preview, member.User.Email)
+		}
+	}
+	return views
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "findme_http_requests_total",
+		Help: "HTTP requests by route, method and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "findme_http_request_duration_seconds",
+		Help:    "HTTP request latency by route, method and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	WSConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "findme_ws_connections",
+		Help: "Open websocket connections on the chat hub.",
+	})
+
+	WSRooms = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "findme_ws_rooms",
+		Help: "Chat rooms with at least one open websocket connection.",
+	})
+
+	ChatBusMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "findme_chat_bus_messages_total",
+		Help: "Chat hub actions relayed between the replicas by direction (published, received, dropped).",
+	}, []string{"direction"})
+
+	HubJobs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "findme_hub_jobs_total",
+		Help: "Jobs processed by the background hubs by outcome (success, retry, failed).",
+	}, []string{"hub", "outcome"})
+
+	GRPCClientDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "findme_grpc_client_duration_seconds",
+		Help:    "Latency of gRPC calls to the ML services.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"service", "method", "code"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "findme_db_query_duration_seconds",
+		Help:    "Duration of GORM queries by operation and table.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	PaystackWebhookEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "findme_paystack_webhook_events_total",
+		Help: "Paystack webhook events by type and outcome.",
+	}, []string{"event", "outcome"})
+
+	CronJobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "findme_cron_job_duration_seconds",
+		Help:    "Duration of the cron jobs.",
+		Buckets: []float64{.1, .5, 1, 5, 10, 30, 60, 300},
+	}, []string{"job"})
+)
+
+const (
+	JobSuccess = "success"
+	JobRetry   = "retry"
+	JobFailed  = "failed"
+)
+
+// RecordJob -> Counts a processed job of a hub, failures are either retried or dropped
+func RecordJob(hub string, err error, retrying bool) {
+	switch {
+	case err == nil:
+		HubJobs.WithLabelValues(hub, JobSuccess).Inc()
+	case retrying:
+		HubJobs.WithLabelValues(hub, JobRetry).Inc()
+	default:
+		HubJobs.WithLabelValues(hub, JobFailed).Inc()
+	}
+}
+
+var hubStats = &hubCollector{
+	stats:    make(map[string]func() HubStats),
+	queued:   prometheus.NewDesc("findme_hub_queue_depth", "Jobs waiting in the hub queue.", []string{"hub"}, nil),
+	capacity: prometheus.NewDesc("findme_hub_queue_capacity", "Size of the hub queue.", []string{"hub"}, nil),
+	workers:  prometheus.NewDesc("findme_hub_workers", "Workers consuming the hub queue.", []string{"hub"}, nil),
+}
+
+func init() {
+	prometheus.MustRegister(hubStats)
+}
+
+// RegisterHubMetrics -> Exposes the queue stats of a hub, read at scrape time
+func RegisterHubMetrics(hub string, stats func() HubStats) {
+	hubStats.mu.Lock()
+	defer hubStats.mu.Unlock()
+
+	hubStats.stats[hub] = stats
+}
+
+type hubCollector struct {
+	mu       sync.Mutex
+	stats    map[string]func() HubStats
+	queued   *prometheus.Desc
+	capacity *prometheus.Desc
+	workers  *prometheus.Desc
+}
+
+func (c *hubCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.queued
+	ch <- c.capacity
+	ch <- c.workers
+}
+
+func (c *hubCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for hub, fn := range c.stats {
+		stats := fn()
+		ch <- prometheus.MustNewConstMetric(c.queued, prometheus.GaugeValue, float64(stats.Queued), hub)
+		ch <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(stats.Capacity), hub)
+		ch <- prometheus.MustNewConstMetric(c.workers, prometheus.GaugeValue, float64(stats.Workers), hub)
+	}
+}
+
+// GormMetrics -> GORM plugin recording the duration of every query
+type GormMetrics struct{}
+
+const gormStartKey = "metrics:start"
+
+func (GormMetrics) Name() string {
+	return "findme:metrics"
+}
+
+func (GormMetrics) Initialize(db *gorm.DB) error {
+	before := func(tx *gorm.DB) {
+		tx.InstanceSet(gormStartKey, time.Now())
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			start, ok := tx.InstanceGet(gormStartKey)
+			if !ok {
+				return
+			}
+			DBQueryDuration.WithLabelValues(operation, tx.Statement.Table).Observe(time.Since(start.(time.Time)).Seconds())
+		}
+	}
+
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("metrics:before_create", before); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("metrics:after_create", after("create")); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("metrics:before_query", before); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("metrics:after_query", after("query")); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("metrics:before_update", before); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("metrics:after_update", after("update")); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("metrics:before_delete", before); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("metrics:after_delete", after("delete")); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("metrics:before_row", before); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("metrics:after_row", after("row")); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("metrics:before_raw", before); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("metrics:after_raw", after("raw"))
+}
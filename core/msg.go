@@ -1,103 +1,811 @@
 package core
 
 import (
-	"log"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"findme/model"
 	"findme/schema"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
+const (
+	// MaxSocketMessageSize -> Largest frame read from a user socket, bigger frames close the connection
+	MaxSocketMessageSize = 8 << 10
+
+	// maxRateViolations -> Rate limited frames in a row after which the connection is closed
+	maxRateViolations = 10
+
+	// Sockets are pinged every pingPeriod and dropped when no pong (or frame) arrives within pongWait
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+	writeWait  = 10 * time.Second
+)
+
+// Client -> A user socket, subscribed to the chats whose events it receives. Event streams (see ServeEvents) are
+// clients without a Conn
 type Client struct {
 	Conn     *websocket.Conn
 	UserID   string
-	ChatID   string
-	SendChan chan *schema.ViewMessage
+	SendChan chan *schema.WSEnvelope
+
+	ctx     context.Context
+	chats   map[string]bool // only touched by the hub goroutine
+	limiter *RateLimiter
+	lastAck string
+
+	// Unix nano time of the last frame and whether the client said the user is away, read by the hub goroutine
+	lastActive atomic.Int64
+	away       atomic.Bool
 }
 
+// BroadcastMessage -> An event for the subscribers of a chat, the sockets of a user when UserID is set
+// or a single socket when To is set
 type BroadcastMessage struct {
 	ChatID string
-	Data   *schema.ViewMessage
+	UserID string
+	Data   *schema.WSEnvelope
+	To     *Client
+	Except *Client
+
+	remote bool
+}
+
+// Subscription -> Subscribes (or unsubscribes) a socket to a chat, every socket of UserID when Client is nil.
+// Ack is delivered to the socket once the subscription is applied.
+type Subscription struct {
+	Client *Client
+	UserID string
+	ChatID string
+	Join   bool
+	Ack    *schema.WSEnvelope
+
+	remote bool
+}
+
+// KickMessage -> Unsubscribes the sockets of a user from a chat, every socket of the chat when UserID is empty
+type KickMessage struct {
+	ChatID string
+	UserID string
+	Reason string
+
+	remote bool
+}
+
+// busOp -> Work for the goroutine talking to the bus, so the hub never waits on redis
+type busOp struct {
+	msg     *BusMessage
+	online  map[string]string
+	offline []string
 }
 
 type ChatHub struct {
+	// ID -> Identifies the replica on the bus, so it ignores the actions it published itself
+	ID         string
+	DB         DB
+	Bus        ChatBus
 	Room       map[string]map[*Client]bool
+	Users      map[string]map[*Client]bool
 	Register   chan *Client
 	UnRegister chan *Client
+	Subscribe  chan *Subscription
 	Broadcast  chan *BroadcastMessage
+	Kick       chan *KickMessage
+	Activity   chan *Client
+
+	// Frames a single connection may send per second, with bursts up to MsgBurst
+	MsgRate  float64
+	MsgBurst int
+
+	// Email -> Notifies the members mentioned while offline, left nil to only notify the open sockets
+	Email Email
+	// Webhooks -> Sends the mentions to the webhooks of the mentioned members (skipped when nil)
+	Webhooks Webhooks
+
+	outbox chan busOp
+
+	// Presence of the users connected to this replica, read by Presence when there is no bus
+	mu       sync.RWMutex
+	presence map[string]string
+
+	// seq -> Last seq given to an event, only touched by the hub goroutine
+	seq int64
+	// Events kept for the resuming streams when there is no bus
+	replayMu sync.Mutex
+	replay   []*BusMessage
 }
 
-func NewClient(conn *websocket.Conn, uid, cid string, data chan *schema.ViewMessage) *Client {
-	return &Client{
+// NewClient -> Creates the client of a user socket subscribed to the given chats,
+// ctx carries the log fields and trace of the request that opened it
+func NewClient(ctx context.Context, conn *websocket.Conn, uid string, chats []string) *Client {
+	c := &Client{
 		Conn:     conn,
 		UserID:   uid,
-		ChatID:   cid,
-		SendChan: data,
+		SendChan: make(chan *schema.WSEnvelope, 16),
+		ctx:      ctx,
+		chats:    make(map[string]bool, len(chats)),
+	}
+	for _, chatID := range chats {
+		c.chats[chatID] = true
 	}
+	c.lastActive.Store(time.Now().UnixNano())
+	return c
 }
 
-func NewChatHub(buffersize int) *ChatHub {
+// NewChatHub -> Creates the chat hub, bus relays its actions to the other replicas and may be nil on a single one
+func NewChatHub(db DB, bus ChatBus, buffersize int) *ChatHub {
 	return &ChatHub{
+		ID:         uuid.NewString(),
+		DB:         db,
+		Bus:        bus,
 		Room:       make(map[string]map[*Client]bool),
+		Users:      make(map[string]map[*Client]bool),
 		Register:   make(chan *Client),
 		UnRegister: make(chan *Client),
+		Subscribe:  make(chan *Subscription, buffersize),
 		Broadcast:  make(chan *BroadcastMessage, buffersize),
+		Kick:       make(chan *KickMessage, buffersize),
+		MsgRate:    5,
+		MsgBurst:   10,
+		Activity:   make(chan *Client, buffersize),
+		outbox:     make(chan busOp, buffersize),
+		presence:   make(map[string]string),
+	}
+}
+
+// MaxMessageAttachments -> Number of files that can be sent along a single message
+const MaxMessageAttachments = 10
+
+// AttachmentViews -> Views of the files sent along a message, without the download urls as those are signed per user
+func AttachmentViews(attachments []model.Attachment) []schema.ViewAttachment {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	views := make([]schema.ViewAttachment, len(attachments))
+	for i, a := range attachments {
+		views[i] = schema.ViewAttachment{
+			ID:          a.ID,
+			Name:        a.Name,
+			ContentType: a.ContentType,
+			Size:        a.Size,
+			Width:       a.Width,
+			Height:      a.Height,
+		}
+	}
+	return views
+}
+
+// NewEvent -> Builds a server frame, id is the correlation id of the action behind it (a new one when empty)
+func NewEvent(eventType, chatID, id string, payload any) *schema.WSEnvelope {
+	if id == "" {
+		id = uuid.NewString()
+	}
+	env := &schema.WSEnvelope{V: schema.WSProtocolVersion, Type: eventType, ID: id, ChatID: chatID}
+	if payload != nil {
+		env.Payload, _ = json.Marshal(payload)
+	}
+	return env
+}
+
+// errorFrame -> Builds the error frame answering the client frame with the given id
+func errorFrame(ack string, code int, msg string) *schema.WSEnvelope {
+	env := NewEvent(schema.EventError, "", "", schema.WSError{Code: code, Message: msg})
+	env.Ack = ack
+	return env
+}
+
+// Stats -> Returns the depth of the broadcast queue, the hub runs on a single goroutine
+func (h *ChatHub) Stats() HubStats {
+	return HubStats{Queued: len(h.Broadcast), Capacity: cap(h.Broadcast), Workers: 1}
+}
+
+// Publish -> Sends an event to every socket subscribed to its chat
+func (h *ChatHub) Publish(env *schema.WSEnvelope) {
+	h.Broadcast <- &BroadcastMessage{ChatID: env.ChatID, Data: env}
+}
+
+// PublishUser -> Sends a personal event to every socket of a user
+func (h *ChatHub) PublishUser(userID string, env *schema.WSEnvelope) {
+	h.Broadcast <- &BroadcastMessage{UserID: userID, Data: env}
+}
+
+// SubscribeUser -> Subscribes the open sockets of a user to a chat they just joined
+func (h *ChatHub) SubscribeUser(chatID, userID string) {
+	h.Subscribe <- &Subscription{UserID: userID, ChatID: chatID, Join: true}
+}
+
+// reply -> Sends a frame to a single socket
+func (h *ChatHub) reply(c *Client, env *schema.WSEnvelope) {
+	h.Broadcast <- &BroadcastMessage{Data: env, To: c}
+}
+
+// Presence -> Returns the presence state of each user across the replicas
+func (h *ChatHub) Presence(users []string) (map[string]string, error) {
+	if h.Bus != nil {
+		return h.Bus.Presence(users)
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	presence := make(map[string]string, len(users))
+	for _, uid := range users {
+		presence[uid] = PresenceOffline
+		if status, ok := h.presence[uid]; ok {
+			presence[uid] = status
+		}
 	}
+	return presence, nil
 }
 
+// status -> Presence state of a single socket
+func (c *Client) status(now time.Time) string {
+	if c.away.Load() || now.Sub(time.Unix(0, c.lastActive.Load())) > AwayAfter {
+		return PresenceAway
+	}
+	return PresenceOnline
+}
+
+// touch -> Records activity on the socket, reports whether it was idle until now
+func (c *Client) touch() bool {
+	now := time.Now()
+	idle := c.status(now) == PresenceAway && !c.away.Load()
+	c.lastActive.Store(now.UnixNano())
+	return idle
+}
+
+// KickUser -> Unsubscribes the sockets of a user from a chat they no longer belong to
+func (h *ChatHub) KickUser(chatID, userID, reason string) {
+	h.Kick <- &KickMessage{ChatID: chatID, UserID: userID, Reason: reason}
+}
+
+// CloseRoom -> Unsubscribes every socket from a chat
+func (h *ChatHub) CloseRoom(chatID, reason string) {
+	h.Kick <- &KickMessage{ChatID: chatID, Reason: reason}
+}
+
+// ReadPump -> Reads the client frames, the sender, ids and timestamps of what they cause are always assigned by the server
 func (c *Client) ReadPump(hub *ChatHub) {
 	defer func() {
 		hub.UnRegister <- c
 		_ = c.Conn.Close()
+
+		if err := hub.DB.WithContext(c.ctx).UpdateLastSeen(c.UserID, time.Now()); err != nil {
+			slog.WarnContext(c.ctx, "Failed to record the last seen time", "component", "chat", "err", err)
+		}
 	}()
 
-	var msg schema.ViewMessage
+	c.Conn.SetReadLimit(MaxSocketMessageSize)
+	_ = c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.Conn.SetPongHandler(func(string) error {
+		return c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	c.limiter = NewRateLimiter(hub.MsgRate, hub.MsgBurst)
+	violations := 0
+
 	for {
-		if err := c.Conn.ReadJSON(&msg); err != nil {
-			break
+		_, data, err := c.Conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				slog.WarnContext(c.ctx, "Websocket closed unexpectedly", "component", "chat", "err", err)
+			}
+			return
+		}
+
+		_ = c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+		if c.touch() {
+			hub.Activity <- c
+		}
+
+		var frame schema.WSEnvelope
+		if err := json.Unmarshal(data, &frame); err != nil {
+			hub.reply(c, errorFrame("", http.StatusBadRequest, "Invalid frame."))
+			continue
+		}
+
+		// Acks only move the delivery cursor of the connection and don't count against the rate limit
+		if frame.Type == schema.EventAck {
+			c.lastAck = frame.Ack
+			continue
+		}
+
+		if !c.limiter.Allow() {
+			violations++
+			if violations >= maxRateViolations {
+				slog.WarnContext(c.ctx, "Websocket rate limit exceeded repeatedly, closing the connection", "component", "chat")
+				_ = c.Conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "Rate limit exceeded."),
+					time.Now().Add(time.Second))
+				return
+			}
+			hub.reply(c, errorFrame(frame.ID, http.StatusTooManyRequests, "Rate limit exceeded."))
+			continue
+		}
+		violations = 0
+
+		c.handleFrame(hub, &frame)
+	}
+}
+
+// handleFrame -> Handles a single client frame, answering it with an ack or an error frame
+func (c *Client) handleFrame(hub *ChatHub, frame *schema.WSEnvelope) {
+	// Frames without a version are read as the current one
+	if frame.V != 0 && frame.V != schema.WSProtocolVersion {
+		hub.reply(c, errorFrame(frame.ID, http.StatusUpgradeRequired, "Unsupported protocol version."))
+		return
+	}
+	if len(frame.ID) > 64 {
+		hub.reply(c, errorFrame("", http.StatusBadRequest, "Invalid frame id."))
+		return
+	}
+
+	// The only frame that isn't about a chat
+	if frame.Type == schema.EventPresenceSet {
+		var payload schema.WSPresence
+		if err := json.Unmarshal(frame.Payload, &payload); err != nil || (payload.Status != PresenceOnline && payload.Status != PresenceAway) {
+			hub.reply(c, errorFrame(frame.ID, http.StatusUnprocessableEntity, "Presence can only be online or away."))
+			return
+		}
+
+		c.away.Store(payload.Status == PresenceAway)
+		hub.Activity <- c
+
+		ack := NewEvent(schema.EventAck, "", "", nil)
+		ack.Ack = frame.ID
+		hub.reply(c, ack)
+		return
+	}
+
+	// Every other frame acts on a chat, membership is checked on each of them as it may have changed since subscribing
+	chatID := frame.ChatID
+	if !model.IsValidUUID(chatID) {
+		hub.reply(c, errorFrame(frame.ID, http.StatusBadRequest, "Invalid chat id."))
+		return
+	}
+
+	correlation := uuid.NewString()
+	ctx := WithLogFields(c.ctx, "correlation_id", correlation, "frame_type", frame.Type, "chat_id", chatID)
+	ack := NewEvent(schema.EventAck, chatID, correlation, nil)
+	ack.Ack = frame.ID
+
+	if frame.Type != schema.EventUnsubscribe {
+		if err := hub.DB.WithContext(ctx).CheckChatMember(chatID, c.UserID); err != nil {
+			cm := err.(*CustomMessage)
+			hub.reply(c, errorFrame(frame.ID, cm.Code, cm.Message))
+			return
+		}
+	}
+
+	switch frame.Type {
+	case schema.EventSubscribe, schema.EventUnsubscribe:
+		hub.Subscribe <- &Subscription{Client: c, ChatID: chatID, Join: frame.Type == schema.EventSubscribe, Ack: ack}
+
+	case schema.EventMessageNew:
+		var payload schema.WSSendMessage
+		if err := json.Unmarshal(frame.Payload, &payload); err != nil || (strings.TrimSpace(payload.Message) == "" && len(payload.Attachments) == 0) {
+			hub.reply(c, errorFrame(frame.ID, http.StatusUnprocessableEntity, "Message can't be empty."))
+			return
+		}
+		if len(payload.Attachments) > MaxMessageAttachments {
+			hub.reply(c, errorFrame(frame.ID, http.StatusUnprocessableEntity, "Too many attachments."))
+			return
 		}
 
+		msg := model.UserMessage{
+			ChatID:  chatID,
+			FromID:  c.UserID,
+			Message: strings.TrimSpace(payload.Message),
+		}
+		if payload.ReplyToID != "" {
+			var quoted model.UserMessage
+			if !model.IsValidUUID(payload.ReplyToID) || hub.DB.WithContext(ctx).FetchMsg(&quoted, payload.ReplyToID) != nil || quoted.ChatID != chatID {
+				hub.reply(c, errorFrame(frame.ID, http.StatusNotFound, "Replied message not found."))
+				return
+			}
+			msg.ReplyToID = &quoted.ID
+		}
+
+		var err error
+		var attachments []model.Attachment
+		if len(payload.Attachments) > 0 {
+			if err = hub.DB.WithContext(ctx).AddMessageAttachments(&msg, payload.Attachments); err == nil {
+				err = hub.DB.WithContext(ctx).FetchAttachments([]string{msg.ID}, &attachments)
+			}
+		} else {
+			err = hub.DB.WithContext(ctx).AddMessage(&msg)
+		}
+		if err != nil {
+			cm := err.(*CustomMessage)
+			hub.reply(c, errorFrame(frame.ID, cm.Code, cm.Message))
+			return
+		}
+
+		view := schema.ViewMessage{
+			ID:          msg.ID,
+			Message:     msg.Message,
+			UserID:      msg.FromID,
+			Sent:        msg.CreatedAt,
+			Edited:      msg.UpdatedAt,
+			ReplyToID:   msg.ReplyToID,
+			Attachments: AttachmentViews(attachments),
+			Mentions:    hub.Mentions(ctx, &msg),
+		}
+		ack.Payload, _ = json.Marshal(view)
+		hub.reply(c, ack)
+		hub.Publish(NewEvent(schema.EventMessageNew, chatID, correlation, view))
+
+	case schema.EventTypingStart, schema.EventTypingStop:
+		hub.reply(c, ack)
 		hub.Broadcast <- &BroadcastMessage{
-			Data:   &msg,
-			ChatID: c.ChatID,
+			ChatID: chatID,
+			Data:   NewEvent(frame.Type, chatID, correlation, schema.WSTyping{UserID: c.UserID}),
+			Except: c,
+		}
+
+	case schema.EventReactionAdded, schema.EventReactionRemoved:
+		var payload schema.WSReaction
+		if err := json.Unmarshal(frame.Payload, &payload); err != nil || !model.IsValidUUID(payload.MsgID) || !model.IsValidEmoji(payload.Emoji) {
+			hub.reply(c, errorFrame(frame.ID, http.StatusUnprocessableEntity, "Invalid reaction."))
+			return
+		}
+
+		var msg model.UserMessage
+		if err := hub.DB.WithContext(ctx).FetchMsg(&msg, payload.MsgID); err != nil || msg.ChatID != chatID || msg.IsRemoved() {
+			hub.reply(c, errorFrame(frame.ID, http.StatusNotFound, "Message not found."))
+			return
+		}
+
+		var err error
+		if frame.Type == schema.EventReactionAdded {
+			err = hub.DB.WithContext(ctx).AddReaction(&model.MessageReaction{MsgID: msg.ID, UserID: c.UserID, Emoji: payload.Emoji})
+		} else {
+			err = hub.DB.WithContext(ctx).RemoveReaction(msg.ID, c.UserID, payload.Emoji)
+		}
+		if err != nil {
+			cm := err.(*CustomMessage)
+			hub.reply(c, errorFrame(frame.ID, cm.Code, cm.Message))
+			return
+		}
+
+		hub.reply(c, ack)
+		hub.Publish(NewEvent(frame.Type, chatID, correlation, schema.WSReaction{MsgID: msg.ID, UserID: c.UserID, Emoji: payload.Emoji}))
+
+	case schema.EventMessageRead:
+		var payload schema.WSReadMessage
+		if err := json.Unmarshal(frame.Payload, &payload); err != nil || !model.IsValidUUID(payload.MsgID) {
+			hub.reply(c, errorFrame(frame.ID, http.StatusUnprocessableEntity, "Invalid message id."))
+			return
+		}
+
+		var msg model.UserMessage
+		if err := hub.DB.WithContext(ctx).FetchMsg(&msg, payload.MsgID); err != nil || msg.ChatID != chatID {
+			hub.reply(c, errorFrame(frame.ID, http.StatusNotFound, "Message not found."))
+			return
 		}
+
+		if err := hub.DB.WithContext(ctx).MarkChatRead(chatID, c.UserID, msg.ID, msg.CreatedAt); err != nil {
+			cm := err.(*CustomMessage)
+			hub.reply(c, errorFrame(frame.ID, cm.Code, cm.Message))
+			return
+		}
+
+		hub.reply(c, ack)
+		hub.Publish(NewEvent(schema.EventMessageRead, chatID, correlation, schema.WSReadReceipt{
+			UserID: c.UserID,
+			MsgID:  msg.ID,
+			ReadAt: time.Now(),
+		}))
+
+	default:
+		hub.reply(c, errorFrame(frame.ID, http.StatusBadRequest, "Unknown frame type."))
 	}
 }
 
 func (c *Client) WritePump() {
-	defer c.Conn.Close()
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = c.Conn.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-c.SendChan:
+			if !ok {
+				return
+			}
+			_ = c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.Conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := c.Conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// join -> Subscribes a client to a chat, must run on the hub goroutine
+func (h *ChatHub) join(c *Client, chatID string) {
+	if h.Room[chatID] == nil {
+		h.Room[chatID] = make(map[*Client]bool)
+	}
+	h.Room[chatID][c] = true
+	c.chats[chatID] = true
+}
+
+// leave -> Unsubscribes a client from a chat, must run on the hub goroutine
+func (h *ChatHub) leave(c *Client, chatID string) {
+	delete(c.chats, chatID)
+	if room := h.Room[chatID]; room != nil {
+		delete(room, c)
+		if len(room) == 0 {
+			delete(h.Room, chatID)
+		}
+	}
+}
+
+// remove -> Drops a client from the hub and stops its write pump, must run on the hub goroutine
+func (h *ChatHub) remove(c *Client) {
+	sockets := h.Users[c.UserID]
+	if !sockets[c] {
+		return
+	}
+
+	for chatID := range c.chats {
+		h.leave(c, chatID)
+	}
+	delete(sockets, c)
+	if len(sockets) == 0 {
+		delete(h.Users, c.UserID)
+	}
+	h.refresh(c.UserID)
+	close(c.SendChan)
+	WSConnections.Dec()
+}
+
+// deliver -> Queues a frame on a client, slow consumers are dropped instead of stalling the whole hub
+func (h *ChatHub) deliver(c *Client, env *schema.WSEnvelope) {
+	select {
+	case c.SendChan <- env:
+	default:
+		h.remove(c)
+	}
+}
+
+// subscribe -> Applies a subscription, to the sockets of its user when it isn't tied to one
+func (h *ChatHub) subscribe(sub *Subscription) {
+	clients := h.Users[sub.UserID]
+	if sub.Client != nil {
+		// The socket may have been dropped since the subscription was queued
+		if !h.Users[sub.Client.UserID][sub.Client] {
+			return
+		}
+		clients = map[*Client]bool{sub.Client: true}
+	}
+
+	for c := range clients {
+		if sub.Join {
+			h.join(c, sub.ChatID)
+		} else {
+			h.leave(c, sub.ChatID)
+		}
+		if sub.Ack != nil {
+			h.deliver(c, sub.Ack)
+		}
+	}
+}
+
+// refresh -> Recomputes the presence of the users connected to the replica (all of them when none are given)
+// and relays the changes to the presence registry, a heartbeat for every user renews them all
+func (h *ChatHub) refresh(users ...string) {
+	heartbeat := len(users) == 0
+	if heartbeat {
+		for uid := range h.Users {
+			users = append(users, uid)
+		}
+	}
+
+	now := time.Now()
+	changed := make(map[string]string)
+	var offline []string
+
+	h.mu.Lock()
+	for _, uid := range users {
+		status := PresenceOffline
+		for c := range h.Users[uid] {
+			if status = c.status(now); status == PresenceOnline {
+				break
+			}
+		}
+
+		switch {
+		case status == PresenceOffline:
+			if _, ok := h.presence[uid]; ok {
+				delete(h.presence, uid)
+				offline = append(offline, uid)
+			}
+		case heartbeat || h.presence[uid] != status:
+			h.presence[uid] = status
+			changed[uid] = status
+		}
+	}
+	h.mu.Unlock()
+
+	if len(changed) > 0 {
+		h.forward(busOp{online: changed})
+	}
+	if len(offline) > 0 {
+		h.forward(busOp{offline: offline})
+	}
+}
+
+// forward -> Hands work to the bus goroutine, dropping it when redis can't keep up rather than stalling the hub
+func (h *ChatHub) forward(op busOp) {
+	if h.Bus == nil {
+		return
+	}
+	if op.msg != nil {
+		op.msg.Instance = h.ID
+	}
+
+	select {
+	case h.outbox <- op:
+	default:
+		ChatBusMessages.WithLabelValues("dropped").Inc()
+		Logger("chat").Warn("The chat bus is falling behind, dropped an action", "instance", h.ID)
+	}
+}
+
+// relay -> Runs the bus work of the hub
+func (h *ChatHub) relay() {
+	for op := range h.outbox {
+		switch {
+		case op.msg != nil:
+			if err := h.Bus.PublishEvent(op.msg); err == nil {
+				ChatBusMessages.WithLabelValues("published").Inc()
+			}
+			if op.msg.Kind == BusEvent && op.msg.Data != nil && op.msg.Data.Seq != 0 {
+				_ = h.Bus.AppendReplay(op.msg)
+			}
+		case op.online != nil:
+			_ = h.Bus.Heartbeat(h.ID, op.online, PresenceTTL)
+		case op.offline != nil:
+			_ = h.Bus.RemovePresence(h.ID, op.offline)
+		}
+	}
+}
+
+// listen -> Applies the actions published by the other replicas to the local sockets
+func (h *ChatHub) listen(ctx context.Context) {
+	for msg := range h.Bus.SubscribeEvents(ctx) {
+		if msg.Instance == h.ID {
+			continue
+		}
+		ChatBusMessages.WithLabelValues("received").Inc()
 
-	for msg := range c.SendChan {
-		if err := c.Conn.WriteJSON(msg); err != nil {
-			break
+		switch msg.Kind {
+		case BusEvent:
+			h.Broadcast <- &BroadcastMessage{ChatID: msg.ChatID, UserID: msg.UserID, Data: msg.Data, remote: true}
+		case BusKick:
+			h.Kick <- &KickMessage{ChatID: msg.ChatID, UserID: msg.UserID, Reason: msg.Reason, remote: true}
+		case BusSubscribe, BusUnsubscribe:
+			h.Subscribe <- &Subscription{UserID: msg.UserID, ChatID: msg.ChatID, Join: msg.Kind == BusSubscribe, remote: true}
 		}
 	}
 }
 
 func (h *ChatHub) Run() {
-	log.Println("[Chat HUB] THe Chat HUB is up and running")
+	Logger("chat").Info("The Chat HUB is up and running", "instance", h.ID)
+
+	if h.Bus != nil {
+		go h.relay()
+		go h.listen(context.Background())
+	}
+
+	// Renews the presence of the connected users and turns the idle ones away
+	heartbeat := time.NewTicker(presenceInterval)
+	defer heartbeat.Stop()
+
 	for {
 		select {
+		case <-heartbeat.C:
+			h.refresh()
+		case c := <-h.Activity:
+			if h.Users[c.UserID][c] {
+				h.refresh(c.UserID)
+			}
 		case c := <-h.Register:
-			if h.Room[c.ChatID] == nil {
-				h.Room[c.ChatID] = make(map[*Client]bool)
+			if h.Users[c.UserID] == nil {
+				h.Users[c.UserID] = make(map[*Client]bool)
+			}
+			h.Users[c.UserID][c] = true
+			for chatID := range c.chats {
+				h.join(c, chatID)
 			}
-			h.Room[c.ChatID][c] = true
+			h.refresh(c.UserID)
+			WSConnections.Inc()
 		case c := <-h.UnRegister:
-			if room := h.Room[c.ChatID]; room != nil {
-				delete(room, c)
-				close(c.SendChan)
+			h.remove(c)
+		case sub := <-h.Subscribe:
+			h.subscribe(sub)
+			// Subscriptions of a user follow them to the sockets they have open on the other replicas
+			if sub.Client == nil && !sub.remote {
+				kind := BusUnsubscribe
+				if sub.Join {
+					kind = BusSubscribe
+				}
+				h.forward(busOp{msg: &BusMessage{Kind: kind, ChatID: sub.ChatID, UserID: sub.UserID}})
+			}
+		case k := <-h.Kick:
+			for c := range h.Room[k.ChatID] {
+				if k.UserID == "" || c.UserID == k.UserID {
+					h.leave(c, k.ChatID)
+					h.deliver(c, NewEvent(schema.EventChatClosed, k.ChatID, "", schema.WSChatClosed{Reason: k.Reason}))
+				}
+			}
+			if !k.remote {
+				h.forward(busOp{msg: &BusMessage{Kind: BusKick, ChatID: k.ChatID, UserID: k.UserID, Reason: k.Reason}})
 			}
 		case msg := <-h.Broadcast:
-			room := h.Room[msg.ChatID]
-			for c := range room {
-				select {
-				case c.SendChan <- msg.Data:
-				default:
-					close(c.SendChan)
-					delete(room, c)
+			if msg.To == nil && !msg.remote {
+				h.stamp(msg)
+				h.forward(busOp{msg: &BusMessage{Kind: BusEvent, ChatID: msg.ChatID, UserID: msg.UserID, Data: msg.Data}})
+			}
+
+			switch {
+			case msg.To != nil:
+				// The socket may have been dropped since the frame was queued
+				if h.Users[msg.To.UserID][msg.To] {
+					h.deliver(msg.To, msg.Data)
+				}
+			case msg.UserID != "":
+				for c := range h.Users[msg.UserID] {
+					h.deliver(c, msg.Data)
+				}
+			default:
+				for c := range h.Room[msg.ChatID] {
+					if c != msg.Except {
+						h.deliver(c, msg.Data)
+					}
 				}
 			}
 		}
+		WSRooms.Set(float64(len(h.Room)))
+	}
+}
+
+// RateLimiter -> Token bucket allowing rate events per second with bursts up to burst, not safe for concurrent use
+type RateLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Allow -> Takes a token from the bucket, reports false when it is empty
+func (l *RateLimiter) Allow() bool {
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
 	}
+	l.tokens--
+	return true
 }
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"context"
+
+	"findme/model"
+	"findme/schema"
+)
+
+type Notifier interface {
+	Notify(ctx context.Context, uid, notifyType, title, body string, refID *string)
+	Unread(ctx context.Context, uid string) (int64, error)
+	PublishRead(ctx context.Context, uid, id string, deleted bool)
+}
+
+// NotificationService -> Writes the notification center of the users and delivers the new entries live
+// to their sockets and event streams
+type NotificationService struct {
+	DB   DB
+	Chat *ChatHub
+}
+
+func NewNotificationService(db DB, chat *ChatHub) *NotificationService {
+	return &NotificationService{DB: db, Chat: chat}
+}
+
+// ViewNotification -> Maps a notification to its view
+func ViewNotification(n *model.Notification) schema.ViewNotification {
+	return schema.ViewNotification{ID: n.ID, Type: n.Type, Title: n.Title, Body: n.Body, RefID: n.RefID, ReadAt: n.ReadAt, CreatedAt: n.CreatedAt}
+}
+
+// Notify -> Records a notification of a user and sends it to their connected clients along with the unread count,
+// unless they turned the in-app notifications of its category off (the transactional ones always go out).
+// Failures are logged (by the db) as the action the notification is about already happened
+func (n *NotificationService) Notify(ctx context.Context, uid, notifyType, title, body string, refID *string) {
+	if category := model.NotifyCategory(notifyType); category != "" {
+		var pref model.NotificationPreference
+		if err := n.DB.WithContext(ctx).FetchNotificationPreference(uid, category, &pref); err == nil && !pref.Allows(model.ChannelInApp) {
+			return
+		}
+	}
+
+	notification := model.Notification{UserID: uid, Type: notifyType, Title: title, Body: body, RefID: refID}
+	if err := n.DB.WithContext(ctx).AddNotification(&notification); err != nil {
+		return
+	}
+
+	unread, err := n.Unread(ctx, uid)
+	if err != nil || n.Chat == nil {
+		return
+	}
+	n.Chat.PublishUser(uid, NewEvent(schema.EventNotification, "", "", schema.WSNotification{Notification: ViewNotification(&notification), Unread: unread}))
+}
+
+// Unread -> Returns the unread count of a user, the badge of the notification center
+func (n *NotificationService) Unread(ctx context.Context, uid string) (int64, error) {
+	var unread int64
+	err := n.DB.WithContext(ctx).CountUnreadNotifications(uid, &unread)
+	return unread, err
+}
+
+// PublishRead -> Tells the clients of a user a notification was read or deleted (all of them read for an empty id),
+// so the badge stays in sync across devices
+func (n *NotificationService) PublishRead(ctx context.Context, uid, id string, deleted bool) {
+	if n.Chat == nil {
+		return
+	}
+	unread, err := n.Unread(ctx, uid)
+	if err != nil {
+		return
+	}
+	n.Chat.PublishUser(uid, NewEvent(schema.EventNotifyRead, "", "", schema.WSNotificationsRead{ID: id, Deleted: deleted, Unread: unread}))
+}
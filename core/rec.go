@@ -2,20 +2,24 @@ package core
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
 	"findme/rec"
 	"findme/schema"
 
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Recommendation interface {
-	QueueUserRecommendation(ID string)
-	QueueProjectRecommendation(ID string)
-	GetRecommendation(ID string, jobType RecommendationJobType) (*schema.RecResponse, error)
+	QueueUserRecommendation(ctx context.Context, ID string)
+	QueueProjectRecommendation(ctx context.Context, ID string)
+	GetRecommendation(ctx context.Context, ID string, jobType RecommendationJobType) (*schema.RecResponse, error)
+	CircuitState() string
+	CheckHealth() error
+	Stats() HubStats
 }
 
 type RecommendationJobType int
@@ -30,21 +34,25 @@ type RecommendationJob struct {
 	ID          string
 	Attempts    int
 	MaxAttempts int
+	JobID       string
+	QueuedBy    trace.SpanContext
 }
 
 type RecommendationHub struct {
-	Jobs     chan *RecommendationJob
-	Quit     chan bool
-	Workers  int
-	GPRCAddr string
+	Jobs    chan *RecommendationJob
+	Quit    chan bool
+	Workers int
+	Conn    *GRPCConn
+	Client  rec.RecommendationServiceClient
 }
 
-func NewRecommendationHub(workers, queuesize int, addr string) *RecommendationHub {
+func NewRecommendationHub(workers, queuesize int, conn *GRPCConn) *RecommendationHub {
 	return &RecommendationHub{
-		Jobs:     make(chan *RecommendationJob, queuesize),
-		Quit:     make(chan bool),
-		Workers:  workers,
-		GPRCAddr: addr,
+		Jobs:    make(chan *RecommendationJob, queuesize),
+		Quit:    make(chan bool),
+		Workers: workers,
+		Conn:    conn,
+		Client:  rec.NewRecommendationServiceClient(conn.Conn),
 	}
 }
 
@@ -52,32 +60,48 @@ func (r *RecommendationHub) Run() {
 	for range r.Workers {
 		go r.WorkerPool()
 	}
-	log.Println("[gRPC Recommendation] The Recommendation Hub is up and running")
+	Logger("recommendation").Info("The Recommendation Hub is up and running", "workers", r.Workers)
 }
 
-func (r *RecommendationHub) WorkerPool() {
-	conn, err := grpc.NewClient(r.GPRCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		log.Println("[gRPC Recommendation] Failed to connect to grpc with address -> ", r.GPRCAddr)
-	}
+// CircuitState -> Returns the state of the circuit breaker on the recommendation service connection
+func (r *RecommendationHub) CircuitState() string {
+	return r.Conn.Breaker.State()
+}
 
-	defer conn.Close()
+// CheckHealth -> Checks the recommendation service through the gRPC health protocol
+func (r *RecommendationHub) CheckHealth() error {
+	return r.Conn.CheckHealth()
+}
 
-	client := rec.NewRecommendationServiceClient(conn)
+// Stats -> Returns the queue depth and worker count of the hub
+func (r *RecommendationHub) Stats() HubStats {
+	return HubStats{Queued: len(r.Jobs), Capacity: cap(r.Jobs), Workers: r.Workers}
+}
 
+func (r *RecommendationHub) WorkerPool() {
 	for {
 		select {
 		case job := <-r.Jobs:
-			_, err := r.ProcessJob(job, client)
+			ctx, span := startJobSpan("recommendation.job", job.QueuedBy, attribute.Int("job.type", int(job.Type)), attribute.Int("job.attempt", job.Attempts+1))
+			ctx = WithLogFields(ctx, "component", "recommendation", "job_id", job.JobID, "job_type", int(job.Type))
+			_, err := r.ProcessJob(ctx, job)
+			endSpan(span, err)
 			if err != nil {
 				job.Attempts++
+				RecordJob("recommendation", err, job.Attempts <= job.MaxAttempts)
 				if job.Attempts <= job.MaxAttempts {
 					waitTime := time.Duration(job.Attempts*3) * time.Second
+					slog.WarnContext(ctx, "Recommendation job failed, retrying", "err", err, "attempt", job.Attempts, "retry_in", waitTime.String())
+
 					go func(job *RecommendationJob, delay time.Duration) {
 						time.Sleep(delay)
 						r.Jobs <- job
 					}(job, waitTime)
+				} else {
+					slog.ErrorContext(ctx, "Recommendation job failed, giving up", "err", err, "attempts", job.Attempts)
 				}
+			} else {
+				RecordJob("recommendation", nil, false)
 			}
 		case <-r.Quit:
 			return
@@ -85,8 +109,8 @@ func (r *RecommendationHub) WorkerPool() {
 	}
 }
 
-func (r *RecommendationHub) ProcessJob(job *RecommendationJob, client rec.RecommendationServiceClient) (*schema.RecResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+func (r *RecommendationHub) ProcessJob(ctx context.Context, job *RecommendationJob) (*schema.RecResponse, error) {
+	ctx, cancel := r.Conn.Context(ctx)
 	defer cancel()
 
 	var err error
@@ -95,11 +119,11 @@ func (r *RecommendationHub) ProcessJob(job *RecommendationJob, client rec.Recomm
 
 	switch job.Type {
 	case UserRecommendation:
-		recRes, err = client.UserRecommendation(ctx, &rec.RecommendationRequest{
+		recRes, err = r.Client.UserRecommendation(ctx, &rec.RecommendationRequest{
 			Id: job.ID,
 		})
 	case ProjectRecommendation:
-		recRes, err = client.ProjectRecommendation(ctx, &rec.RecommendationRequest{
+		recRes, err = r.Client.ProjectRecommendation(ctx, &rec.RecommendationRequest{
 			Id: job.ID,
 		})
 	}
@@ -111,39 +135,34 @@ func (r *RecommendationHub) ProcessJob(job *RecommendationJob, client rec.Recomm
 	return res, err
 }
 
-func (r *RecommendationHub) QueueUserRecommendation(projectID string) {
+func (r *RecommendationHub) QueueUserRecommendation(ctx context.Context, projectID string) {
 	r.Jobs <- &RecommendationJob{
+		JobID:       uuid.NewString(),
+		QueuedBy:    trace.SpanContextFromContext(ctx),
 		Type:        UserRecommendation,
 		ID:          projectID,
 		MaxAttempts: 3,
 	}
 }
 
-func (r *RecommendationHub) QueueProjectRecommendation(userID string) {
+func (r *RecommendationHub) QueueProjectRecommendation(ctx context.Context, userID string) {
 	r.Jobs <- &RecommendationJob{
+		JobID:       uuid.NewString(),
+		QueuedBy:    trace.SpanContextFromContext(ctx),
 		Type:        ProjectRecommendation,
 		ID:          userID,
 		MaxAttempts: 3,
 	}
 }
 
-func (r *RecommendationHub) GetRecommendation(ID string, jobType RecommendationJobType) (*schema.RecResponse, error) {
-	conn, err := grpc.NewClient(r.GPRCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		log.Println("[gRPC Recommendation] Failed to connect to grpc with address -> ", r.GPRCAddr)
-	}
-
-	defer conn.Close()
-
-	client := rec.NewRecommendationServiceClient(conn)
-
+func (r *RecommendationHub) GetRecommendation(ctx context.Context, ID string, jobType RecommendationJobType) (*schema.RecResponse, error) {
 	job := &RecommendationJob{
 		Type:        jobType,
 		ID:          ID,
 		MaxAttempts: 1,
 	}
 
-	res, err := r.ProcessJob(job, client)
+	res, err := r.ProcessJob(ctx, job)
 	if err != nil {
 		return nil, err
 	}
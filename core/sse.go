@@ -0,0 +1,155 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"findme/schema"
+)
+
+// sseRetry -> Delay (in milliseconds) the browsers wait before reconnecting a dropped event stream
+const sseRetry = 3000
+
+// replayable -> Whether an event is worth delivering late to a resuming stream, typing indicators aren't
+func replayable(eventType string) bool {
+	return eventType != schema.EventTypingStart && eventType != schema.EventTypingStop
+}
+
+// stamp -> Gives a chat or personal event its seq, the time it was published in microseconds (kept increasing),
+// and buffers it for replay when there is no bus to do it. Must run on the hub goroutine
+func (h *ChatHub) stamp(msg *BroadcastMessage) {
+	if msg.Data == nil || !replayable(msg.Data.Type) {
+		return
+	}
+
+	h.seq = max(time.Now().UnixMicro(), h.seq+1)
+	msg.Data.Seq = h.seq
+	if h.Bus != nil {
+		return
+	}
+
+	cutoff := time.Now().Add(-ReplayWindow).UnixMicro()
+	h.replayMu.Lock()
+	defer h.replayMu.Unlock()
+
+	h.replay = append(h.replay, &BusMessage{Instance: h.ID, Kind: BusEvent, ChatID: msg.ChatID, UserID: msg.UserID, Data: msg.Data})
+	drop := max(len(h.replay)-ReplaySize, 0)
+	for drop < len(h.replay) && h.replay[drop].Data.Seq < cutoff {
+		drop++
+	}
+	h.replay = h.replay[drop:]
+}
+
+// Replay -> Returns the buffered events of a user published after the given seq, the personal ones and those of
+// the given chats. complete is false when the seq is past the replay window, so some events may be lost
+func (h *ChatHub) Replay(uid string, chats []string, after int64) (events []*schema.WSEnvelope, complete bool, err error) {
+	var msgs []*BusMessage
+	if h.Bus != nil {
+		if msgs, err = h.Bus.Replay(after); err != nil {
+			return nil, false, err
+		}
+	} else {
+		h.replayMu.Lock()
+		for _, msg := range h.replay {
+			if msg.Data.Seq > after {
+				msgs = append(msgs, msg)
+			}
+		}
+		h.replayMu.Unlock()
+	}
+
+	member := make(map[string]bool, len(chats))
+	for _, chatID := range chats {
+		member[chatID] = true
+	}
+	for _, msg := range msgs {
+		if (msg.UserID != "" && msg.UserID == uid) || (msg.UserID == "" && member[msg.ChatID]) {
+			events = append(events, msg.Data)
+		}
+	}
+	return events, after >= time.Now().Add(-ReplayWindow).UnixMicro(), nil
+}
+
+// ServeEvents -> Streams the events of the client as server-sent events until the request is done or the hub drops
+// the client, each data line holding a schema.WSEnvelope. A stream resuming after the given seq (0 for a new one)
+// first gets the buffered events it missed, or a resync event when they can't all be replayed.
+// The client must already be registered on the hub, so nothing published while replaying is lost
+func (c *Client) ServeEvents(ctx context.Context, hub *ChatHub, w http.ResponseWriter, after int64, chats []string) {
+	defer func() {
+		hub.UnRegister <- c
+
+		if err := hub.DB.WithContext(c.ctx).UpdateLastSeen(c.UserID, time.Now()); err != nil {
+			slog.WarnContext(c.ctx, "Failed to record the last seen time", "component", "chat", "err", err)
+		}
+	}()
+
+	rc := http.NewResponseController(w)
+	write := func(format string, args ...any) bool {
+		_ = rc.SetWriteDeadline(time.Now().Add(writeWait))
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+	send := func(env *schema.WSEnvelope) bool {
+		data, err := json.Marshal(env)
+		if err != nil {
+			return true
+		}
+		if env.Seq != 0 {
+			return write("id: %s\ndata: %s\n\n", strconv.FormatInt(env.Seq, 10), data)
+		}
+		return write("data: %s\n\n", data)
+	}
+
+	if !write("retry: %d\n\n", sseRetry) {
+		return
+	}
+
+	// Live events up to the last replayed one were already sent
+	replayed := after
+	if after > 0 {
+		events, complete, err := hub.Replay(c.UserID, chats, after)
+		if err != nil || !complete {
+			if !send(NewEvent(schema.EventResync, "", "", nil)) {
+				return
+			}
+		}
+		for _, env := range events {
+			if !send(env) {
+				return
+			}
+			replayed = env.Seq
+		}
+	}
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case env, ok := <-c.SendChan:
+			if !ok {
+				return
+			}
+			if env.Seq != 0 && env.Seq <= replayed {
+				continue
+			}
+			if !send(env) {
+				return
+			}
+		case <-ticker.C:
+			// Comments keep the proxies from closing an idle stream
+			if !write(": ping\n\n") {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package core
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"maps"
+	"slices"
+	texttemplate "text/template"
+	"time"
+)
+
+// The emails are a pair of templates sharing a layout, <name>.html for the html part (escaped per context, so the
+// messages and names of the users can't inject markup or links) and <name>.txt for the plain text part and the subject
+//
+//go:embed templates/email
+var emailFiles embed.FS
+
+// Email templates
+const (
+	TemplateForgotPassword        = "forgot_password"
+	TemplateFriendRequest         = "friend_request"
+	TemplateProjectApplication    = "project_application"
+	TemplateApplicationAccepted   = "application_accepted"
+	TemplateApplicationRejected   = "application_rejected"
+	TemplateMention               = "mention"
+	TemplateChatExport            = "chat_export"
+	TemplateMessageDigest         = "message_digest"
+	TemplateSubscriptionCreated   = "subscription_created"
+	TemplatePaymentFailed         = "payment_failed"
+	TemplateSubscriptionReEnabled = "subscription_reenabled"
+	TemplateSubscriptionCancelled = "subscription_cancelled"
+	TemplateTrialEnding           = "trial_ending"
+)
+
+// EmailContent -> A rendered email, sent as multipart/alternative with the text part first
+type EmailContent struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+type otpEmail struct {
+	Username string
+	Token    string
+}
+
+type friendReqEmail struct {
+	From    string
+	To      string
+	Message string
+	URL     string
+}
+
+type applicationEmail struct {
+	From        string
+	To          string
+	Description string
+	Reason      string
+	URL         string
+}
+
+type mentionEmail struct {
+	From     string
+	To       string
+	ChatName string
+	Message  string
+}
+
+type chatExportEmail struct {
+	Username string
+	ChatName string
+	Format   string
+	URL      string
+	Expires  string
+}
+
+type digestEmail struct {
+	Username       string
+	Unread         int
+	Chats          []DigestChat
+	UnsubscribeURL string
+}
+
+type paymentEmail struct {
+	Username string
+	Amount   string
+	Currency string
+	PlanName string
+	URL      string
+}
+
+type accountEmail struct {
+	Username string
+	Date     string
+	URL      string
+}
+
+type emailButton struct {
+	Label string
+	URL   string
+}
+
+// emailSamples -> The data every template is previewed with, a template missing here isn't loaded
+var emailSamples = map[string]any{
+	TemplateForgotPassword: otpEmail{Username: "janedoe", Token: "482913"},
+	TemplateFriendRequest: friendReqEmail{
+		From: "johndoe", To: "janedoe", Message: "Hey, loved your <b>Go</b> projects! Let's build something together.", URL: "https://findme.app/requests",
+	},
+	TemplateProjectApplication: applicationEmail{
+		From: "johndoe", To: "janedoe", Description: "A platform for finding developers for contributive projects", URL: "https://findme.app/applications",
+	},
+	TemplateApplicationAccepted: applicationEmail{
+		From: "janedoe", To: "johndoe", Description: "A platform for finding developers for contributive projects", URL: "https://findme.app/chats",
+	},
+	TemplateApplicationRejected: applicationEmail{
+		From: "janedoe", To: "johndoe", Description: "A platform for finding developers for contributive projects", Reason: "We are looking for someone with more frontend experience.",
+	},
+	TemplateMention: mentionEmail{
+		From: "johndoe", To: "janedoe", ChatName: "Backend Team", Message: "@janedoe can you review the migration before we ship it?",
+	},
+	TemplateChatExport: chatExportEmail{
+		Username: "janedoe", ChatName: "Backend Team", Format: "markdown", URL: "https://findme.app/api/msg/export/sample", Expires: "Jan 2, 2026 15:04 UTC",
+	},
+	TemplateMessageDigest: digestEmail{
+		Username: "janedoe",
+		Unread:   3,
+		Chats: []DigestChat{
+			{Name: "Backend Team", Unread: 2, Messages: []DigestMessage{
+				{From: "johndoe", Message: "The deploy is done", Sent: time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)},
+				{From: "johndoe", Message: "Can you check the logs?", Sent: time.Date(2026, 1, 2, 15, 6, 0, 0, time.UTC)},
+			}},
+			{Name: "johndoe", Unread: 1, Messages: []DigestMessage{
+				{From: "johndoe", Message: "Are you around?", Sent: time.Date(2026, 1, 2, 16, 0, 0, 0, time.UTC)},
+			}},
+		},
+		UnsubscribeURL: "https://findme.app/unsubscribe?token=sample",
+	},
+	TemplateSubscriptionCreated:   paymentEmail{Username: "janedoe", Amount: "5000", Currency: "NGN", PlanName: "Pro", URL: "https://findme.app/settings/subscription"},
+	TemplatePaymentFailed:         paymentEmail{Username: "janedoe", Amount: "5000", Currency: "NGN", PlanName: "Pro", URL: "https://findme.app/settings/subscription"},
+	TemplateSubscriptionReEnabled: accountEmail{Username: "janedoe", Date: "Feb 2, 2026"},
+	TemplateSubscriptionCancelled: accountEmail{Username: "janedoe", Date: "Feb 2, 2026"},
+	TemplateTrialEnding:           accountEmail{Username: "janedoe", Date: "Feb 2, 2026", URL: "https://findme.app/subscribe"},
+}
+
+type emailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+var emailTemplates = loadEmailTemplates()
+
+var emailFuncs = map[string]any{
+	"action": func(label, url string) emailButton { return emailButton{Label: label, URL: url} },
+	"sent":   func(t time.Time) string { return t.UTC().Format("Jan 2, 15:04 MST") },
+}
+
+// loadEmailTemplates -> Parses every template along with the layout, a broken template fails at startup
+func loadEmailTemplates() map[string]*emailTemplate {
+	templates := make(map[string]*emailTemplate, len(emailSamples))
+	for name := range emailSamples {
+		html := htmltemplate.Must(htmltemplate.New(name).Funcs(emailFuncs).ParseFS(emailFiles, "templates/email/layout.html", "templates/email/"+name+".html"))
+		text := texttemplate.Must(texttemplate.New(name).Funcs(emailFuncs).ParseFS(emailFiles, "templates/email/layout.txt", "templates/email/"+name+".txt"))
+		templates[name] = &emailTemplate{html: html, text: text}
+	}
+	return templates
+}
+
+// EmailTemplates -> Returns the names of the email templates
+func EmailTemplates() []string {
+	return slices.Sorted(maps.Keys(emailTemplates))
+}
+
+// RenderEmail -> Renders the subject, html and plain text parts of an email
+func RenderEmail(name string, data any) (EmailContent, error) {
+	tmpl, ok := emailTemplates[name]
+	if !ok {
+		return EmailContent{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, html, text bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return EmailContent{}, err
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return EmailContent{}, err
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "layout", data); err != nil {
+		return EmailContent{}, err
+	}
+
+	return EmailContent{Subject: subject.String(), HTML: html.String(), Text: text.String()}, nil
+}
+
+// PreviewEmail -> Writes a template rendered with its sample data, the html part unless the format is text
+func PreviewEmail(w io.Writer, name, format string) error {
+	content, err := RenderEmail(name, emailSamples[name])
+	if err != nil {
+		return err
+	}
+
+	if format == "text" {
+		_, err = fmt.Fprintf(w, "Subject: %s\n\n%s", content.Subject, content.Text)
+		return err
+	}
+	_, err = io.WriteString(w, content.HTML)
+	return err
+}
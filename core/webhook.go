@@ -0,0 +1,430 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"findme/model"
+	"findme/schema"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// WebhookMaxAttempts -> Attempts of a delivery before it is counted as a failure of the webhook
+	WebhookMaxAttempts = 5
+
+	// webhookTimeout -> Time an endpoint has to answer a delivery
+	webhookTimeout = 10 * time.Second
+
+	// webhookErrorLength -> Characters of a failed response body kept in the delivery log
+	webhookErrorLength = 500
+
+	// webhookLease -> Time a queued delivery is kept from the other pollers (and instances), after it the delivery is
+	// claimed again as its worker is presumed gone
+	webhookLease = time.Minute
+
+	// webhookPollBatch -> Deliveries claimed by a poll
+	webhookPollBatch = 100
+)
+
+// Headers of the webhook deliveries, the signature is the hex HMAC-SHA256 of "<timestamp>.<body>" with the webhook secret
+const (
+	WebhookEventHeader     = "X-FindMe-Event"
+	WebhookDeliveryHeader  = "X-FindMe-Delivery"
+	WebhookTimestampHeader = "X-FindMe-Timestamp"
+	WebhookSignatureHeader = "X-FindMe-Signature"
+)
+
+// errWebhookAddress -> Returned when a webhook url resolves to an address of the internal network
+var errWebhookAddress = errors.New("the webhook address isn't public")
+
+// allowPrivateWebhooks -> Lets webhooks reach loopback and private addresses, only turned on by the tests
+var allowPrivateWebhooks atomic.Bool
+
+// AllowPrivateWebhooks -> Allows (or forbids again) webhooks to loopback and private addresses, for tests against
+// local servers
+func AllowPrivateWebhooks(allow bool) {
+	allowPrivateWebhooks.Store(allow)
+}
+
+// internalPrefixes -> Ranges that aren't routed on the internet and aren't covered by netip.Addr.IsPrivate,
+// the "this network" block and the carrier-grade NAT one
+var internalPrefixes = []netip.Prefix{netip.MustParsePrefix("0.0.0.0/8"), netip.MustParsePrefix("100.64.0.0/10")}
+
+// IsPublicAddr -> Whether an address can be reached by a webhook, anything loopback, private, link-local (the cloud
+// metadata endpoints), multicast or unspecified stays internal
+func IsPublicAddr(addr netip.Addr) bool {
+	if allowPrivateWebhooks.Load() {
+		return true
+	}
+
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range internalPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckWebhookURL -> Checks a webhook url is an absolute http(s) url whose host only resolves to public addresses.
+// The same rule is enforced again when connecting, so a host re-resolving to an internal address gets nowhere
+func CheckWebhookURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return &CustomMessage{http.StatusUnprocessableEntity, "The webhook url must be an http or https url."}
+	}
+
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil {
+		addrs = []netip.Addr{addr}
+	} else if addrs, err = net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname()); err != nil || len(addrs) == 0 {
+		return &CustomMessage{http.StatusUnprocessableEntity, "The webhook host couldn't be resolved."}
+	}
+
+	for _, addr := range addrs {
+		if !IsPublicAddr(addr) {
+			return &CustomMessage{http.StatusUnprocessableEntity, "The webhook url must point to a public address."}
+		}
+	}
+	return nil
+}
+
+// NewWebhookClient -> Returns the client of the deliveries, it refuses to connect to internal addresses (checked on
+// the resolved address, after any DNS change), doesn't go through proxies and doesn't follow redirects
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !IsPublicAddr(addrPort.Addr()) {
+				return errWebhookAddress
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// The redirect response is recorded as a failed delivery
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+type Webhooks interface {
+	Worker()
+	Stats() HubStats
+	Dispatch(ctx context.Context, event string, users []string, projectID string, data any)
+	Redeliver(ctx context.Context, delivery *model.WebhookDelivery) (*model.WebhookDelivery, error)
+}
+
+type WebhookJob struct {
+	DeliveryID string
+	Attempts   int
+	JobID      string
+	QueuedBy   trace.SpanContext
+}
+
+type WebhookHub struct {
+	Jobs       chan *WebhookJob
+	Quit       chan bool
+	WorkerPool int
+	DB         DB
+	Client     *http.Client
+
+	// Backoff -> Delay before the first retry of a failed delivery, doubled on every attempt
+	Backoff time.Duration
+	// PollInterval -> How often the deliveries due for a retry (or left pending by a restart) are claimed and queued
+	PollInterval time.Duration
+}
+
+func NewWebhookHub(queueSize, workers int, db DB, client *http.Client) *WebhookHub {
+	return &WebhookHub{
+		Jobs:       make(chan *WebhookJob, queueSize),
+		Quit:       make(chan bool),
+		WorkerPool: workers,
+		DB:         db,
+		Client:     client,
+		Backoff:    30 * time.Second,
+
+		PollInterval: 5 * time.Second,
+	}
+}
+
+func (w *WebhookHub) Run() {
+	for range w.WorkerPool {
+		go w.Worker()
+	}
+	go w.Poll()
+	Logger("webhook").Info("The Webhook hub is up and running", "workers", w.WorkerPool)
+}
+
+func (w *WebhookHub) Stop() {
+	for range w.WorkerPool + 1 {
+		w.Quit <- true
+	}
+}
+
+// Stats -> Returns the queue depth and worker count of the hub
+func (w *WebhookHub) Stats() HubStats {
+	return HubStats{Queued: len(w.Jobs), Capacity: cap(w.Jobs), Workers: w.WorkerPool}
+}
+
+// SignWebhook -> Returns the signature of a delivery body sent at the given unix timestamp
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatch -> Records a delivery of the event for every active webhook subscribed to it, those of the users and those
+// of the project (when given), and queues them leased to this instance. Failures are logged as the action behind the
+// event already happened
+func (w *WebhookHub) Dispatch(ctx context.Context, event string, users []string, projectID string, data any) {
+	var hooks []model.Webhook
+	if err := w.DB.WithContext(ctx).FetchEventWebhooks(users, projectID, &hooks); err != nil || len(hooks) == 0 {
+		return
+	}
+
+	payload := schema.WebhookPayload{ID: uuid.NewString(), Event: event, CreatedAt: time.Now().UTC(), Data: data}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to encode the webhook payload", "component", "webhook", "event", event, "err", err)
+		return
+	}
+
+	lease := time.Now().Add(webhookLease)
+	var deliveries []model.WebhookDelivery
+	for _, hook := range hooks {
+		if hook.Subscribed(event) {
+			deliveries = append(deliveries, model.WebhookDelivery{
+				WebhookID: hook.ID, EventID: payload.ID, Event: event, Payload: string(body), Status: model.StatusPending, NextAttemptAt: &lease,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+	if err := w.DB.WithContext(ctx).AddWebhookDeliveries(deliveries); err != nil {
+		return
+	}
+
+	for _, delivery := range deliveries {
+		w.queue(ctx, delivery.ID)
+	}
+}
+
+// Redeliver -> Sends the event of a delivery again as a new delivery, the webhook must still be active
+func (w *WebhookHub) Redeliver(ctx context.Context, delivery *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	if delivery.Webhook == nil || !delivery.Webhook.Active {
+		return nil, &CustomMessage{http.StatusConflict, "The webhook is disabled, enable it to redeliver its events."}
+	}
+
+	lease := time.Now().Add(webhookLease)
+	redelivery := []model.WebhookDelivery{{
+		WebhookID:     delivery.WebhookID,
+		EventID:       delivery.EventID,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		RedeliveryOf:  &delivery.ID,
+		Status:        model.StatusPending,
+		NextAttemptAt: &lease,
+	}}
+	if err := w.DB.WithContext(ctx).AddWebhookDeliveries(redelivery); err != nil {
+		return nil, err
+	}
+
+	w.queue(ctx, redelivery[0].ID)
+	return &redelivery[0], nil
+}
+
+// queue -> Hands a delivery to the workers without waiting on them, so the requests dispatching events don't hang
+// behind slow endpoints. A delivery left out of a full queue is still leased in the db, the poller sends it once the
+// lease expires
+func (w *WebhookHub) queue(ctx context.Context, deliveryID string) bool {
+	select {
+	case w.Jobs <- &WebhookJob{DeliveryID: deliveryID, JobID: uuid.NewString(), QueuedBy: trace.SpanContextFromContext(ctx)}:
+		return true
+	default:
+		slog.WarnContext(ctx, "Webhook queue is full, leaving the delivery to the poller", "component", "webhook", "delivery_id", deliveryID)
+		return false
+	}
+}
+
+// Poll -> Queues the pending deliveries that are due, the retries scheduled by the workers and those an instance
+// stopped or crashed with. Every delivery is claimed for webhookLease first, so a delivery goes out at least once
+// but a lease expiring while it waits in a long queue can send it twice (the receivers dedupe on the event id)
+func (w *WebhookHub) Poll() {
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Only as many deliveries as the queue has room for are claimed
+			limit := min(webhookPollBatch, cap(w.Jobs)-len(w.Jobs))
+			if limit <= 0 {
+				continue
+			}
+
+			now := time.Now()
+			var ids []string
+			if err := w.DB.ClaimWebhookDeliveries(now, now.Add(webhookLease), limit, &ids); err != nil {
+				continue
+			}
+			for _, id := range ids {
+				if !w.queue(context.Background(), id) {
+					break
+				}
+			}
+		case <-w.Quit:
+			return
+		}
+	}
+}
+
+func (w *WebhookHub) Worker() {
+	for {
+		select {
+		case job := <-w.Jobs:
+			ctx, span := startJobSpan("webhook.deliver", job.QueuedBy, attribute.String("webhook.delivery", job.DeliveryID), attribute.Int("job.attempt", job.Attempts+1))
+			ctx = WithLogFields(ctx, "component", "webhook", "job_id", job.JobID, "delivery_id", job.DeliveryID)
+			retry, err := w.Deliver(ctx, job)
+			endSpan(span, err)
+			RecordJob("webhook", err, retry)
+			if err == nil {
+				continue
+			}
+
+			if retry {
+				slog.WarnContext(ctx, "Webhook delivery failed, retrying", "err", err, "attempt", job.Attempts, "retry_in", w.retryDelay(job.Attempts).String())
+			} else {
+				slog.WarnContext(ctx, "Webhook delivery failed, giving up", "err", err, "attempts", job.Attempts)
+			}
+		case <-w.Quit:
+			return
+		}
+	}
+}
+
+// Deliver -> Makes an attempt of a delivery and records its outcome, returning whether a failed one is retried, the
+// retry is scheduled in the db for the poller. A delivery failing all of its attempts counts as a failure of the
+// webhook, which is disabled after too many in a row
+func (w *WebhookHub) Deliver(ctx context.Context, job *WebhookJob) (bool, error) {
+	db := w.DB.WithContext(ctx)
+
+	var delivery model.WebhookDelivery
+	if err := db.FetchWebhookDelivery(job.DeliveryID, &delivery); err != nil {
+		// The webhook was deleted along with its deliveries
+		return false, nil
+	}
+	// Already done by a worker that held an expired lease
+	if delivery.Status != model.StatusPending {
+		return false, nil
+	}
+	hook := delivery.Webhook
+	if hook == nil || !hook.Active {
+		delivery.Status, delivery.Error, delivery.NextAttemptAt = model.StatusFailed, "The webhook was disabled.", nil
+		_ = db.SaveWebhookDelivery(&delivery)
+		return false, nil
+	}
+
+	delivery.Attempts++
+	job.Attempts = delivery.Attempts
+	delivery.StatusCode, delivery.Error, delivery.NextAttemptAt = 0, "", nil
+
+	err := w.post(ctx, hook, &delivery)
+	if err == nil {
+		now := time.Now()
+		delivery.Status, delivery.DeliveredAt = model.StatusSuccess, &now
+		_ = db.SaveWebhookDelivery(&delivery)
+		_ = db.ResetWebhookFailures(hook.ID)
+		return false, nil
+	}
+
+	delivery.Error = err.Error()
+	if delivery.Attempts < WebhookMaxAttempts {
+		next := time.Now().Add(w.retryDelay(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		_ = db.SaveWebhookDelivery(&delivery)
+		return true, err
+	}
+
+	delivery.Status = model.StatusFailed
+	_ = db.SaveWebhookDelivery(&delivery)
+
+	var updated model.Webhook
+	if db.RecordWebhookFailure(hook.ID, time.Now(), &updated) == nil && !updated.Active {
+		slog.WarnContext(ctx, "Webhook disabled after failing repeatedly", "webhook_id", hook.ID, "failures", updated.Failures)
+	}
+	return false, err
+}
+
+// retryDelay -> Backoff after the given attempt of a delivery
+func (w *WebhookHub) retryDelay(attempts int) time.Duration {
+	return w.Backoff << (attempts - 1)
+}
+
+// post -> Sends the signed payload of a delivery, any response out of 2xx is an error
+func (w *WebhookHub) post(ctx context.Context, hook *model.Webhook, delivery *model.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "FindMe-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(hook.Secret, timestamp, body))
+
+	res, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	delivery.StatusCode = res.StatusCode
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+
+	text, _ := io.ReadAll(io.LimitReader(res.Body, webhookErrorLength))
+	return fmt.Errorf("endpoint answered %d: %s", res.StatusCode, text)
+}
@@ -3,7 +3,7 @@ package database
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 
 	"findme/model"
@@ -27,7 +27,7 @@ func Connect() *gorm.DB {
 	db.Logger.LogMode(logger.Silent)
 
 	if err != nil {
-		log.Fatalf("[ERROR] [DB] Failed to establish database connection -> %s", err.Error())
+		fatal("Failed to establish database connection", err)
 	}
 
 	err = db.AutoMigrate(
@@ -40,41 +40,83 @@ func Connect() *gorm.DB {
 		&model.UserFriend{},
 		&model.FriendReq{},
 		&model.UserMessage{},
+		&model.MessageReaction{},
+		&model.Attachment{},
+		&model.MessageRevision{},
+		&model.HiddenMessage{},
+		&model.MessageMention{},
+		&model.Notification{},
+		&model.NotificationPreference{},
 		&model.UserSavedProject{},
 		&model.Chat{},
 		&model.ChatUser{},
+		&model.ChatInvite{},
+		&model.ChatJoinRequest{},
 		&model.Subscriptions{},
 		&model.Transactions{},
+		&model.Webhook{},
+		&model.WebhookDelivery{},
 	)
 	if err != nil {
-		log.Fatalf("[ERROR] [DB] Failed to create tables -> %s", err.Error())
+		fatal("Failed to create tables", err)
 	}
 
 	err = db.SetupJoinTable(&model.Project{}, "Tags", &model.ProjectSkill{})
 	if err != nil {
-		log.Fatalf("[ERROR] [DB] Failed to create join table on project and skills -> %s", err.Error())
+		fatal("Failed to create join table on project and skills", err)
 	}
 
 	err = db.SetupJoinTable(&model.User{}, "Skills", &model.UserSkill{})
 	if err != nil {
-		log.Fatalf("[ERROR] [DB] Failed to create join table on user and skills -> %s", err.Error())
+		fatal("Failed to create join table on user and skills", err)
 	}
 
 	err = db.SetupJoinTable(&model.User{}, "Friends", &model.UserFriend{})
 	if err != nil {
-		log.Fatalf("[ERROR] [DB] Failed to create join table on user and friends -> %s", err.Error())
+		fatal("Failed to create join table on user and friends", err)
 	}
 
 	err = db.SetupJoinTable(&model.User{}, "Chats", &model.ChatUser{})
 	if err != nil {
-		log.Fatalf("[ERROR] [DB] Failed to create join table on user and chats -> %s", err.Error())
+		fatal("Failed to create join table on user and chats", err)
 	}
 
 	err = db.SetupJoinTable(&model.User{}, "SavedProjects", &model.UserSavedProject{})
 	if err != nil {
-		log.Fatalf("[ERROR] [DB] Failed to create join table on user and saved posts -> %s", err.Error())
+		fatal("Failed to create join table on user and saved posts", err)
 	}
 
-	log.Println("[INFO] [DB] Connected to the database successfully.")
+	// Group owners from before the member roles
+	err = db.Exec(`UPDATE chat_users SET role = ? WHERE role <> ? AND EXISTS
+		(SELECT 1 FROM chats c WHERE c.id = chat_users.chat_id AND c.owner_id = chat_users.user_id)`, model.RoleOwner, model.RoleOwner).Error
+	if err != nil {
+		fatal("Failed to set the group owner roles", err)
+	}
+
+	// Full-text search over the messages, the generated column keeps itself in sync with the message text
+	err = db.Exec(fmt.Sprintf(`ALTER TABLE user_messages ADD COLUMN IF NOT EXISTS search tsvector
+		GENERATED ALWAYS AS (to_tsvector('%s', message)) STORED`, model.MessageSearchConfig)).Error
+	if err != nil {
+		fatal("Failed to create the message search column", err)
+	}
+
+	err = db.Exec("CREATE INDEX IF NOT EXISTS idx_user_messages_search ON user_messages USING GIN (search)").Error
+	if err != nil {
+		fatal("Failed to create the message search index", err)
+	}
+
+	// Chat history pages are read by (created_at, id) within a chat
+	err = db.Exec("CREATE INDEX IF NOT EXISTS idx_user_messages_history ON user_messages (chat_id, created_at, id)").Error
+	if err != nil {
+		fatal("Failed to create the chat history index", err)
+	}
+
+	slog.Info("Connected to the database successfully.", "component", "db")
 	return db
 }
+
+// fatal -> Logs the connection error and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "component", "db", "err", err)
+	os.Exit(1)
+}
@@ -2,7 +2,7 @@ package database
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"os"
 	"time"
 
@@ -23,9 +23,10 @@ func ConnectRedis() *redis.Client {
 	defer cancel()
 
 	if _, err := redisClient.Ping(ctx).Result(); err != nil {
-		log.Fatalf("[ERROR] [RDB] Could not connect to redis -> %v", err)
+		slog.Error("Could not connect to redis", "component", "cache", "err", err)
+		os.Exit(1)
 	}
 
-	log.Println("[INFO] [RDB] Connected to the redis client successfully.")
+	slog.Info("Connected to the redis client successfully.", "component", "cache")
 	return redisClient
 }
@@ -22,7 +22,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for adding users to a group chat",
+                "description": "An endpoint for the admins of a group chat to add users to it",
                 "consumes": [
                     "application/json"
                 ],
@@ -69,6 +69,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "409": {
+                        "description": "Already a member",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "422": {
                         "description": "Invalid payload",
                         "schema": {
@@ -84,14 +90,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/msg/delete-chat": {
-            "delete": {
+        "/api/msg/archive-chat": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for deleting a group chat owned by the current user",
+                "description": "An endpoint for moving a chat out of the chat list into the archived chats, or back",
                 "consumes": [
                     "application/json"
                 ],
@@ -101,22 +107,21 @@ const docTemplate = `{
                 "tags": [
                     "Msg"
                 ],
-                "summary": "Delete a group chat",
+                "summary": "Archive a chat",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "chat ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "Archive payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.ArchiveChat"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Chat deleted"
-                    },
-                    "400": {
-                        "description": "Invalid id",
+                    "202": {
+                        "description": "Chat updated",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -133,8 +138,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "422": {
+                        "description": "Invalid payload",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -148,51 +153,61 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/msg/delete-message": {
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "An endpoint for deleting a sent message of the current user",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/msg/attachment/{id}": {
+            "get": {
+                "description": "An endpoint for downloading a file sent in a chat through the signed url found in the message attachments, the url only works while its user is still a member of the chat",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
                     "Msg"
                 ],
-                "summary": "Delete a sent message",
+                "summary": "Download a chat file",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Msg ID",
+                        "description": "Attachment ID",
                         "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User the url was signed for",
+                        "name": "uid",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Expiry of the url (unix time)",
+                        "name": "exp",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature",
+                        "name": "sig",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Download the thumbnail of an image",
+                        "name": "thumb",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Message deleted"
-                    },
-                    "400": {
-                        "description": "Invalid id",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                    "200": {
+                        "description": "File content",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
+                            "type": "file"
                         }
                     },
                     "403": {
-                        "description": "Permission denied",
+                        "description": "Invalid or expired url",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -212,14 +227,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/msg/edit-message": {
-            "patch": {
+        "/api/msg/create-invite": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for editing a sent message",
+                "description": "An endpoint for the admins of a group to create a shareable invite link, optionally expiring after some hours or a number of uses",
                 "consumes": [
                     "application/json"
                 ],
@@ -229,23 +244,23 @@ const docTemplate = `{
                 "tags": [
                     "Msg"
                 ],
-                "summary": "Editing a sent message",
+                "summary": "Create an invite link",
                 "parameters": [
                     {
-                        "description": "Message payload",
+                        "description": "Invite payload",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.EditMessage"
+                            "$ref": "#/definitions/schema.NewInvite"
                         }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Message edited",
+                    "201": {
+                        "description": "Invite created",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocMsgResponse"
+                            "$ref": "#/definitions/schema.DocViewInvite"
                         }
                     },
                     "401": {
@@ -255,7 +270,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "permission denied",
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -281,14 +296,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/msg/leave-chat": {
+        "/api/msg/delete-chat": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for leaving a group chat",
+                "description": "An endpoint for deleting a group chat owned by the current user",
                 "consumes": [
                     "application/json"
                 ],
@@ -298,11 +313,11 @@ const docTemplate = `{
                 "tags": [
                     "Msg"
                 ],
-                "summary": "Leave a group chat",
+                "summary": "Delete a group chat",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Chat ID",
+                        "description": "chat ID",
                         "name": "id",
                         "in": "query",
                         "required": true
@@ -310,7 +325,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "204": {
-                        "description": "Chat removed"
+                        "description": "Chat deleted"
                     },
                     "400": {
                         "description": "Invalid id",
@@ -345,14 +360,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/msg/open-chat": {
-            "get": {
+        "/api/msg/delete-message": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for opening a chat between users with IDs.",
+                "description": "An endpoint for deleting a message, for everyone (the sender, or the owner of a group for any message) which leaves a tombstone in the chat, or only for the current user (any member)",
                 "consumes": [
                     "application/json"
                 ],
@@ -362,22 +377,29 @@ const docTemplate = `{
                 "tags": [
                     "Msg"
                 ],
-                "summary": "Open a chat between users",
+                "summary": "Delete a sent message",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Msg ID",
                         "name": "id",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "me",
+                            "everyone"
+                        ],
+                        "type": "string",
+                        "description": "Delete for me or for everyone (default)",
+                        "name": "for",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Chat opened",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocViewChatHistory"
-                        }
+                    "204": {
+                        "description": "Message deleted"
                     },
                     "400": {
                         "description": "Invalid id",
@@ -391,6 +413,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "404": {
                         "description": "Record not found",
                         "schema": {
@@ -406,14 +434,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/msg/remove-user": {
-            "delete": {
+        "/api/msg/edit-message": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for removing a user from a group chat",
+                "description": "An endpoint for editing a sent message",
                 "consumes": [
                     "application/json"
                 ],
@@ -423,21 +451,24 @@ const docTemplate = `{
                 "tags": [
                     "Msg"
                 ],
-                "summary": "Remove a user from a group",
+                "summary": "Editing a sent message",
                 "parameters": [
                     {
-                        "description": "Chat payload",
+                        "description": "Message payload",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.AddUserChat"
+                            "$ref": "#/definitions/schema.EditMessage"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "User removed"
+                    "202": {
+                        "description": "Message edited",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocMsgResponse"
+                        }
                     },
                     "401": {
                         "description": "Unauthorized",
@@ -446,7 +477,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Permission denied",
+                        "description": "permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -472,38 +503,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/msg/rename-chat": {
-            "patch": {
+        "/api/msg/export": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for renaming a group chat",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "An endpoint for downloading the full history of a chat as seen by the current user (sender usernames, timestamps, edits and attachment links), streamed as json, markdown or html",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/markdown",
+                    "text/html"
                 ],
                 "tags": [
                     "Msg"
                 ],
-                "summary": "Renaming a group chat",
+                "summary": "Export a chat",
                 "parameters": [
                     {
-                        "description": "Chat payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.RenameChat"
-                        }
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default), markdown or html",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Chat Updated",
+                    "200": {
+                        "description": "Chat transcript",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocExportChat"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id or format",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -526,12 +566,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Invalid payload",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
@@ -541,14 +575,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/msg/send-message": {
+        "/api/msg/export-email": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for sending a message to a chat",
+                "description": "An endpoint for receiving the download link of a chat transcript by email, the link expires after a day and only works while the user is still a member of the chat",
                 "consumes": [
                     "application/json"
                 ],
@@ -558,23 +592,23 @@ const docTemplate = `{
                 "tags": [
                     "Msg"
                 ],
-                "summary": "Sending of message to a chat",
+                "summary": "Email a chat export",
                 "parameters": [
                     {
-                        "description": "Message payload",
+                        "description": "Export payload",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.NewMessage"
+                            "$ref": "#/definitions/schema.ExportChat"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Message Sent",
+                    "202": {
+                        "description": "Export link sent",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocMsgResponse"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
@@ -583,6 +617,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "404": {
                         "description": "Record not found",
                         "schema": {
@@ -604,50 +644,64 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/msg/transfer-owner": {
-            "patch": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "An endpoint for transferring the ownership of a group chat to another user",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/msg/export/{id}": {
+            "get": {
+                "description": "An endpoint for downloading a chat transcript through the signed link sent by email",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/markdown",
+                    "text/html"
                 ],
                 "tags": [
                     "Msg"
                 ],
-                "summary": "Transfer group chat ownership to another user",
+                "summary": "Download an emailed chat export",
                 "parameters": [
                     {
-                        "description": "payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.AddUserChat"
-                        }
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User the link was signed for",
+                        "name": "uid",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json, markdown or html",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Expiry of the link (unix time)",
+                        "name": "exp",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature",
+                        "name": "sig",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Ownership Transferred",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                    "200": {
+                        "description": "Chat transcript",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
+                            "$ref": "#/definitions/schema.DocExportChat"
                         }
                     },
                     "403": {
-                        "description": "Permission denied",
+                        "description": "Invalid or expired link",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -658,12 +712,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Invalid payload",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
@@ -673,14 +721,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/msg/view-chats": {
-            "get": {
+        "/api/msg/join-approval": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for fetching all chats of the current user",
+                "description": "An endpoint for the admins of a group to require their approval for the users joining through an invite link",
                 "consumes": [
                     "application/json"
                 ],
@@ -690,12 +738,23 @@ const docTemplate = `{
                 "tags": [
                     "Msg"
                 ],
-                "summary": "Fetch the current user chats",
+                "summary": "Turn join approval on or off",
+                "parameters": [
+                    {
+                        "description": "Approval payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.JoinApproval"
+                        }
+                    }
+                ],
                 "responses": {
-                    "200": {
-                        "description": "User chats",
+                    "202": {
+                        "description": "Chat updated",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocViewAllChats"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
@@ -704,12 +763,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "404": {
                         "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
@@ -719,14 +790,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/msg/view-hist": {
-            "get": {
+        "/api/msg/join/{token}": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint to view all messages in a chat (the chat history)",
+                "description": "An endpoint for joining a group through an invite link, groups with join approval turned on get a join request instead for their admins to review",
                 "consumes": [
                     "application/json"
                 ],
@@ -736,25 +807,25 @@ const docTemplate = `{
                 "tags": [
                     "Msg"
                 ],
-                "summary": "View All messages in a chat",
+                "summary": "Join a group with an invite link",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Chat ID",
-                        "name": "id",
-                        "in": "query",
+                        "description": "Invite token",
+                        "name": "token",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Chat history",
+                        "description": "Chat joined",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocViewChatHistory"
+                            "$ref": "#/definitions/schema.DocJoinChat"
                         }
                     },
-                    "400": {
-                        "description": "Invalid id",
+                    "202": {
+                        "description": "Join request sent",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -771,6 +842,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "409": {
+                        "description": "Already a member",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Invite expired",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
@@ -780,14 +863,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/msg/ws/chat": {
-            "get": {
+        "/api/msg/leave-chat": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint that upgrades client to a websocket connection for real-time chatting experience",
+                "description": "An endpoint for leaving a group chat",
                 "consumes": [
                     "application/json"
                 ],
@@ -797,7 +880,7 @@ const docTemplate = `{
                 "tags": [
                     "Msg"
                 ],
-                "summary": "A websocket message hub for real-time chatting",
+                "summary": "Leave a group chat",
                 "parameters": [
                     {
                         "type": "string",
@@ -808,10 +891,13 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "101": {
-                        "description": "Switching Protocols",
+                    "204": {
+                        "description": "Chat removed"
+                    },
+                    "400": {
+                        "description": "Invalid id",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
@@ -820,14 +906,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Invalid payload",
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "500": {
-                        "description": "server error",
+                        "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -835,14 +927,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/apply": {
-            "post": {
+        "/api/msg/mark-read": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for applying to a project to work on",
+                "description": "An endpoint for moving the read cursor of the current user up to a message, the latest message of the chat when no message id is given",
                 "consumes": [
                     "application/json"
                 ],
@@ -850,32 +942,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Msg"
                 ],
-                "summary": "Apply for a project to work on",
+                "summary": "Mark a chat as read",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Project ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "description": "Application payload",
+                        "description": "Read payload",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.ProjectApplication"
+                            "$ref": "#/definitions/schema.MarkRead"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Applied successfully",
+                    "202": {
+                        "description": "Chat marked as read",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocProjectApplication"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "400": {
@@ -902,12 +987,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "409": {
-                        "description": "Existing record",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
                     "422": {
                         "description": "Invalid payload",
                         "schema": {
@@ -923,14 +1002,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/clear-application": {
-            "delete": {
+        "/api/msg/mute-chat": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for clearing all applications on a project",
+                "description": "An endpoint for muting the notifications of a chat until a time, or unmuting it with an empty until",
                 "consumes": [
                     "application/json"
                 ],
@@ -938,24 +1017,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Msg"
                 ],
-                "summary": "Clear all applications on a project",
+                "summary": "Mute a chat",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Project ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "Mute payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.MuteChat"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Applications cleared"
+                    "202": {
+                        "description": "Chat updated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
                     },
                     "400": {
-                        "description": "Invalid id",
+                        "description": "Invalid time",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -972,8 +1056,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "422": {
+                        "description": "Invalid payload",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -987,14 +1071,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/create": {
-            "post": {
+        "/api/msg/notify-level": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for creating a new project for the current user it internally calls a service to create a vector for the project",
+                "description": "An endpoint for choosing which messages of a chat send notifications, all of them, only the ones mentioning the user or none",
                 "consumes": [
                     "application/json"
                 ],
@@ -1002,25 +1086,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Msg"
                 ],
-                "summary": "Create a new project",
+                "summary": "Set the notification level of a chat",
                 "parameters": [
                     {
-                        "description": "Project payload",
+                        "description": "Level payload",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.NewProjectRequest"
+                            "$ref": "#/definitions/schema.NotifyLevel"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Project created",
+                    "202": {
+                        "description": "Chat updated",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocProjectResponse"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
@@ -1029,6 +1113,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "422": {
                         "description": "Invalid payload",
                         "schema": {
@@ -1044,14 +1134,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/delete-application": {
-            "delete": {
+        "/api/msg/open-chat": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for deleting a sent project application for the current user",
+                "description": "An endpoint for opening a chat between users with IDs, with the latest page of its history (older messages through view-hist)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1059,21 +1149,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Msg"
                 ],
-                "summary": "Delete a send project application",
+                "summary": "Open a chat between users",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Request ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Request deleted"
+                    "200": {
+                        "description": "Chat opened",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocViewChatHistory"
+                        }
                     },
                     "400": {
                         "description": "Invalid id",
@@ -1087,12 +1180,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "403": {
-                        "description": "Permission denied",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
                     "404": {
                         "description": "Record not found",
                         "schema": {
@@ -1108,14 +1195,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/edit": {
-            "put": {
+        "/api/msg/pin-chat": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for editing major details of a project it internally calls a service to update the vector for the project",
+                "description": "An endpoint for pinning a chat at a position on top of the chat list (up to 5 chats), or unpinning it with an empty position",
                 "consumes": [
                     "application/json"
                 ],
@@ -1123,36 +1210,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Msg"
                 ],
-                "summary": "Editing details of a project",
+                "summary": "Pin a chat",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Project ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "description": "Project payload",
+                        "description": "Pin payload",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.NewProjectRequest"
+                            "$ref": "#/definitions/schema.PinChat"
                         }
                     }
                 ],
                 "responses": {
                     "202": {
-                        "description": "Project Edited",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocProjectResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid id",
+                        "description": "Chat updated",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -1169,8 +1243,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "409": {
+                        "description": "Too many pinned chats",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -1190,14 +1264,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/edit-status": {
+        "/api/msg/pin-message": {
             "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for editing the availability status of a project it internally calls a service to update the vector for the project",
+                "description": "An endpoint for the admins of a group to pin a message at the top of the chat, or to remove the pinned message with an empty msg_id",
                 "consumes": [
                     "application/json"
                 ],
@@ -1205,30 +1279,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Msg"
                 ],
-                "summary": "Editing the availability of a project",
+                "summary": "Pin a message in a group",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Project ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Status",
-                        "name": "staus",
-                        "in": "query",
-                        "required": true
+                        "description": "Pin payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.PinMessage"
+                        }
                     }
                 ],
                 "responses": {
                     "202": {
-                        "description": "Project edited",
+                        "description": "Message pinned",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocProjectResponse"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "400": {
@@ -1249,6 +1318,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "422": {
                         "description": "Invalid payload",
                         "schema": {
@@ -1264,14 +1339,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/edit-view": {
-            "patch": {
+        "/api/msg/react": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for editing the number of views on a project",
+                "description": "An endpoint for reacting to a message with an emoji, a user reacts at most once with each emoji",
                 "consumes": [
                     "application/json"
                 ],
@@ -1279,27 +1354,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Msg"
                 ],
-                "summary": "Editing the number of views on a project",
+                "summary": "React to a message",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Project ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "Reaction payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.React"
+                        }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Project view edited",
+                    "201": {
+                        "description": "Reaction added",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocProjectResponse"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid id",
+                        "description": "Invalid reaction",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -1310,54 +1387,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "500": {
-                        "description": "Server error",
+                    "404": {
+                        "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/api/post/posts/all": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "An endpoint for retreiving all current user projects",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Project"
-                ],
-                "summary": "Retreive all current user projects",
-                "responses": {
-                    "200": {
-                        "description": "Fetched all projects",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocAllProjectResponse"
-                        }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "409": {
+                        "description": "Reaction already added",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "422": {
+                        "description": "Invalid payload",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -1371,14 +1420,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/recommend": {
-            "get": {
+        "/api/msg/remove-user": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for recommending users for a project using ai",
+                "description": "An endpoint for the admins of a group chat to remove a member, only the owner removes other admins",
                 "consumes": [
                     "application/json"
                 ],
@@ -1386,30 +1435,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Msg"
                 ],
-                "summary": "Recommends users to work on a project",
+                "summary": "Remove a user from a group",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Project ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "Chat payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.AddUserChat"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Users Retrieved",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocRecUsersResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid id",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
+                    "204": {
+                        "description": "User removed"
                     },
                     "401": {
                         "description": "Unauthorized",
@@ -1417,8 +1459,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "402": {
-                        "description": "Payment Required",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -1429,6 +1471,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
@@ -1438,14 +1486,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/save-post": {
-            "put": {
+        "/api/msg/rename-chat": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for adding a project to the current user bookmarks",
+                "description": "An endpoint for the admins of a group chat to rename it",
                 "consumes": [
                     "application/json"
                 ],
@@ -1453,27 +1501,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Msg"
                 ],
-                "summary": "Bookmark a project",
+                "summary": "Renaming a group chat",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Project ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "Chat payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.RenameChat"
+                        }
                     }
                 ],
                 "responses": {
                     "202": {
-                        "description": "Bookmarked project",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocProjectResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid id",
+                        "description": "Chat Updated",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -1496,6 +1540,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
@@ -1505,14 +1555,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/tags": {
-            "post": {
+        "/api/msg/review-join": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for searching for project with tags associated with the project",
+                "description": "An endpoint for the admins of a group to approve (adding the user to the group) or decline a join request",
                 "consumes": [
                     "application/json"
                 ],
@@ -1520,25 +1570,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Msg"
                 ],
-                "summary": "Search for a project with tags/skills",
+                "summary": "Approve or decline a join request",
                 "parameters": [
                     {
-                        "description": "Tags",
+                        "description": "Review payload",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.SearchProjectWithTags"
+                            "$ref": "#/definitions/schema.ReviewJoin"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Projects",
+                    "202": {
+                        "description": "Request reviewed",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocAllProjectResponse"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
@@ -1547,6 +1597,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "422": {
                         "description": "Invalid payload",
                         "schema": {
@@ -1562,14 +1624,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/update-application": {
-            "patch": {
+        "/api/msg/revoke-invite": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for updating a project application status to accepted or rejected",
+                "description": "An endpoint for the admins of a group to stop an invite link from working",
                 "consumes": [
                     "application/json"
                 ],
@@ -1577,40 +1639,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Msg"
                 ],
-                "summary": "Updating a project application status",
+                "summary": "Revoke an invite link",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Request ID",
+                        "description": "Invite ID",
                         "name": "id",
                         "in": "query",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Request status",
-                        "name": "status",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "description": "Payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.RejectApplication"
-                        }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Status Updated",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
+                    "204": {
+                        "description": "Invite revoked"
                     },
                     "400": {
                         "description": "Invalid id",
@@ -1636,12 +1679,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Invalid payload",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
@@ -1651,14 +1688,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/view": {
+        "/api/msg/search": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for viewing a single project in details by using the project ID",
+                "description": "An endpoint for searching the messages of a chat, or of every chat of the current user when no chat id is given. Supports the web search syntax (\"quoted phrases\", -excluded words, or), the best matches come first",
                 "consumes": [
                     "application/json"
                 ],
@@ -1666,27 +1703,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Msg"
                 ],
-                "summary": "View a single project with ID",
+                "summary": "Search messages",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Project ID",
-                        "name": "id",
+                        "description": "Search",
+                        "name": "q",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results, 20 by default and 50 at most",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Fetched project",
+                        "description": "Matching messages",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocDetailedProjectResponse"
+                            "$ref": "#/definitions/schema.DocSearchMessages"
                         }
                     },
                     "400": {
-                        "description": "Invalid id",
+                        "description": "Invalid search",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -1697,8 +1746,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -1712,14 +1761,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/view-application": {
-            "get": {
+        "/api/msg/send-message": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for viewing all the applications on a single project",
+                "description": "An endpoint for sending a message to a chat",
                 "consumes": [
                     "application/json"
                 ],
@@ -1727,29 +1776,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Msg"
                 ],
-                "summary": "View all applications on a project",
+                "summary": "Sending of message to a chat",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Project ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "Message payload, the files are uploaded beforehand through /api/msg/upload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.NewMessage"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Project applications",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocViewProjectApplications"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid id",
+                    "201": {
+                        "description": "Message Sent",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
+                            "$ref": "#/definitions/schema.DocMsgResponse"
                         }
                     },
                     "401": {
@@ -1770,6 +1815,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
@@ -1779,14 +1830,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/view-applications": {
-            "get": {
+        "/api/msg/set-role": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for viewing all sent and received project applications",
+                "description": "An endpoint for the owner of a group to make a member an admin or take it back",
                 "consumes": [
                     "application/json"
                 ],
@@ -1794,70 +1845,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Msg"
                 ],
-                "summary": "View all project applications sent and received",
-                "responses": {
-                    "200": {
-                        "description": "Fetched applications",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocViewAllProjectApplication"
+                "summary": "Change the role of a group member",
+                "parameters": [
+                    {
+                        "description": "Role payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.SetRole"
                         }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Role updated",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "500": {
-                        "description": "Server error",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/api/post/view/saved-post": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "An endpoint to view all of the bookmarked projects of the current user",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Project"
-                ],
-                "summary": "View all current user bookmarked projects",
-                "responses": {
-                    "200": {
-                        "description": "Bookmarked projects",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocAllProjectResponse"
-                        }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "422": {
+                        "description": "Invalid payload",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -1871,57 +1899,56 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/transc/cancel-sub": {
-            "patch": {
+        "/api/msg/sse": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for canceling a subscription",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "A fallback for the clients that can't keep a websocket open (proxies breaking the upgrade), delivering the same events as the chat socket, every data line is a schema.WSEnvelope. The stream is read only, actions go through the REST endpoints. A reconnecting client sends the id of the last event it got in the Last-Event-ID header (or the last_event_id query) and first receives the events it missed, or a resync event when they are past the replay buffer",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "Transaction"
+                    "Msg"
+                ],
+                "summary": "A server-sent events stream of the chat and personal events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of the last event received, for the clients that can't set the header",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "An endpoint for canceling a subscription",
                 "responses": {
                     "200": {
-                        "description": "Success",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                        "description": "Event stream",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
+                            "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "400": {
+                        "description": "Invalid event id",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Failed to parse payload",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "500": {
-                        "description": "Server error",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
-                    "502": {
-                        "description": "Failed communication with external service",
+                        "description": "server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -1929,14 +1956,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/transc/enable-sub": {
+        "/api/msg/transfer-owner": {
             "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for re enabling a subscription",
+                "description": "An endpoint for transferring the ownership of a group chat to another user",
                 "consumes": [
                     "application/json"
                 ],
@@ -1944,12 +1971,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Transaction"
+                    "Msg"
+                ],
+                "summary": "Transfer group chat ownership to another user",
+                "parameters": [
+                    {
+                        "description": "payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.AddUserChat"
+                        }
+                    }
                 ],
-                "summary": "An endpoint for enabling a subscription",
                 "responses": {
-                    "200": {
-                        "description": "Success",
+                    "202": {
+                        "description": "Ownership Transferred",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -1960,8 +1998,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "402": {
-                        "description": "Payment required",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -1973,7 +2011,7 @@ const docTemplate = `{
                         }
                     },
                     "422": {
-                        "description": "Failed to parse payload",
+                        "description": "Invalid payload",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -1983,24 +2021,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
-                    },
-                    "502": {
-                        "description": "Failed communication with external service",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
                     }
                 }
             }
         },
-        "/api/transc/initialize": {
-            "get": {
+        "/api/msg/unreact": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for initializing a transaction on paystack to receive a checkout url for payment",
+                "description": "An endpoint for removing the reaction of the current user with an emoji from a message",
                 "consumes": [
                     "application/json"
                 ],
@@ -2008,34 +2040,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Transaction"
+                    "Msg"
                 ],
-                "summary": "An endpoint for initializing a transaction on paystack",
+                "summary": "Remove a reaction from a message",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "amount",
-                        "name": "amount",
+                        "description": "Msg ID",
+                        "name": "id",
                         "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "plan",
-                        "name": "plan",
+                        "description": "Emoji",
+                        "name": "emoji",
                         "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Success",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocInitTranscResponse"
-                        }
+                    "204": {
+                        "description": "Reaction removed"
                     },
                     "400": {
-                        "description": "Bad Query",
+                        "description": "Invalid reaction",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2046,14 +2075,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Failed to parse payload",
+                    "404": {
+                        "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2063,52 +2092,53 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
-                    },
-                    "502": {
-                        "description": "Bad Gateway",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
                     }
                 }
             }
         },
-        "/api/transc/retry-payment": {
+        "/api/msg/upload": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for retrying a failed subscription payment",
+                "description": "An endpoint for uploading a file (images, pdf, zip or text up to 10MB) to a chat, the returned id is then sent along a message in its attachments",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Transaction"
+                    "Msg"
                 ],
-                "summary": "Retries a failed payment for subscription",
+                "summary": "Upload a file to a chat",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Sub ID",
-                        "name": "id",
-                        "in": "query",
+                        "description": "Chat ID",
+                        "name": "chat_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Success",
+                    "201": {
+                        "description": "File uploaded",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
+                            "$ref": "#/definitions/schema.DocAttachment"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid file",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2119,20 +2149,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "402": {
-                        "description": "Payment required",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocMsgResResponse"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "413": {
+                        "description": "File too large",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Failed to parse payload / response",
+                    "415": {
+                        "description": "Unsupported file type",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2142,24 +2172,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
-                    },
-                    "502": {
-                        "description": "External server error",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
                     }
                 }
             }
         },
-        "/api/transc/update-card": {
+        "/api/msg/view-chats": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "This is an endpoint for retrieving link for updating card details used for transaction on paystack",
+                "description": "An endpoint for fetching the chats of the current user, the pinned ones first by their position then the most recently active, archived chats are only listed with archived=true",
                 "consumes": [
                     "application/json"
                 ],
@@ -2167,14 +2191,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Transaction"
+                    "Msg"
+                ],
+                "summary": "Fetch the current user chats",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "List the archived chats instead",
+                        "name": "archived",
+                        "in": "query"
+                    }
                 ],
-                "summary": "This is an endpoint for udpating card details on paystack",
                 "responses": {
                     "200": {
-                        "description": "Link Generated",
+                        "description": "User chats",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocUpdateCardSub"
+                            "$ref": "#/definitions/schema.DocViewAllChats"
                         }
                     },
                     "401": {
@@ -2189,35 +2221,23 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Failed to parse payload",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
-                    },
-                    "502": {
-                        "description": "Failed communication with external service",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
                     }
                 }
             }
         },
-        "/api/transc/view": {
+        "/api/msg/view-edits": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint that retrieves all the transactions of the current user",
+                "description": "An endpoint to view the previous texts of a message along with the current one, oldest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -2225,14 +2245,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Transaction"
+                    "Msg"
+                ],
+                "summary": "View the edit history of a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Msg ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "Retrieves the transactions for a user",
                 "responses": {
                     "200": {
-                        "description": "Transactions retrieved",
+                        "description": "Edit history",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocTranscResposne"
+                            "$ref": "#/definitions/schema.DocViewEdits"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
@@ -2241,6 +2276,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "404": {
                         "description": "Record not found",
                         "schema": {
@@ -2256,14 +2297,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/transc/view/plans": {
+        "/api/msg/view-hist": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint to view available plans",
+                "description": "An endpoint to view a page of the chat history, oldest first. Without a cursor the page holds the latest messages, with before (or after) the messages right before (or after) that message",
                 "consumes": [
                     "application/json"
                 ],
@@ -2271,105 +2312,63 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Transaction"
+                    "Msg"
                 ],
-                "summary": "An endpoint to view available plans",
-                "responses": {
-                    "200": {
-                        "description": "Success",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocViewPlansResponse"
-                        }
+                "summary": "View the messages in a chat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Msg ID to read the history before",
+                        "name": "before",
+                        "in": "query"
                     },
-                    "422": {
-                        "description": "Failed to parse payload",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Msg ID to read the history after",
+                        "name": "after",
+                        "in": "query"
                     },
-                    "502": {
-                        "description": "Bad Gateway",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
+                    {
+                        "type": "integer",
+                        "description": "Page size, 50 by default and 100 at most",
+                        "name": "limit",
+                        "in": "query"
                     }
-                }
-            }
-        },
-        "/api/transc/webhook": {
-            "post": {
-                "description": "An endpoint for intercepting the paystack webhooks transaction events",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Transaction"
                 ],
-                "summary": "This is a webhook for the paystack transaction events",
                 "responses": {
                     "200": {
-                        "description": "Success",
+                        "description": "Chat history",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
+                            "$ref": "#/definitions/schema.DocViewChatHistory"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Invalid id",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Failed to parse payload",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "500": {
-                        "description": "Server error",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/api/user/connect-github": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "An redirecting endpoint for connecting a github account to the current user account",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Auth"
-                ],
-                "summary": "Connecting github account to user account",
-                "responses": {
-                    "307": {
-                        "description": "Redirecting",
-                        "schema": {
-                            "type": "string"
-                        }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2383,14 +2382,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/delete-friend-req": {
-            "delete": {
+        "/api/msg/view-invites": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for deleting a sent friend request",
+                "description": "An endpoint for the admins of a group to view its invite links that weren't revoked, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -2398,21 +2397,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Msg"
                 ],
-                "summary": "Delete a sent friend req",
+                "summary": "View the invite links of a group",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Request id",
+                        "description": "Chat ID",
                         "name": "id",
                         "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Request deleted"
+                    "200": {
+                        "description": "Invite links",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocViewInvites"
+                        }
                     },
                     "400": {
                         "description": "Invalid id",
@@ -2421,13 +2423,13 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized user",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "403": {
-                        "description": "Permission Denied",
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2447,14 +2449,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/delete-skills": {
-            "delete": {
+        "/api/msg/view-join-requests": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint to delete some skills from the current user skills it internally calls a service to update the user vector",
+                "description": "An endpoint for the admins of a group to view the users waiting for approval, oldest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -2462,75 +2464,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Msg"
                 ],
-                "summary": "Delete skills from the user skills",
+                "summary": "View the join requests of a group",
                 "parameters": [
                     {
-                        "description": "Skills",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.DeleteUserSkillsRequest"
-                        }
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Skills Deleted"
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Record not found",
+                    "200": {
+                        "description": "Join requests",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
+                            "$ref": "#/definitions/schema.DocViewJoinRequests"
                         }
                     },
-                    "422": {
-                        "description": "Invalid payload",
+                    "400": {
+                        "description": "Invalid id",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "500": {
-                        "description": "server error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/api/user/delete-user": {
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "An endpoint for deleting the current user account it internally calls a service to delete the vector of the user",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "User"
-                ],
-                "summary": "Delete a user account",
-                "responses": {
-                    "204": {
-                        "description": "Account deleted"
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2542,7 +2508,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "server error",
+                        "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2550,14 +2516,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/delete-user-friend": {
-            "delete": {
+        "/api/msg/view-members": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for deleting a friend from the users friend list",
+                "description": "An endpoint to view the members of a chat along with their role",
                 "consumes": [
                     "application/json"
                 ],
@@ -2565,28 +2531,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Msg"
                 ],
-                "summary": "Delete a existing friendship",
+                "summary": "View the members of a chat",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User id",
+                        "description": "Chat ID",
                         "name": "id",
                         "in": "query",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Chat id",
-                        "name": "chat_id",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Friend deleted"
+                    "200": {
+                        "description": "Chat members",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocViewMembers"
+                        }
                     },
                     "400": {
                         "description": "Invalid id",
@@ -2595,13 +2557,13 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized user",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2615,14 +2577,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/get-user": {
+        "/api/msg/view-thread": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for fetching a user by their ID",
+                "description": "An endpoint to view a message along with all the replies to it, oldest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -2630,13 +2592,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Msg"
                 ],
-                "summary": "Get a user by their ID",
+                "summary": "View the thread of a message",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Msg ID",
                         "name": "id",
                         "in": "query",
                         "required": true
@@ -2644,19 +2606,25 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "user and projects fetched",
+                        "description": "Message thread",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocProjectUserResponse"
+                            "$ref": "#/definitions/schema.DocViewThread"
                         }
                     },
                     "400": {
-                        "description": "Invalid user id",
+                        "description": "Invalid id",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized user",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2676,14 +2644,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/payment-info": {
+        "/api/msg/ws/chat": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for retreiving a user saved payment info used for subscription",
+                "description": "An endpoint that upgrades client to a single websocket connection per user, subscribed to all of the user's chats. Chats can be unsubscribed from and subscribed to again over the socket, every frame is a schema.WSEnvelope (see the event types in schema/ws.go)",
                 "consumes": [
                     "application/json"
                 ],
@@ -2691,14 +2659,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Msg"
                 ],
-                "summary": "Retreives a user saved payment info used for subscription",
+                "summary": "A websocket hub for real-time chatting and personal events",
                 "responses": {
-                    "200": {
-                        "description": "Success",
+                    "101": {
+                        "description": "Switching Protocols",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocViewPaymentInfo"
+                            "type": "string"
                         }
                     },
                     "401": {
@@ -2707,14 +2675,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Server error",
+                    "500": {
+                        "description": "server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2722,14 +2684,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/profile": {
-            "get": {
+        "/api/post/apply": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for fetching the currently logged in user profile details",
+                "description": "An endpoint for applying to a project to work on",
                 "consumes": [
                     "application/json"
                 ],
@@ -2737,18 +2699,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Project"
+                ],
+                "summary": "Apply for a project to work on",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Application payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.ProjectApplication"
+                        }
+                    }
                 ],
-                "summary": "Get the logged in user info",
                 "responses": {
                     "200": {
-                        "description": "user fetched",
+                        "description": "Applied successfully",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocUserResponse"
+                            "$ref": "#/definitions/schema.DocProjectApplication"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized user",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2759,6 +2751,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "409": {
+                        "description": "Existing record",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
@@ -2768,14 +2772,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/recommend": {
-            "get": {
+        "/api/post/clear-application": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for recommending projects for a user to work on using ai",
+                "description": "An endpoint for clearing all applications on a project",
                 "consumes": [
                     "application/json"
                 ],
@@ -2783,14 +2787,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Project"
+                ],
+                "summary": "Clear all applications on a project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "Recommends projects for a user to work on",
                 "responses": {
-                    "200": {
-                        "description": "Projects Retreived",
+                    "204": {
+                        "description": "Applications cleared"
+                    },
+                    "400": {
+                        "description": "Invalid id",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocRecProjectsResponse"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
@@ -2799,8 +2815,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "402": {
-                        "description": "Payment Required",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2820,14 +2836,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/reset-password": {
-            "patch": {
+        "/api/post/create": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for reseting the user password with a reset jwt token gotten from the verify-otp route",
+                "description": "An endpoint for creating a new project for the current user it internally calls a service to create a vector for the project",
                 "consumes": [
                     "application/json"
                 ],
@@ -2835,25 +2851,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Project"
                 ],
-                "summary": "Reset user password through forgot password route",
+                "summary": "Create a new project",
                 "parameters": [
                     {
-                        "description": "new password",
+                        "description": "Project payload",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.ResetPassword"
+                            "$ref": "#/definitions/schema.NewProjectRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "reset successful",
+                    "201": {
+                        "description": "Project created",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
+                            "$ref": "#/definitions/schema.DocProjectResponse"
                         }
                     },
                     "401": {
@@ -2862,20 +2878,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
                     "422": {
-                        "description": "Invalid password",
+                        "description": "Invalid payload",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "500": {
-                        "description": "server error",
+                        "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2883,14 +2893,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/search": {
-            "post": {
+        "/api/post/delete-application": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for searching for users with their skills to show their profiles",
+                "description": "An endpoint for deleting a sent project application for the current user",
                 "consumes": [
                     "application/json"
                 ],
@@ -2898,35 +2908,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Project"
                 ],
-                "summary": "Search for users by skills/tags",
+                "summary": "Delete a send project application",
                 "parameters": [
                     {
-                        "description": "Skills",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.SearchUserbySkills"
-                        }
+                        "type": "string",
+                        "description": "Request ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "users fetched",
+                    "204": {
+                        "description": "Request deleted"
+                    },
+                    "400": {
+                        "description": "Invalid id",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocUsersSearch"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized user",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Failed to parse payload",
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -2940,14 +2957,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/send-user-req": {
-            "post": {
+        "/api/post/edit": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for sending friend requests to users for connecting",
+                "description": "An endpoint for editing major details of a project it internally calls a service to update the vector for the project",
                 "consumes": [
                     "application/json"
                 ],
@@ -2955,47 +2972,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Project"
                 ],
-                "summary": "Send a Friend req to a user",
+                "summary": "Editing details of a project",
                 "parameters": [
                     {
-                        "description": "Request",
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Project payload",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.SendFriendReq"
+                            "$ref": "#/definitions/schema.NewProjectRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Request sent",
+                    "202": {
+                        "description": "Project Edited",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocFriendReqStatus"
+                            "$ref": "#/definitions/schema.DocProjectResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized user",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "409": {
-                        "description": "Existing Friend Req / Friend",
+                    "404": {
+                        "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "422": {
-                        "description": "Failed to parse payload",
+                        "description": "Invalid payload",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3009,14 +3039,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/update-availability/{status}": {
+        "/api/post/edit-status": {
             "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for updating the Availability status of the current user to either true or false it internally calls a service to update the user vector payload",
+                "description": "An endpoint for editing the availability status of a project it internally calls a service to update the vector for the project",
                 "consumes": [
                     "application/json"
                 ],
@@ -3024,21 +3054,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Project"
                 ],
-                "summary": "Update Availability status",
+                "summary": "Editing the availability of a project",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Availability status",
-                        "name": "status",
-                        "in": "path",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status",
+                        "name": "staus",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "202": {
-                        "description": "Status updated",
+                        "description": "Project edited",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocProjectResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3049,8 +3092,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3062,7 +3105,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "server error",
+                        "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3070,14 +3113,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/update-bio": {
+        "/api/post/edit-view": {
             "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for updating the logged-in user bio information it internally calls a service to update the vector for the user.",
+                "description": "An endpoint for editing the number of views on a project",
                 "consumes": [
                     "application/json"
                 ],
@@ -3085,23 +3128,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Project"
                 ],
-                "summary": "Update the current user bio",
+                "summary": "Editing the number of views on a project",
                 "parameters": [
                     {
-                        "description": "new details",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.UpdateUserBio"
-                        }
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "202": {
-                        "description": "User updated",
+                        "description": "Project view edited",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocProjectResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3118,12 +3165,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Invalid payload",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
@@ -3133,14 +3174,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/update-interest": {
-            "patch": {
+        "/api/post/posts/all": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for updating the logged-in user interests it internally calls a service to update the vector for the user.",
+                "description": "An endpoint for retreiving all current user projects",
                 "consumes": [
                     "application/json"
                 ],
@@ -3148,25 +3189,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
-                ],
-                "summary": "Update the current user interests",
-                "parameters": [
-                    {
-                        "description": "new details",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.UpdateUserInterests"
-                        }
-                    }
+                    "Project"
                 ],
+                "summary": "Retreive all current user projects",
                 "responses": {
-                    "202": {
-                        "description": "User updated",
+                    "200": {
+                        "description": "Fetched all projects",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
+                            "$ref": "#/definitions/schema.DocAllProjectResponse"
                         }
                     },
                     "401": {
@@ -3181,12 +3211,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Invalid payload",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
@@ -3196,14 +3220,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/update-password": {
-            "patch": {
+        "/api/post/recommend": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for updating the logged-in user password",
+                "description": "An endpoint for recommending users for a project using ai",
                 "consumes": [
                     "application/json"
                 ],
@@ -3211,23 +3235,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Project"
                 ],
-                "summary": "Update the current user password",
+                "summary": "Recommends users to work on a project",
                 "parameters": [
                     {
-                        "description": "new password",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.UpdatePassword"
-                        }
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "password updated",
+                    "200": {
+                        "description": "Users Retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocRecUsersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3238,20 +3266,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "402": {
+                        "description": "Payment Required",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Invalid payload",
+                    "404": {
+                        "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "500": {
-                        "description": "server error",
+                        "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3259,14 +3287,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/update-profile": {
+        "/api/post/save-post": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for updating the logged-in user profile details",
+                "description": "An endpoint for adding a project to the current user bookmarks",
                 "consumes": [
                     "application/json"
                 ],
@@ -3274,47 +3302,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Project"
                 ],
-                "summary": "Update the current user profile details",
+                "summary": "Bookmark a project",
                 "parameters": [
                     {
-                        "description": "new details",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.UserProfileRequest"
-                        }
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "202": {
-                        "description": "User updated",
+                        "description": "Bookmarked project",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocUserResponse"
+                            "$ref": "#/definitions/schema.DocProjectResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Invalid id",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "409": {
-                        "description": "Existing username / email",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Invalid payload",
+                    "404": {
+                        "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3328,14 +3354,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/update-skills": {
-            "patch": {
+        "/api/post/tags": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for updating the skills of the current user it internally calls a service to update the user vector",
+                "description": "An endpoint for searching for project with tags associated with the project",
                 "consumes": [
                     "application/json"
                 ],
@@ -3343,25 +3369,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Project"
                 ],
-                "summary": "Update User skills",
+                "summary": "Search for a project with tags/skills",
                 "parameters": [
                     {
-                        "description": "Skills",
+                        "description": "Tags",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.UpdateUserSkillsRequest"
+                            "$ref": "#/definitions/schema.SearchProjectWithTags"
                         }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Skills updated",
+                    "200": {
+                        "description": "Projects",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocSkillsResponse"
+                            "$ref": "#/definitions/schema.DocAllProjectResponse"
                         }
                     },
                     "401": {
@@ -3370,12 +3396,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "record not found",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
                     "422": {
                         "description": "Invalid payload",
                         "schema": {
@@ -3383,7 +3403,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "server error",
+                        "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3391,14 +3411,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/update-user-req": {
+        "/api/post/update-application": {
             "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for updating the status of received friend req to rejected / accepted",
+                "description": "An endpoint for updating a project application status to accepted or rejected",
                 "consumes": [
                     "application/json"
                 ],
@@ -3406,9 +3426,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Project"
                 ],
-                "summary": "Update a friend req status",
+                "summary": "Updating a project application status",
                 "parameters": [
                     {
                         "type": "string",
@@ -3416,29 +3436,45 @@ const docTemplate = `{
                         "name": "id",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Request status",
+                        "name": "status",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.RejectApplication"
+                        }
                     }
                 ],
                 "responses": {
                     "202": {
-                        "description": "Request status updated",
+                        "description": "Status Updated",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocFriendReqAccept"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid status / id",
+                        "description": "Invalid id",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized user",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "403": {
-                        "description": "Permission Denied",
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3449,6 +3485,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
@@ -3458,14 +3500,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/view": {
+        "/api/post/view": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for searching for a user with their username to show their projects and profile",
+                "description": "An endpoint for viewing a single project in details by using the project ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -3473,13 +3515,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Project"
                 ],
-                "summary": "Search for user with their username",
+                "summary": "View a single project with ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Username",
+                        "description": "Project ID",
                         "name": "id",
                         "in": "query",
                         "required": true
@@ -3487,19 +3529,19 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "user and projects fetched",
+                        "description": "Fetched project",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocProjectUserResponse"
+                            "$ref": "#/definitions/schema.DocDetailedProjectResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid username",
+                        "description": "Invalid id",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized user",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3519,14 +3561,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/view-friend": {
+        "/api/post/view-application": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for viewing all the friends for the currently logged in user",
+                "description": "An endpoint for viewing all the applications on a single project",
                 "consumes": [
                     "application/json"
                 ],
@@ -3534,30 +3576,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Project"
+                ],
+                "summary": "View all applications on a project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "View all friends for the logged in user",
                 "responses": {
                     "200": {
-                        "description": "User friends fetched",
+                        "description": "Project applications",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocViewFriends"
+                            "$ref": "#/definitions/schema.DocViewProjectApplications"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized user",
+                    "400": {
+                        "description": "Invalid id",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "500": {
-                        "description": "Server error",
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3565,14 +3628,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/view-git": {
+        "/api/post/view-applications": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for searching for a user with their git username to show their projects and profile",
+                "description": "An endpoint for viewing all sent and received project applications",
                 "consumes": [
                     "application/json"
                 ],
@@ -3580,33 +3643,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
-                ],
-                "summary": "Search for user with their git username",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Git Username",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
-                    }
+                    "Project"
                 ],
+                "summary": "View all project applications sent and received",
                 "responses": {
                     "200": {
-                        "description": "user and projects fetched",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocProjectUserResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid git username",
+                        "description": "Fetched applications",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
+                            "$ref": "#/definitions/schema.DocViewAllProjectApplication"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized user",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3626,14 +3674,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/view-repo": {
+        "/api/post/view/saved-post": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for viewing the user public repo to tag to a project.",
+                "description": "An endpoint to view all of the bookmarked projects of the current user",
                 "consumes": [
                     "application/json"
                 ],
@@ -3641,14 +3689,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Git"
+                    "Project"
                 ],
-                "summary": "View Github public repo for current user",
+                "summary": "View all current user bookmarked projects",
                 "responses": {
                     "200": {
-                        "description": "Repo fetched",
+                        "description": "Bookmarked projects",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocViewRepos"
+                            "$ref": "#/definitions/schema.DocAllProjectResponse"
                         }
                     },
                     "401": {
@@ -3663,35 +3711,23 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Invalid payload",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
-                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
-                    },
-                    "502": {
-                        "description": "Bad Gateway",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
-                        }
                     }
                 }
             }
         },
-        "/api/user/view-subs": {
-            "get": {
+        "/api/transc/cancel-sub": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for viewing the history of a user's subsciption",
+                "description": "An endpoint for canceling a subscription",
                 "consumes": [
                     "application/json"
                 ],
@@ -3699,14 +3735,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Transaction"
                 ],
-                "summary": "Views a user subscription history",
+                "summary": "An endpoint for canceling a subscription",
                 "responses": {
                     "200": {
-                        "description": "Subscriptions",
+                        "description": "Success",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocViewSubscriptions"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
@@ -3721,23 +3757,35 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "422": {
+                        "description": "Failed to parse payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
+                    },
+                    "502": {
+                        "description": "Failed communication with external service",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/user/view-user-friend": {
+        "/api/transc/initialize": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for viewing all the friends of a user",
+                "description": "An endpoint for initializing a transaction on paystack to receive a checkout url for payment",
                 "consumes": [
                     "application/json"
                 ],
@@ -3745,33 +3793,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Transaction"
                 ],
-                "summary": "View all friends of a user",
+                "summary": "An endpoint for initializing a transaction on paystack",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
-                        "name": "id",
+                        "description": "amount",
+                        "name": "amount",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "plan",
+                        "name": "plan",
                         "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User friends fetched",
+                        "description": "Success",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocViewFriends"
+                            "$ref": "#/definitions/schema.DocInitTranscResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid id",
+                        "description": "Bad Query",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized user",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3782,23 +3837,35 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "422": {
+                        "description": "Failed to parse payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/user/view-user-req": {
-            "get": {
+        "/api/transc/retry-payment": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "An endpoint for viewing all the looged in users friend reqs (sent and received)",
+                "description": "An endpoint for retrying a failed subscription payment",
                 "consumes": [
                     "application/json"
                 ],
@@ -3806,40 +3873,78 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Transaction"
+                ],
+                "summary": "Retries a failed payment for subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sub ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "View All friend reqs",
                 "responses": {
                     "200": {
-                        "description": "Fetched requests",
+                        "description": "Success",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocViewFriendReqs"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized user",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "402": {
+                        "description": "Payment required",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocMsgResResponse"
+                        }
+                    },
                     "404": {
                         "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "422": {
+                        "description": "Failed to parse payload / response",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
+                    },
+                    "502": {
+                        "description": "External server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/v1/auth/github/callback": {
+        "/api/transc/update-card": {
             "get": {
-                "description": "An endpoint for selecting the callback for login, connect github endpoint",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "This is an endpoint for retrieving link for updating card details used for transaction on paystack",
                 "consumes": [
                     "application/json"
                 ],
@@ -3847,36 +3952,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Transaction"
                 ],
-                "summary": "Callback for the github sign-in / connect",
+                "summary": "This is an endpoint for udpating card details on paystack",
                 "responses": {
                     "200": {
-                        "description": "Token generated",
-                        "schema": {
-                            "$ref": "#/definitions/schema.DocTokenResponse"
-                        }
-                    },
-                    "202": {
-                        "description": "Github Connected",
+                        "description": "Link Generated",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
+                            "$ref": "#/definitions/schema.DocUpdateCardSub"
                         }
                     },
-                    "400": {
-                        "description": "Invalid id",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "409": {
-                        "description": "Existing record",
+                    "422": {
+                        "description": "Failed to parse payload",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3888,7 +3987,7 @@ const docTemplate = `{
                         }
                     },
                     "502": {
-                        "description": "Bad Gateway",
+                        "description": "Failed communication with external service",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3896,9 +3995,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/forgot-password": {
-            "post": {
-                "description": "An endpoint for getting an otp for reseting the user password",
+        "/api/transc/view": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint that retrieves all the transactions of the current user",
                 "consumes": [
                     "application/json"
                 ],
@@ -3906,35 +4010,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
-                ],
-                "summary": "Get a OTP for reseting user password",
-                "parameters": [
-                    {
-                        "description": "User email",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.ForgotPasswordEmail"
-                        }
-                    }
+                    "Transaction"
                 ],
+                "summary": "Retrieves the transactions for a user",
                 "responses": {
                     "200": {
-                        "description": "Email sent to user",
+                        "description": "Transactions retrieved",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocNormalResponse"
+                            "$ref": "#/definitions/schema.DocTranscResposne"
                         }
                     },
-                    "404": {
-                        "description": "Record not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Invalid Email",
+                    "404": {
+                        "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3948,9 +4041,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/github-signup": {
+        "/api/transc/view/plans": {
             "get": {
-                "description": "A redirecting endpoint for sign-in / sign-up with github it calls a service internally to create a vector for new users",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint to view available plans",
                 "consumes": [
                     "application/json"
                 ],
@@ -3958,18 +4056,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Transaction"
                 ],
-                "summary": "Signing up user using github",
+                "summary": "An endpoint to view available plans",
                 "responses": {
-                    "307": {
-                        "description": "Redirecting",
+                    "200": {
+                        "description": "Success",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/schema.DocViewPlansResponse"
                         }
                     },
-                    "500": {
-                        "description": "Server error",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Failed to parse payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -3977,9 +4087,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/health/detailed": {
-            "get": {
-                "description": "This gives a detailed health status of the running services",
+        "/api/transc/webhook": {
+            "post": {
+                "description": "An endpoint for intercepting the paystack webhooks transaction events",
                 "consumes": [
                     "application/json"
                 ],
@@ -3987,30 +4097,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "Transaction"
                 ],
-                "summary": "Checks the health of the services running",
+                "summary": "This is a webhook for the paystack transaction events",
                 "responses": {
                     "200": {
-                        "description": "Service Healthy",
+                        "description": "Success",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "503": {
-                        "description": "Service Degraded",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Failed to parse payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     }
                 }
             }
         },
-        "/login": {
+        "/api/user/connect-github": {
             "post": {
-                "description": "Log in endpoint for existing users",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An redirecting endpoint for connecting a github account to the current user account",
                 "consumes": [
                     "application/json"
                 ],
@@ -4020,23 +4145,81 @@ const docTemplate = `{
                 "tags": [
                     "Auth"
                 ],
-                "summary": "Log in a user",
+                "summary": "Connecting github account to user account",
+                "responses": {
+                    "307": {
+                        "description": "Redirecting",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/create-webhook": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for sending events to an url of the current user, or of one of their projects with project_id. The url must resolve to a public address, deliveries to internal addresses and redirects are refused. Events: application.received, application.accepted, project.updated and message.mention (not for project webhooks). Deliveries are signed in X-FindMe-Signature with sha256= and the hex HMAC-SHA256 of \"\u003cX-FindMe-Timestamp\u003e.\u003cbody\u003e\" using the secret, which is generated when none is given and only shown here",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Create a webhook",
                 "parameters": [
                     {
-                        "description": "User login payload",
+                        "description": "Webhook",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.LoginRequest"
+                            "$ref": "#/definitions/schema.CreateWebhook"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "jwt token generated",
+                    "201": {
+                        "description": "Webhook created",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocTokenResponse"
+                            "$ref": "#/definitions/schema.DocWebhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "404": {
@@ -4045,8 +4228,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
+                    "409": {
+                        "description": "Too many webhooks",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
                     "422": {
-                        "description": "Failed to parse payload",
+                        "description": "Invalid payload",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -4060,9 +4249,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/signup": {
-            "post": {
-                "description": "Sign up endpoint for new users it internally calls a service to create a vector for the user",
+        "/api/user/delete-friend-req": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for deleting a sent friend request",
                 "consumes": [
                     "application/json"
                 ],
@@ -4070,35 +4264,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "User"
                 ],
-                "summary": "Register a new user",
+                "summary": "Delete a sent friend req",
                 "parameters": [
                     {
-                        "description": "User signup payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.SignupRequest"
-                        }
+                        "type": "string",
+                        "description": "Request id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "jwt token generated",
+                    "204": {
+                        "description": "Request deleted"
+                    },
+                    "400": {
+                        "description": "Invalid id",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocTokenResponse"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "409": {
-                        "description": "Existing email or username",
+                    "401": {
+                        "description": "Unauthorized user",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "422": {
-                        "description": "Failed to parse payload",
+                    "403": {
+                        "description": "Permission Denied",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
@@ -4112,9 +4313,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/verify-otp": {
-            "get": {
-                "description": "An endpoint to verify sent otp to create reset jwt token for reseting user password",
+        "/api/user/delete-notification": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for removing a notification from the notification center of the current user",
                 "consumes": [
                     "application/json"
                 ],
@@ -4124,77 +4330,2966 @@ const docTemplate = `{
                 "tags": [
                     "User"
                 ],
-                "summary": "Verify Sent otp to reset password",
+                "summary": "Delete a notification",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "otp",
+                        "description": "Notification ID",
                         "name": "id",
                         "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "reset token",
+                    "204": {
+                        "description": "Notification deleted"
+                    },
+                    "400": {
+                        "description": "Invalid id",
                         "schema": {
-                            "$ref": "#/definitions/schema.DocTokenResponse"
+                            "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
-                    "400": {
-                        "description": "invalid otp",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "404": {
-                        "description": "invalid otp",
+                        "description": "Record not found",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     },
                     "500": {
-                        "description": "server error",
+                        "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/schema.DocNormalResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "schema.AddUserChat": {
-            "type": "object",
-            "required": [
-                "chat_id",
-                "user_id"
-            ],
-            "properties": {
-                "chat_id": {
-                    "type": "string"
-                },
-                "user_id": {
-                    "type": "string"
-                }
-            }
         },
-        "schema.ApplicationProjectResponse": {
-            "type": "object",
-            "properties": {
-                "applications": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.ViewProjectApplication"
+        "/api/user/delete-skills": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
                     }
-                }
-            }
-        },
+                ],
+                "description": "An endpoint to delete some skills from the current user skills it internally calls a service to update the user vector",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Delete skills from the user skills",
+                "parameters": [
+                    {
+                        "description": "Skills",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.DeleteUserSkillsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Skills Deleted"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/delete-user": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for deleting the current user account it internally calls a service to delete the vector of the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Delete a user account",
+                "responses": {
+                    "204": {
+                        "description": "Account deleted"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/delete-user-friend": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for deleting a friend from the users friend list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Delete a existing friendship",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chat id",
+                        "name": "chat_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Friend deleted"
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized user",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/delete-webhook": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for deleting a webhook of the current user along with its delivery log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Webhook deleted"
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/get-user": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for fetching a user by their ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get a user by their ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "user and projects fetched",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocProjectUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized user",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/payment-info": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for retreiving a user saved payment info used for subscription",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Retreives a user saved payment info used for subscription",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocViewPaymentInfo"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for fetching the currently logged in user profile details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get the logged in user info",
+                "responses": {
+                    "200": {
+                        "description": "user fetched",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocUserResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized user",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/read-all-notifications": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for marking all the notifications of the current user as read",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Mark every notification as read",
+                "responses": {
+                    "202": {
+                        "description": "Notifications read",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/read-notification": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for marking a notification of the current user as read",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Mark a notification as read",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Notification read",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/recommend": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for recommending projects for a user to work on using ai",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Recommends projects for a user to work on",
+                "responses": {
+                    "200": {
+                        "description": "Projects Retreived",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocRecProjectsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/redeliver-webhook": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for sending the event of a delivery again to its webhook, as a new delivery sharing the event id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Redeliver a webhook event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Redelivery queued",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocWebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Webhook disabled",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/reset-password": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for reseting the user password with a reset jwt token gotten from the verify-otp route",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Reset user password through forgot password route",
+                "parameters": [
+                    {
+                        "description": "new password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.ResetPassword"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "reset successful",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid password",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/search": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for searching for users with their skills to show their profiles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Search for users by skills/tags",
+                "parameters": [
+                    {
+                        "description": "Skills",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.SearchUserbySkills"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "users fetched",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocUsersSearch"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized user",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Failed to parse payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/send-user-req": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for sending friend requests to users for connecting",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Send a Friend req to a user",
+                "parameters": [
+                    {
+                        "description": "Request",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.SendFriendReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request sent",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocFriendReqStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized user",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Existing Friend Req / Friend",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Failed to parse payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/unread-notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for the badge of the notification center, the number of notifications the current user hasn't read",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Count the unread notifications",
+                "responses": {
+                    "200": {
+                        "description": "Unread count",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocUnreadNotifications"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/update-availability/{status}": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for updating the Availability status of the current user to either true or false it internally calls a service to update the user vector payload",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update Availability status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Availability status",
+                        "name": "status",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Status updated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/update-bio": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for updating the logged-in user bio information it internally calls a service to update the vector for the user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update the current user bio",
+                "parameters": [
+                    {
+                        "description": "new details",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.UpdateUserBio"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "User updated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/update-digest/{frequency}": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for choosing how often the current user gets an email of the messages they missed while offline: instant (once a message stays unread for a few minutes), hourly, daily or off",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Choose how often the message digests come",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "instant, hourly, daily or off",
+                        "name": "frequency",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Frequency updated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/update-interest": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for updating the logged-in user interests it internally calls a service to update the vector for the user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update the current user interests",
+                "parameters": [
+                    {
+                        "description": "new details",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.UpdateUserInterests"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "User updated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/update-notification-preference": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for turning the channels of a notification category on or off for the current user, the channels left out keep their value. Categories: friend_requests, applications, subscription and trial (email, in_app), mentions (email, digest) and messages (digest)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update a notification preference",
+                "parameters": [
+                    {
+                        "description": "Preference",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.UpdateNotificationPreference"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Preference updated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.ViewNotificationPreference"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/update-password": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for updating the logged-in user password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update the current user password",
+                "parameters": [
+                    {
+                        "description": "new password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.UpdatePassword"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "password updated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/update-presence-visibility/{visible}": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for choosing whether friends can see the online status and last seen time of the current user, hidden users always look offline",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Show or hide the online status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Presence visibility",
+                        "name": "visible",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Visibility updated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/update-profile": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for updating the logged-in user profile details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update the current user profile details",
+                "parameters": [
+                    {
+                        "description": "new details",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.UserProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "User updated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocUserResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Existing username / email",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/update-skills": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for updating the skills of the current user it internally calls a service to update the user vector",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update User skills",
+                "parameters": [
+                    {
+                        "description": "Skills",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.UpdateUserSkillsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Skills updated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocSkillsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/update-user-req": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for updating the status of received friend req to rejected / accepted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update a friend req status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Request ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Request status updated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocFriendReqAccept"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status / id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized user",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission Denied",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/update-webhook": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for changing the url or events of a webhook of the current user, or turning it on or off. Turning a webhook disabled after repeated failures back on clears its failures",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.UpdateWebhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Webhook updated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocWebhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/view": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for searching for a user with their username to show their projects and profile",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Search for user with their username",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "user and projects fetched",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocProjectUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid username",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized user",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/view-friend": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for viewing all the friends for the currently logged in user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "View all friends for the logged in user",
+                "responses": {
+                    "200": {
+                        "description": "User friends fetched",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocViewFriends"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized user",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/view-git": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for searching for a user with their git username to show their projects and profile",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Search for user with their git username",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Git Username",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "user and projects fetched",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocProjectUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid git username",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized user",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/view-notification-preferences": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint to view the channels (email, in_app, digest) the current user gets each notification category on. Password resets and payment failures aren't listed as they always go out",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "View the notification preferences",
+                "responses": {
+                    "200": {
+                        "description": "Preferences",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNotificationPreferences"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/view-notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint to view a page of the notifications of the current user (friend requests, application decisions, subscription events and trial reminders), newest first, along with the unread count",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "View the notification center",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Notification ID to read the notifications before",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 20 by default and 50 at most",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Notifications",
+                        "schema": {
+                            "$ref": "#/definitions/schema.ViewNotifications"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/view-repo": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for viewing the user public repo to tag to a project.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Git"
+                ],
+                "summary": "View Github public repo for current user",
+                "responses": {
+                    "200": {
+                        "description": "Repo fetched",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocViewRepos"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/view-subs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for viewing the history of a user's subsciption",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Views a user subscription history",
+                "responses": {
+                    "200": {
+                        "description": "Subscriptions",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocViewSubscriptions"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/view-user-friend": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for viewing all the friends of a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "View all friends of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User friends fetched",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocViewFriends"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized user",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/view-user-req": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint for viewing all the looged in users friend reqs (sent and received)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "View All friend reqs",
+                "responses": {
+                    "200": {
+                        "description": "Fetched requests",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocViewFriendReqs"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized user",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/view-webhook-deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint to view the latest deliveries of a webhook of the current user, newest first, with their status, attempts and the response code of the last attempt",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "View the delivery log of a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of deliveries, 20 by default and 100 at most",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deliveries",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocWebhookDeliveries"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/view-webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An endpoint to view the webhooks of the current user and of their projects, without their secrets",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "View the webhooks",
+                "responses": {
+                    "200": {
+                        "description": "Webhooks",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocViewWebhooks"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/github/callback": {
+            "get": {
+                "description": "An endpoint for selecting the callback for login, connect github endpoint",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Callback for the github sign-in / connect",
+                "responses": {
+                    "200": {
+                        "description": "Token generated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocTokenResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Github Connected",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Existing record",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/forgot-password": {
+            "post": {
+                "description": "An endpoint for getting an otp for reseting the user password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get a OTP for reseting user password",
+                "parameters": [
+                    {
+                        "description": "User email",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.ForgotPasswordEmail"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email sent to user",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid Email",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/github-signup": {
+            "get": {
+                "description": "A redirecting endpoint for sign-in / sign-up with github it calls a service internally to create a vector for new users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Signing up user using github",
+                "responses": {
+                    "307": {
+                        "description": "Redirecting",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/detailed": {
+            "get": {
+                "description": "This gives a detailed health status of the running services. The dependencies are probed concurrently and the results are reused for 30 seconds, checked_at tells when they were taken",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Checks the health of the services running",
+                "responses": {
+                    "200": {
+                        "description": "Service Healthy",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "Service Degraded",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "description": "Liveness probe, only reports that the process is running and serving requests",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Checks that the app process is up",
+                "responses": {
+                    "200": {
+                        "description": "Alive",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Log in endpoint for existing users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Log in a user",
+                "parameters": [
+                    {
+                        "description": "User login payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "jwt token generated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocTokenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Failed to parse payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Readiness probe, reports ready only when the database and cache are reachable",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Checks that the app can serve traffic",
+                "responses": {
+                    "200": {
+                        "description": "Ready",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "Not ready",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/signup": {
+            "post": {
+                "description": "Sign up endpoint for new users it internally calls a service to create a vector for the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User signup payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.SignupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "jwt token generated",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocTokenResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Existing email or username",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Failed to parse payload",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/unsubscribe": {
+            "get": {
+                "description": "An endpoint for the unsubscribe links of the emails, working without logging in. It only shows a page asking to confirm, which posts to the same link, so link scanners opening the emails don't unsubscribe the users",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Confirm an unsubscribe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unsubscribe token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "friend_requests",
+                            "applications",
+                            "subscription",
+                            "trial",
+                            "mentions"
+                        ],
+                        "type": "string",
+                        "description": "Notification category",
+                        "name": "category",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Confirmation page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Invalid link",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "An endpoint for confirming the unsubscribe links of the emails, working without logging in. Without a category it turns the message digests off, with one the emails of that category. Mail clients POST to it for the RFC 8058 one-click List-Unsubscribe header",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Unsubscribe from an email category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unsubscribe token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "friend_requests",
+                            "applications",
+                            "subscription",
+                            "trial",
+                            "mentions"
+                        ],
+                        "type": "string",
+                        "description": "Notification category",
+                        "name": "category",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Unsubscribed",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Invalid link",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-otp": {
+            "get": {
+                "description": "An endpoint to verify sent otp to create reset jwt token for reseting user password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Verify Sent otp to reset password",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "otp",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "reset token",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid otp",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "invalid otp",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "server error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DocNormalResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "schema.AddUserChat": {
+            "type": "object",
+            "required": [
+                "chat_id",
+                "user_id"
+            ],
+            "properties": {
+                "chat_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "schema.ApplicationProjectResponse": {
+            "type": "object",
+            "properties": {
+                "applications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewProjectApplication"
+                    }
+                }
+            }
+        },
+        "schema.ArchiveChat": {
+            "type": "object",
+            "required": [
+                "archived",
+                "chat_id"
+            ],
+            "properties": {
+                "archived": {
+                    "type": "boolean"
+                },
+                "chat_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "schema.CreateWebhook": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
         "schema.DeleteUserSkillsRequest": {
             "type": "object",
-            "required": [
-                "skills"
-            ],
+            "required": [
+                "skills"
+            ],
+            "properties": {
+                "skills": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "schema.DetailedProjectResponse": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "gitLink": {
+                    "type": "string"
+                },
+                "gitProject": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                },
+                "views": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schema.DocAllProjectResponse": {
+            "type": "object",
+            "properties": {
+                "project": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ProjectResponse"
+                    }
+                }
+            }
+        },
+        "schema.DocAttachment": {
+            "type": "object",
+            "properties": {
+                "attachment": {
+                    "$ref": "#/definitions/schema.ViewAttachment"
+                }
+            }
+        },
+        "schema.DocDetailedProjectResponse": {
+            "type": "object",
+            "properties": {
+                "project": {
+                    "$ref": "#/definitions/schema.DetailedProjectResponse"
+                }
+            }
+        },
+        "schema.DocExportChat": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "string"
+                },
+                "exported_at": {
+                    "type": "string"
+                },
+                "exported_by": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "boolean"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ExportMessage"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "schema.DocFriendReqAccept": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "string"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "schema.DocFriendReqStatus": {
+            "type": "object",
+            "properties": {
+                "req": {
+                    "$ref": "#/definitions/schema.FriendReqStatus"
+                }
+            }
+        },
+        "schema.DocInitTranscResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                },
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "schema.DocJoinChat": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "string"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "schema.DocMsgResResponse": {
+            "type": "object",
+            "properties": {
+                "msg": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "schema.DocMsgResponse": {
+            "type": "object",
+            "properties": {
+                "msg": {
+                    "$ref": "#/definitions/schema.ViewMessage"
+                }
+            }
+        },
+        "schema.DocNormalResponse": {
+            "type": "object",
+            "properties": {
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "schema.DocNotificationPreferences": {
+            "type": "object",
+            "properties": {
+                "preferences": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewNotificationPreference"
+                    }
+                }
+            }
+        },
+        "schema.DocProjectApplication": {
+            "type": "object",
+            "properties": {
+                "project_req": {
+                    "$ref": "#/definitions/schema.ViewProjectApplication"
+                }
+            }
+        },
+        "schema.DocProjectResponse": {
+            "type": "object",
+            "properties": {
+                "project": {
+                    "$ref": "#/definitions/schema.ProjectResponse"
+                }
+            }
+        },
+        "schema.DocProjectUserResponse": {
+            "type": "object",
+            "properties": {
+                "posts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ProjectResponse"
+                    }
+                },
+                "user": {
+                    "$ref": "#/definitions/schema.UserProfileResponse"
+                }
+            }
+        },
+        "schema.DocRecProjectsResponse": {
+            "type": "object",
+            "properties": {
+                "projects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.RecProjectResponse"
+                    }
+                }
+            }
+        },
+        "schema.DocRecUsersResponse": {
+            "type": "object",
+            "properties": {
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.RecProfileResponse"
+                    }
+                }
+            }
+        },
+        "schema.DocSearchMessages": {
+            "type": "object",
+            "properties": {
+                "msg": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.SearchMessage"
+                    }
+                }
+            }
+        },
+        "schema.DocSkillsResponse": {
+            "type": "object",
             "properties": {
                 "skills": {
                     "type": "array",
@@ -4204,379 +7299,753 @@ const docTemplate = `{
                 }
             }
         },
-        "schema.DetailedProjectResponse": {
+        "schema.DocTokenResponse": {
             "type": "object",
             "properties": {
-                "available": {
-                    "type": "boolean"
-                },
-                "createdAt": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "gitLink": {
-                    "type": "string"
-                },
-                "gitProject": {
-                    "type": "boolean"
-                },
-                "id": {
+                "token": {
                     "type": "string"
-                },
-                "tags": {
+                }
+            }
+        },
+        "schema.DocTranscResposne": {
+            "type": "object",
+            "properties": {
+                "transactions": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/schema.TransactionResponse"
                     }
-                },
-                "title": {
-                    "type": "string"
-                },
-                "updatedAt": {
+                }
+            }
+        },
+        "schema.DocUnreadNotifications": {
+            "type": "object",
+            "properties": {
+                "unread": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schema.DocUpdateCardSub": {
+            "type": "object",
+            "properties": {
+                "link": {
                     "type": "string"
                 },
-                "username": {
+                "msg": {
                     "type": "string"
-                },
-                "views": {
-                    "type": "integer"
                 }
             }
         },
-        "schema.DocAllProjectResponse": {
+        "schema.DocUserResponse": {
             "type": "object",
             "properties": {
-                "project": {
+                "user": {
+                    "$ref": "#/definitions/schema.UserProfileResponse"
+                }
+            }
+        },
+        "schema.DocUsersSearch": {
+            "type": "object",
+            "properties": {
+                "users": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/schema.ProjectResponse"
+                        "$ref": "#/definitions/schema.SearchUser"
                     }
                 }
             }
         },
-        "schema.DocDetailedProjectResponse": {
+        "schema.DocViewAllChats": {
+            "type": "object",
+            "properties": {
+                "msg": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewChat"
+                    }
+                }
+            }
+        },
+        "schema.DocViewAllProjectApplication": {
             "type": "object",
             "properties": {
                 "project": {
-                    "$ref": "#/definitions/schema.DetailedProjectResponse"
+                    "type": "object",
+                    "additionalProperties": {}
                 }
             }
         },
-        "schema.DocFriendReqAccept": {
+        "schema.DocViewChatHistory": {
             "type": "object",
             "properties": {
-                "chat_id": {
-                    "type": "string"
+                "msg": {
+                    "$ref": "#/definitions/schema.ViewChat"
+                }
+            }
+        },
+        "schema.DocViewEdits": {
+            "type": "object",
+            "properties": {
+                "msg": {
+                    "$ref": "#/definitions/schema.ViewEdits"
+                }
+            }
+        },
+        "schema.DocViewFriendReqs": {
+            "type": "object",
+            "properties": {
+                "rec_req": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.FriendReqStatus"
+                    }
                 },
+                "sent_req": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.FriendReqStatus"
+                    }
+                }
+            }
+        },
+        "schema.DocViewFriends": {
+            "type": "object",
+            "properties": {
+                "friends": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewFriends"
+                    }
+                }
+            }
+        },
+        "schema.DocViewInvite": {
+            "type": "object",
+            "properties": {
                 "msg": {
-                    "type": "string"
+                    "$ref": "#/definitions/schema.ViewInvite"
                 }
             }
         },
-        "schema.DocFriendReqStatus": {
+        "schema.DocViewInvites": {
+            "type": "object",
+            "properties": {
+                "msg": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewInvite"
+                    }
+                }
+            }
+        },
+        "schema.DocViewJoinRequests": {
+            "type": "object",
+            "properties": {
+                "msg": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewJoinRequest"
+                    }
+                }
+            }
+        },
+        "schema.DocViewMembers": {
+            "type": "object",
+            "properties": {
+                "msg": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewMember"
+                    }
+                }
+            }
+        },
+        "schema.DocViewPaymentInfo": {
+            "type": "object",
+            "properties": {
+                "info": {
+                    "$ref": "#/definitions/schema.PaymentInfo"
+                }
+            }
+        },
+        "schema.DocViewPlansResponse": {
+            "type": "object",
+            "properties": {
+                "plans": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewPlansResp"
+                    }
+                }
+            }
+        },
+        "schema.DocViewProjectApplications": {
             "type": "object",
             "properties": {
                 "req": {
-                    "$ref": "#/definitions/schema.FriendReqStatus"
+                    "$ref": "#/definitions/schema.ApplicationProjectResponse"
                 }
             }
         },
-        "schema.DocInitTranscResponse": {
+        "schema.DocViewRepos": {
+            "type": "object",
+            "properties": {
+                "repos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewRepo"
+                    }
+                }
+            }
+        },
+        "schema.DocViewSubscriptions": {
+            "type": "object",
+            "properties": {
+                "subs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewSubscriptions"
+                    }
+                }
+            }
+        },
+        "schema.DocViewThread": {
+            "type": "object",
+            "properties": {
+                "msg": {
+                    "$ref": "#/definitions/schema.ViewThread"
+                }
+            }
+        },
+        "schema.DocViewWebhooks": {
+            "type": "object",
+            "properties": {
+                "webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewWebhook"
+                    }
+                }
+            }
+        },
+        "schema.DocWebhook": {
+            "type": "object",
+            "properties": {
+                "webhook": {
+                    "$ref": "#/definitions/schema.ViewWebhook"
+                }
+            }
+        },
+        "schema.DocWebhookDeliveries": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewWebhookDelivery"
+                    }
+                }
+            }
+        },
+        "schema.DocWebhookDelivery": {
             "type": "object",
             "properties": {
-                "token": {
-                    "type": "string"
-                },
-                "uri": {
-                    "type": "string"
+                "delivery": {
+                    "$ref": "#/definitions/schema.ViewWebhookDelivery"
                 }
             }
         },
-        "schema.DocMsgResResponse": {
+        "schema.EditMessage": {
             "type": "object",
+            "required": [
+                "msg",
+                "msg_id"
+            ],
             "properties": {
                 "msg": {
                     "type": "string"
                 },
-                "reason": {
+                "msg_id": {
                     "type": "string"
                 }
             }
         },
-        "schema.DocMsgResponse": {
+        "schema.ExportChat": {
             "type": "object",
+            "required": [
+                "chat_id",
+                "format"
+            ],
             "properties": {
-                "msg": {
-                    "$ref": "#/definitions/schema.ViewMessage"
+                "chat_id": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "json",
+                        "markdown",
+                        "html"
+                    ]
                 }
             }
         },
-        "schema.DocNormalResponse": {
+        "schema.ExportMessage": {
             "type": "object",
             "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewAttachment"
+                    }
+                },
+                "deleted": {
+                    "type": "boolean"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
                 "msg": {
                     "type": "string"
+                },
+                "reply_to": {
+                    "type": "string"
+                },
+                "revisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewRevision"
+                    }
+                },
+                "sent": {
+                    "type": "string"
+                },
+                "system": {
+                    "type": "boolean"
+                },
+                "uid": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "schema.DocProjectApplication": {
+        "schema.ForgotPasswordEmail": {
             "type": "object",
+            "required": [
+                "email"
+            ],
             "properties": {
-                "project_req": {
-                    "$ref": "#/definitions/schema.ViewProjectApplication"
+                "email": {
+                    "type": "string"
                 }
             }
         },
-        "schema.DocProjectResponse": {
+        "schema.FriendReqStatus": {
             "type": "object",
             "properties": {
-                "project": {
-                    "$ref": "#/definitions/schema.ProjectResponse"
+                "id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "sent": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "schema.DocProjectUserResponse": {
+        "schema.JoinApproval": {
             "type": "object",
+            "required": [
+                "chat_id",
+                "enabled"
+            ],
             "properties": {
-                "posts": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.ProjectResponse"
-                    }
+                "chat_id": {
+                    "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/schema.UserProfileResponse"
+                "enabled": {
+                    "type": "boolean"
                 }
             }
         },
-        "schema.DocRecProjectsResponse": {
+        "schema.LoginRequest": {
             "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
             "properties": {
-                "projects": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.RecProjectResponse"
-                    }
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "schema.DocRecUsersResponse": {
+        "schema.MarkRead": {
             "type": "object",
+            "required": [
+                "chat_id"
+            ],
             "properties": {
-                "users": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.RecProfileResponse"
-                    }
+                "chat_id": {
+                    "type": "string"
+                },
+                "msg_id": {
+                    "description": "The last read message, the latest message of the chat when empty",
+                    "type": "string"
                 }
             }
         },
-        "schema.DocSkillsResponse": {
+        "schema.MuteChat": {
             "type": "object",
+            "required": [
+                "chat_id"
+            ],
             "properties": {
-                "skills": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "chat_id": {
+                    "type": "string"
+                },
+                "until": {
+                    "description": "End of the mute, the chat is unmuted when empty",
+                    "type": "string"
                 }
             }
         },
-        "schema.DocTokenResponse": {
+        "schema.NewInvite": {
             "type": "object",
+            "required": [
+                "chat_id"
+            ],
             "properties": {
-                "token": {
+                "chat_id": {
                     "type": "string"
+                },
+                "expires_in": {
+                    "description": "Lifetime of the link in hours, 0 for a link that doesn't expire",
+                    "type": "integer",
+                    "maximum": 720,
+                    "minimum": 0
+                },
+                "max_uses": {
+                    "description": "Number of users that can join with the link, 0 for no limit",
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 0
                 }
             }
         },
-        "schema.DocTranscResposne": {
+        "schema.NewMessage": {
             "type": "object",
+            "required": [
+                "chat_id"
+            ],
             "properties": {
-                "transactions": {
+                "attachments": {
+                    "description": "Ids of the files uploaded to the chat to send along",
                     "type": "array",
+                    "maxItems": 10,
                     "items": {
-                        "$ref": "#/definitions/schema.TransactionResponse"
+                        "type": "string"
                     }
-                }
-            }
-        },
-        "schema.DocUpdateCardSub": {
-            "type": "object",
-            "properties": {
-                "link": {
+                },
+                "chat_id": {
                     "type": "string"
                 },
                 "msg": {
+                    "description": "The text can only be left empty when files are attached",
+                    "type": "string"
+                },
+                "reply_to": {
+                    "description": "The message replied to, in the same chat",
                     "type": "string"
                 }
             }
         },
-        "schema.DocUserResponse": {
+        "schema.NewProjectRequest": {
             "type": "object",
+            "required": [
+                "description",
+                "tags",
+                "title"
+            ],
             "properties": {
-                "user": {
-                    "$ref": "#/definitions/schema.UserProfileResponse"
+                "description": {
+                    "type": "string"
+                },
+                "git": {
+                    "type": "boolean"
+                },
+                "git_link": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
                 }
             }
         },
-        "schema.DocUsersSearch": {
+        "schema.NotifyLevel": {
             "type": "object",
+            "required": [
+                "chat_id",
+                "level"
+            ],
             "properties": {
-                "users": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.SearchUser"
-                    }
+                "chat_id": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "all",
+                        "mentions",
+                        "none"
+                    ]
                 }
             }
         },
-        "schema.DocViewAllChats": {
+        "schema.PaymentInfo": {
             "type": "object",
             "properties": {
-                "msg": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.ViewChat"
-                    }
+                "card": {
+                    "type": "string"
+                },
+                "last4": {
+                    "type": "string"
+                },
+                "month": {
+                    "type": "string"
+                },
+                "nextPaymentDate": {
+                    "type": "string"
+                },
+                "year": {
+                    "type": "string"
                 }
             }
         },
-        "schema.DocViewAllProjectApplication": {
+        "schema.PinChat": {
             "type": "object",
+            "required": [
+                "chat_id"
+            ],
             "properties": {
-                "project": {
-                    "type": "object",
-                    "additionalProperties": {}
+                "chat_id": {
+                    "type": "string"
+                },
+                "position": {
+                    "description": "Position among the pinned chats (lowest first), the chat is unpinned when empty",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0
                 }
             }
         },
-        "schema.DocViewChatHistory": {
+        "schema.PinMessage": {
             "type": "object",
+            "required": [
+                "chat_id"
+            ],
             "properties": {
-                "msg": {
-                    "$ref": "#/definitions/schema.ViewChat"
+                "chat_id": {
+                    "type": "string"
+                },
+                "msg_id": {
+                    "description": "The message to pin, the pinned message is removed when empty",
+                    "type": "string"
                 }
             }
         },
-        "schema.DocViewFriendReqs": {
+        "schema.ProjectApplication": {
             "type": "object",
             "properties": {
-                "rec_req": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.FriendReqStatus"
-                    }
-                },
-                "sent_req": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.FriendReqStatus"
-                    }
+                "msg": {
+                    "type": "string",
+                    "maxLength": 50
                 }
             }
         },
-        "schema.DocViewFriends": {
+        "schema.ProjectResponse": {
             "type": "object",
             "properties": {
-                "friends": {
+                "available": {
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "tags": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/schema.ViewFriends"
+                        "type": "string"
                     }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "views": {
+                    "type": "integer"
                 }
             }
         },
-        "schema.DocViewPaymentInfo": {
+        "schema.React": {
             "type": "object",
+            "required": [
+                "emoji",
+                "msg_id"
+            ],
             "properties": {
-                "info": {
-                    "$ref": "#/definitions/schema.PaymentInfo"
+                "emoji": {
+                    "type": "string"
+                },
+                "msg_id": {
+                    "type": "string"
                 }
             }
         },
-        "schema.DocViewPlansResponse": {
+        "schema.ReactionCount": {
             "type": "object",
             "properties": {
-                "plans": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.ViewPlansResp"
-                    }
+                "count": {
+                    "type": "integer"
+                },
+                "emoji": {
+                    "type": "string"
+                },
+                "me": {
+                    "type": "boolean"
                 }
             }
         },
-        "schema.DocViewProjectApplications": {
+        "schema.RecProfileResponse": {
             "type": "object",
             "properties": {
-                "req": {
-                    "$ref": "#/definitions/schema.ApplicationProjectResponse"
+                "score": {
+                    "type": "number",
+                    "format": "float32"
+                },
+                "user": {
+                    "$ref": "#/definitions/schema.UserProfileResponse"
                 }
             }
         },
-        "schema.DocViewRepos": {
+        "schema.RecProjectResponse": {
             "type": "object",
             "properties": {
-                "repos": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.ViewRepo"
-                    }
+                "project": {
+                    "$ref": "#/definitions/schema.ProjectResponse"
+                },
+                "score": {
+                    "type": "number",
+                    "format": "float32"
                 }
             }
         },
-        "schema.DocViewSubscriptions": {
+        "schema.RejectApplication": {
             "type": "object",
             "properties": {
-                "subs": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.ViewSubscriptions"
-                    }
+                "msg": {
+                    "type": "string"
                 }
             }
         },
-        "schema.EditMessage": {
+        "schema.RenameChat": {
             "type": "object",
             "required": [
-                "msg",
-                "msg_id"
+                "chat_id",
+                "name"
             ],
             "properties": {
-                "msg": {
+                "chat_id": {
                     "type": "string"
                 },
-                "msg_id": {
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "schema.ForgotPasswordEmail": {
+        "schema.ResetPassword": {
             "type": "object",
             "required": [
-                "email"
+                "password"
             ],
             "properties": {
-                "email": {
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                }
+            }
+        },
+        "schema.ReviewJoin": {
+            "type": "object",
+            "required": [
+                "approve",
+                "chat_id",
+                "user_id"
+            ],
+            "properties": {
+                "approve": {
+                    "type": "boolean"
+                },
+                "chat_id": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "schema.FriendReqStatus": {
+        "schema.SearchMessage": {
             "type": "object",
             "properties": {
+                "chat_id": {
+                    "type": "string"
+                },
+                "edited": {
+                    "type": "string"
+                },
+                "highlight": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
-                "message": {
+                "msg": {
                     "type": "string"
                 },
                 "sent": {
                     "type": "string"
                 },
-                "status": {
+                "uid": {
                     "type": "string"
                 },
                 "username": {
@@ -4584,199 +8053,292 @@ const docTemplate = `{
                 }
             }
         },
-        "schema.LoginRequest": {
+        "schema.SearchProjectWithTags": {
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "tags"
             ],
             "properties": {
-                "password": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "schema.SearchUser": {
+            "type": "object",
+            "properties": {
+                "availability": {
+                    "type": "boolean"
+                },
+                "bio": {
                     "type": "string"
                 },
-                "username": {
+                "gitUser": {
+                    "type": "boolean"
+                },
+                "gitUserName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "interests": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "skills": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "userName": {
                     "type": "string"
                 }
             }
         },
-        "schema.NewMessage": {
+        "schema.SearchUserbySkills": {
             "type": "object",
             "required": [
-                "chat_id",
-                "msg"
+                "skills"
+            ],
+            "properties": {
+                "skills": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "schema.SendFriendReq": {
+            "type": "object",
+            "required": [
+                "uid"
             ],
             "properties": {
-                "chat_id": {
-                    "type": "string"
-                },
                 "msg": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "uid": {
                     "type": "string"
                 }
             }
         },
-        "schema.NewProjectRequest": {
+        "schema.SetRole": {
             "type": "object",
             "required": [
-                "description",
-                "tags",
-                "title"
+                "chat_id",
+                "role",
+                "user_id"
             ],
             "properties": {
-                "description": {
-                    "type": "string"
-                },
-                "git": {
-                    "type": "boolean"
-                },
-                "git_link": {
+                "chat_id": {
                     "type": "string"
                 },
-                "tags": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "member"
+                    ]
                 },
-                "title": {
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "schema.PaymentInfo": {
+        "schema.SignupRequest": {
             "type": "object",
+            "required": [
+                "country",
+                "email",
+                "fullname",
+                "password",
+                "username"
+            ],
             "properties": {
-                "card": {
-                    "type": "string"
+                "bio": {
+                    "type": "string",
+                    "maxLength": 50
                 },
-                "last4": {
+                "country": {
                     "type": "string"
                 },
-                "month": {
+                "email": {
                     "type": "string"
                 },
-                "nextPaymentDate": {
+                "fullname": {
                     "type": "string"
                 },
-                "year": {
+                "interests": {
+                    "type": "array",
+                    "maxItems": 5,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                },
+                "skills": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "schema.ProjectApplication": {
-            "type": "object",
-            "properties": {
-                "msg": {
-                    "type": "string",
-                    "maxLength": 50
-                }
-            }
-        },
-        "schema.ProjectResponse": {
+        "schema.TransactionResponse": {
             "type": "object",
             "properties": {
-                "available": {
-                    "type": "boolean"
-                },
-                "createdAt": {
-                    "type": "string"
+                "amount": {
+                    "type": "integer",
+                    "format": "int64"
                 },
-                "description": {
+                "channel": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "tags": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "title": {
+                "paidAt": {
                     "type": "string"
                 },
-                "updatedAt": {
+                "status": {
                     "type": "string"
-                },
-                "views": {
-                    "type": "integer"
                 }
             }
         },
-        "schema.RecProfileResponse": {
+        "schema.UpdateNotificationPreference": {
             "type": "object",
+            "required": [
+                "category"
+            ],
             "properties": {
-                "score": {
-                    "type": "number",
-                    "format": "float32"
+                "category": {
+                    "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/schema.UserProfileResponse"
+                "digest": {
+                    "type": "boolean"
+                },
+                "email": {
+                    "type": "boolean"
+                },
+                "in_app": {
+                    "type": "boolean"
                 }
             }
         },
-        "schema.RecProjectResponse": {
+        "schema.UpdatePassword": {
             "type": "object",
+            "required": [
+                "new_password",
+                "password"
+            ],
             "properties": {
-                "project": {
-                    "$ref": "#/definitions/schema.ProjectResponse"
+                "new_password": {
+                    "type": "string",
+                    "minLength": 8
                 },
-                "score": {
-                    "type": "number",
-                    "format": "float32"
+                "password": {
+                    "type": "string"
                 }
             }
         },
-        "schema.RejectApplication": {
+        "schema.UpdateUserBio": {
             "type": "object",
             "properties": {
-                "msg": {
-                    "type": "string"
+                "bio": {
+                    "type": "string",
+                    "maxLength": 50
                 }
             }
         },
-        "schema.RenameChat": {
+        "schema.UpdateUserInterests": {
             "type": "object",
-            "required": [
-                "chat_id",
-                "name"
-            ],
             "properties": {
-                "chat_id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
+                "interests": {
+                    "type": "array",
+                    "maxItems": 5,
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "schema.ResetPassword": {
+        "schema.UpdateUserSkillsRequest": {
             "type": "object",
             "required": [
-                "password"
+                "skills"
             ],
             "properties": {
-                "password": {
-                    "type": "string",
-                    "minLength": 8
+                "skills": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "schema.SearchProjectWithTags": {
+        "schema.UpdateWebhook": {
             "type": "object",
             "required": [
-                "tags"
+                "id"
             ],
             "properties": {
-                "tags": {
+                "active": {
+                    "type": "boolean"
+                },
+                "events": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048
                 }
             }
         },
-        "schema.SearchUser": {
+        "schema.UserProfileRequest": {
+            "type": "object",
+            "required": [
+                "country",
+                "email",
+                "fullname",
+                "username"
+            ],
+            "properties": {
+                "country": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "fullname": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "schema.UserProfileResponse": {
             "type": "object",
             "properties": {
                 "availability": {
@@ -4785,12 +8347,21 @@ const docTemplate = `{
                 "bio": {
                     "type": "string"
                 },
-                "gitUser": {
-                    "type": "boolean"
+                "country": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "fullName": {
+                    "type": "string"
                 },
                 "gitUserName": {
                     "type": "string"
                 },
+                "gituser": {
+                    "type": "boolean"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -4811,168 +8382,192 @@ const docTemplate = `{
                 }
             }
         },
-        "schema.SearchUserbySkills": {
-            "type": "object",
-            "required": [
-                "skills"
-            ],
-            "properties": {
-                "skills": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                }
-            }
-        },
-        "schema.SendFriendReq": {
+        "schema.ViewAttachment": {
             "type": "object",
-            "required": [
-                "uid"
-            ],
             "properties": {
-                "msg": {
-                    "type": "string",
-                    "maxLength": 50
+                "height": {
+                    "type": "integer"
                 },
-                "uid": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "thumb_url": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "url": {
                     "type": "string"
+                },
+                "width": {
+                    "type": "integer"
                 }
             }
         },
-        "schema.SignupRequest": {
+        "schema.ViewChat": {
             "type": "object",
-            "required": [
-                "country",
-                "email",
-                "fullname",
-                "password",
-                "username"
-            ],
             "properties": {
-                "bio": {
-                    "type": "string",
-                    "maxLength": 50
+                "archived": {
+                    "type": "boolean"
                 },
-                "country": {
+                "cid": {
                     "type": "string"
                 },
-                "email": {
-                    "type": "string"
+                "group": {
+                    "type": "boolean"
                 },
-                "fullname": {
+                "hasMore": {
+                    "description": "More messages are left past the page of the history",
+                    "type": "boolean"
+                },
+                "joinApproval": {
+                    "type": "boolean"
+                },
+                "lastSeen": {
                     "type": "string"
                 },
-                "interests": {
+                "message": {
                     "type": "array",
-                    "maxItems": 5,
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/schema.ViewMessage"
                     }
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 8
+                "mutedUntil": {
+                    "description": "Preferences of the current user",
+                    "type": "string"
                 },
-                "skills": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "name": {
+                    "type": "string"
                 },
-                "username": {
+                "notifyLevel": {
+                    "type": "string"
+                },
+                "pinPosition": {
+                    "type": "integer"
+                },
+                "pinned": {
+                    "$ref": "#/definitions/schema.ViewMessage"
+                },
+                "presence": {
+                    "description": "Presence of the other member of a direct chat",
+                    "type": "string"
+                },
+                "preview": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role of the current user and settings of a group",
+                    "type": "string"
+                },
+                "unread": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "unreadMentions": {
+                    "description": "Unread messages mentioning the current user",
+                    "type": "integer"
+                }
+            }
+        },
+        "schema.ViewEdits": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "msg": {
                     "type": "string"
+                },
+                "revisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewRevision"
+                    }
                 }
             }
         },
-        "schema.TransactionResponse": {
+        "schema.ViewFriends": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "type": "integer",
-                    "format": "int64"
-                },
-                "channel": {
+                "bio": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "paidAt": {
+                "lastSeen": {
                     "type": "string"
                 },
-                "status": {
+                "presence": {
                     "type": "string"
-                }
-            }
-        },
-        "schema.UpdatePassword": {
-            "type": "object",
-            "required": [
-                "new_password",
-                "password"
-            ],
-            "properties": {
-                "new_password": {
-                    "type": "string",
-                    "minLength": 8
                 },
-                "password": {
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "schema.UpdateUserBio": {
+        "schema.ViewInvite": {
             "type": "object",
             "properties": {
-                "bio": {
-                    "type": "string",
-                    "maxLength": 50
+                "created": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "max_uses": {
+                    "type": "integer"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "uses": {
+                    "type": "integer"
                 }
             }
         },
-        "schema.UpdateUserInterests": {
+        "schema.ViewJoinRequest": {
             "type": "object",
             "properties": {
-                "interests": {
-                    "type": "array",
-                    "maxItems": 5,
-                    "items": {
-                        "type": "string"
-                    }
+                "requested": {
+                    "type": "string"
+                },
+                "uid": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "schema.UpdateUserSkillsRequest": {
+        "schema.ViewMember": {
             "type": "object",
-            "required": [
-                "skills"
-            ],
             "properties": {
-                "skills": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "role": {
+                    "type": "string"
+                },
+                "uid": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "schema.UserProfileRequest": {
+        "schema.ViewMention": {
             "type": "object",
-            "required": [
-                "country",
-                "email",
-                "fullname",
-                "username"
-            ],
             "properties": {
-                "country": {
-                    "type": "string"
-                },
-                "email": {
-                    "type": "string"
-                },
-                "fullname": {
+                "uid": {
                     "type": "string"
                 },
                 "username": {
@@ -4980,101 +8575,132 @@ const docTemplate = `{
                 }
             }
         },
-        "schema.UserProfileResponse": {
+        "schema.ViewMessage": {
             "type": "object",
             "properties": {
-                "availability": {
-                    "type": "boolean"
-                },
-                "bio": {
-                    "type": "string"
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewAttachment"
+                    }
                 },
-                "country": {
-                    "type": "string"
+                "deleted": {
+                    "description": "Deleted for everyone, only the tombstone is left",
+                    "type": "boolean"
                 },
-                "email": {
+                "edited": {
                     "type": "string"
                 },
-                "fullName": {
+                "id": {
                     "type": "string"
                 },
-                "gitUserName": {
-                    "type": "string"
+                "is_edited": {
+                    "type": "boolean"
                 },
-                "gituser": {
+                "mentioned": {
                     "type": "boolean"
                 },
-                "id": {
+                "mentions": {
+                    "description": "Members mentioned with @username, mentioned is set when the current user is one of them",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewMention"
+                    }
+                },
+                "msg": {
                     "type": "string"
                 },
-                "interests": {
+                "reactions": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/schema.ReactionCount"
                     }
                 },
-                "skills": {
+                "replies": {
+                    "type": "integer"
+                },
+                "reply_to": {
+                    "type": "string"
+                },
+                "seen_by": {
+                    "description": "Members of a group chat that have read the message",
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "userName": {
-                    "type": "string"
-                }
-            }
-        },
-        "schema.ViewChat": {
-            "type": "object",
-            "properties": {
-                "cid": {
+                "sent": {
                     "type": "string"
                 },
-                "group": {
+                "system": {
+                    "description": "Recorded by the app on membership and name changes, uid is the member who made the change",
                     "type": "boolean"
                 },
-                "message": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.ViewMessage"
-                    }
+                "uid": {
+                    "type": "string"
                 },
-                "name": {
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "schema.ViewFriends": {
+        "schema.ViewNotification": {
             "type": "object",
             "properties": {
-                "bio": {
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "username": {
+                "read_at": {
+                    "type": "string"
+                },
+                "ref_id": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
                     "type": "string"
                 }
             }
         },
-        "schema.ViewMessage": {
+        "schema.ViewNotificationPreference": {
             "type": "object",
             "properties": {
-                "edited": {
+                "category": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "string"
+                "digest": {
+                    "type": "boolean"
                 },
-                "msg": {
-                    "type": "string"
+                "email": {
+                    "type": "boolean"
                 },
-                "sent": {
-                    "type": "string"
+                "in_app": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "schema.ViewNotifications": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
                 },
-                "uid": {
-                    "type": "string"
+                "notifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewNotification"
+                    }
+                },
+                "unread": {
+                    "type": "integer"
                 }
             }
         },
@@ -5133,6 +8759,20 @@ const docTemplate = `{
                 }
             }
         },
+        "schema.ViewRevision": {
+            "type": "object",
+            "properties": {
+                "editor_id": {
+                    "type": "string"
+                },
+                "msg": {
+                    "type": "string"
+                },
+                "replaced": {
+                    "type": "string"
+                }
+            }
+        },
         "schema.ViewSubscriptions": {
             "type": "object",
             "properties": {
@@ -5144,6 +8784,93 @@ const docTemplate = `{
                 },
                 "start": {
                     "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "schema.ViewThread": {
+            "type": "object",
+            "properties": {
+                "replies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ViewMessage"
+                    }
+                },
+                "root": {
+                    "$ref": "#/definitions/schema.ViewMessage"
+                }
+            }
+        },
+        "schema.ViewWebhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "failures": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "schema.ViewWebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "redelivery_of": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
                 }
             }
         }
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"findme/core"
+	"findme/model"
+	"findme/schema"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxAttachmentSize -> Largest file that can be uploaded to a chat
+	MaxAttachmentSize = 10 << 20
+
+	// AttachmentURLExpiry -> Lifetime of the signed download urls
+	AttachmentURLExpiry = time.Hour
+)
+
+// attachmentTypes -> Types (sniffed from the content, not the name) of the files that can be shared in a chat
+var attachmentTypes = map[string]bool{
+	"image/png":                 true,
+	"image/jpeg":                true,
+	"image/gif":                 true,
+	"image/webp":                true,
+	"application/pdf":           true,
+	"application/zip":           true,
+	"text/plain; charset=utf-8": true,
+}
+
+// thumbnailTypes -> Types of the images the stdlib decodes, which get a thumbnail
+var thumbnailTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+}
+
+// UploadAttachment godoc
+// @Summary    Upload a file to a chat
+// @Description An endpoint for uploading a file (images, pdf, zip or text up to 10MB) to a chat, the returned id is then sent along a message in its attachments
+// @Tags Msg
+// @Accept multipart/form-data
+// @Produce json
+// @Param chat_id formData string true "Chat ID"
+// @Param file formData file true "File"
+// @Security BearerAuth
+// @Success 201 {object} schema.DocAttachment "File uploaded"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid file"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 413 {object} schema.DocNormalResponse "File too large"
+// @Failure 415 {object} schema.DocNormalResponse "Unsupported file type"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/upload [post]
+func (s *Service) UploadAttachment(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	// Leaves room for the other form fields and the multipart boundaries
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, MaxAttachmentSize+1<<20)
+
+	cid := ctx.PostForm("chat_id")
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"msg": "File too large."})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid file."})
+		return
+	}
+	if !model.IsValidUUID(cid) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid chat id."})
+		return
+	}
+	if header.Size > MaxAttachmentSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"msg": "File too large."})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).CheckChatMember(cid, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid file."})
+		return
+	}
+	defer file.Close()
+
+	sniff := make([]byte, 512)
+	n, _ := io.ReadFull(file, sniff)
+	contentType := http.DetectContentType(sniff[:n])
+	if !attachmentTypes[contentType] {
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"msg": "Unsupported file type."})
+		return
+	}
+
+	attachment := model.Attachment{
+		ChatID:      cid,
+		UploaderID:  uid,
+		Name:        attachmentName(header.Filename),
+		ContentType: contentType,
+		Size:        header.Size,
+	}
+	attachment.ID = uuid.NewString()
+	attachment.Key = "chats/" + cid + "/" + attachment.ID
+
+	if thumbnailTypes[contentType] {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to upload the file."})
+			return
+		}
+		thumb, thumbType, width, height, err := core.Thumbnail(file)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid image."})
+			return
+		}
+
+		thumbKey := attachment.Key + "_thumb"
+		if err := s.Storage.Put(ctx, thumbKey, thumb, int64(thumb.Len()), thumbType); err != nil {
+			slog.ErrorContext(ctx, "Failed to store the thumbnail", "component", "storage", "err", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to upload the file."})
+			return
+		}
+		attachment.ThumbKey, attachment.Width, attachment.Height = &thumbKey, width, height
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to upload the file."})
+		return
+	}
+	if err := s.Storage.Put(ctx, attachment.Key, file, header.Size, contentType); err != nil {
+		slog.ErrorContext(ctx, "Failed to store the attachment", "component", "storage", "err", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to upload the file."})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).AddAttachment(&attachment); err != nil {
+		_ = s.Storage.Delete(ctx, attachment.Key)
+		if attachment.ThumbKey != nil {
+			_ = s.Storage.Delete(ctx, *attachment.ThumbKey)
+		}
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"attachment": s.attachmentViews(uid, []model.Attachment{attachment})[0]})
+}
+
+// DownloadAttachment godoc
+// @Summary    Download a chat file
+// @Description An endpoint for downloading a file sent in a chat through the signed url found in the message attachments, the url only works while its user is still a member of the chat
+// @Tags Msg
+// @Produce octet-stream
+// @Param id path string true "Attachment ID"
+// @Param uid query string true "User the url was signed for"
+// @Param exp query int true "Expiry of the url (unix time)"
+// @Param sig query string true "Signature"
+// @Param thumb query bool false "Download the thumbnail of an image"
+// @Success 200 {file} file "File content"
+// @Failure 403 {object} schema.DocNormalResponse "Invalid or expired url"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/attachment/{id} [get]
+func (s *Service) DownloadAttachment(ctx *gin.Context) {
+	id, uid, thumb := ctx.Param("id"), ctx.Query("uid"), ctx.Query("thumb") == "true"
+	exp, err := strconv.ParseInt(ctx.Query("exp"), 10, 64)
+	if err != nil || !model.IsValidUUID(id) || !model.IsValidUUID(uid) || time.Now().Unix() > exp ||
+		!hmac.Equal([]byte(ctx.Query("sig")), []byte(signAttachment(id, uid, exp, thumb))) {
+		ctx.JSON(http.StatusForbidden, gin.H{"msg": "Invalid or expired url."})
+		return
+	}
+
+	var attachment model.Attachment
+	if err := s.DB.WithContext(ctx).FetchAttachment(id, &attachment); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).CheckChatMember(attachment.ChatID, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	key, contentType := attachment.Key, attachment.ContentType
+	if thumb {
+		if attachment.ThumbKey == nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"msg": "Thumbnail not found."})
+			return
+		}
+		key = *attachment.ThumbKey
+		contentType = "image/png"
+		if attachment.ContentType == "image/jpeg" {
+			contentType = "image/jpeg"
+		}
+	}
+
+	body, err := s.Storage.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, core.ErrObjectNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"msg": "Attachment not found."})
+			return
+		}
+		slog.ErrorContext(ctx, "Failed to read the attachment", "component", "storage", "err", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to download the file."})
+		return
+	}
+	defer body.Close()
+
+	// Only images are shown inline, anything else is downloaded
+	disposition := "attachment"
+	if strings.HasPrefix(contentType, "image/") {
+		disposition = "inline"
+	}
+	ctx.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Name}))
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", max(exp-time.Now().Unix(), 0)))
+
+	size := int64(-1)
+	if !thumb {
+		size = attachment.Size
+	}
+	ctx.DataFromReader(http.StatusOK, size, contentType, body, nil)
+}
+
+// signAttachment -> Signature of a download url, bound to the file, the user it was issued to and its expiry
+func signAttachment(id, uid string, exp int64, thumb bool) string {
+	mac := hmac.New(sha256.New, []byte(JWTSecret))
+	fmt.Fprintf(mac, "attachment|%s|%s|%d|%t", id, uid, exp, thumb)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// attachmentURL -> Signed download url of a file for a user
+func attachmentURL(id, uid string, exp int64, thumb bool) string {
+	query := url.Values{
+		"uid": {uid},
+		"exp": {strconv.FormatInt(exp, 10)},
+		"sig": {signAttachment(id, uid, exp, thumb)},
+	}
+	if thumb {
+		query.Set("thumb", "true")
+	}
+	return "/api/msg/attachment/" + id + "?" + query.Encode()
+}
+
+// attachmentViews -> Views of the attachments with download urls signed for the user
+func (s *Service) attachmentViews(uid string, attachments []model.Attachment) []schema.ViewAttachment {
+	views := core.AttachmentViews(attachments)
+	exp := time.Now().Add(AttachmentURLExpiry).Unix()
+	for i, a := range attachments {
+		views[i].URL = attachmentURL(a.ID, uid, exp, false)
+		if a.ThumbKey != nil {
+			views[i].ThumbURL = attachmentURL(a.ID, uid, exp, true)
+		}
+	}
+	return views
+}
+
+// attachmentName -> Keeps the base name of an uploaded file, without control characters and at most 255 bytes
+func attachmentName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	return name
+}
@@ -2,6 +2,7 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"strings"
@@ -23,7 +24,9 @@ type JWTClaims struct {
 }
 
 var (
-	JWTSecret  = os.Getenv("JWTSECRET")
+	JWTSecret = os.Getenv("JWTSECRET")
+	// AppURL -> Public address of the api, prefixed to the links sent by email
+	AppURL     = os.Getenv("APP_URL")
 	JWTExpiry  = time.Hour * 24
 	JWTRExpiry = time.Minute * 5
 	HTTPClient = &http.Client{Timeout: 10 * time.Second}
@@ -132,18 +135,19 @@ func (s *Service) Authentication() gin.HandlerFunc {
 		ctx.Set("userID", payload.UserID)
 		ctx.Set("purpose", payload.Purpose)
 		ctx.Set("premium", payload.Premium)
+		ctx.Request = ctx.Request.WithContext(core.WithLogFields(ctx.Request.Context(), "user_id", payload.UserID))
 
 		ctx.Next()
 	}
 }
 
 // CheckAndUpdateSkills -> Helper func for checking and updating skills
-func (s *Service) CheckAndUpdateSkills(payload []string) ([]*model.Skill, error) {
-	skills, err := s.RDB.RetrieveCachedSkills(payload)
+func (s *Service) CheckAndUpdateSkills(ctx context.Context, payload []string) ([]*model.Skill, error) {
+	skills, err := s.RDB.WithContext(ctx).RetrieveCachedSkills(payload)
 	if err != nil { // Falling back to the db if the cache fails
 		var existingSkills []*model.Skill
 
-		if err := s.DB.FindExistingSkills(&existingSkills, payload); err != nil {
+		if err := s.DB.WithContext(ctx).FindExistingSkills(&existingSkills, payload); err != nil {
 			return nil, err
 		}
 
@@ -160,7 +164,7 @@ func (s *Service) CheckAndUpdateSkills(payload []string) ([]*model.Skill, error)
 		}
 
 		if len(newSkill) > 0 {
-			if err := s.DB.AddSkills(&newSkill); err != nil {
+			if err := s.DB.WithContext(ctx).AddSkills(&newSkill); err != nil {
 				return nil, err
 			}
 		}
@@ -178,10 +182,10 @@ func (s *Service) CheckAndUpdateSkills(payload []string) ([]*model.Skill, error)
 	}
 
 	if len(newskills) > 0 {
-		if err := s.DB.AddSkills(&newskills); err != nil {
+		if err := s.DB.WithContext(ctx).AddSkills(&newskills); err != nil {
 			return nil, err
 		}
-		s.RDB.AddNewSkillToCache(newskills)
+		s.RDB.WithContext(ctx).AddNewSkillToCache(newskills)
 	}
 	allskills = append(allskills, newskills...)
 	return allskills, nil
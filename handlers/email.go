@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+
+	"findme/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailTemplates -> Lists the email templates that can be previewed
+func EmailTemplates(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"templates": core.EmailTemplates()})
+}
+
+// PreviewEmail -> Renders an email template with its sample data, as html or with ?format=text as the plain text part
+func PreviewEmail(ctx *gin.Context) {
+	name := ctx.Param("name")
+	if !slices.Contains(core.EmailTemplates(), name) {
+		ctx.JSON(http.StatusNotFound, gin.H{"msg": "Email template not found."})
+		return
+	}
+
+	format, contentType := "html", "text/html; charset=utf-8"
+	if ctx.Query("format") == "text" {
+		format, contentType = "text", "text/plain; charset=utf-8"
+	}
+
+	var body bytes.Buffer
+	if err := core.PreviewEmail(&body, name, format); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to render the email template."})
+		return
+	}
+
+	ctx.Data(http.StatusOK, contentType, body.Bytes())
+}
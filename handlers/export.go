@@ -0,0 +1,488 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"findme/core"
+	"findme/model"
+	"findme/schema"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ExportURLExpiry -> Lifetime of the emailed export links, and of the attachment urls found in a transcript
+	ExportURLExpiry = 24 * time.Hour
+
+	// exportBatch -> Messages read from the db at once while streaming a transcript
+	exportBatch = 200
+
+	exportTimeLayout = "2006-01-02 15:04 MST"
+)
+
+// exportFormats -> Content type and file extension of each transcript format
+var exportFormats = map[string]struct{ contentType, ext string }{
+	"json":     {"application/json; charset=utf-8", "json"},
+	"markdown": {"text/markdown; charset=utf-8", "md"},
+	"html":     {"text/html; charset=utf-8", "html"},
+}
+
+// ExportChat godoc
+// @Summary    Export a chat
+// @Description An endpoint for downloading the full history of a chat as seen by the current user (sender usernames, timestamps, edits and attachment links), streamed as json, markdown or html
+// @Tags Msg
+// @Produce json
+// @Produce text/markdown
+// @Produce html
+// @Param id query string true "Chat ID"
+// @Param format query string false "json (default), markdown or html"
+// @Security BearerAuth
+// @Success 200 {object} schema.DocExportChat "Chat transcript"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id or format"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/export [get]
+func (s *Service) ExportChat(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	cid, format := ctx.Query("id"), ctx.DefaultQuery("format", "json")
+	if !model.IsValidUUID(cid) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid chat id."})
+		return
+	}
+	if _, ok := exportFormats[format]; !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid format, use json, markdown or html."})
+		return
+	}
+
+	s.streamExport(ctx, cid, uid, format)
+}
+
+// EmailExport godoc
+// @Summary    Email a chat export
+// @Description An endpoint for receiving the download link of a chat transcript by email, the link expires after a day and only works while the user is still a member of the chat
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param payload body schema.ExportChat true "Export payload"
+// @Security BearerAuth
+// @Success 202 {object} schema.DocNormalResponse "Export link sent"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/export-email [post]
+func (s *Service) EmailExport(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var payload schema.ExportChat
+	if err := ctx.ShouldBindJSON(&payload); err != nil || !model.IsValidUUID(payload.ChatID) {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).CheckChatMember(payload.ChatID, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	var chat model.Chat
+	if err := s.DB.WithContext(ctx).FetchChatPreloadU(payload.ChatID, &chat); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	var user model.User
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	exp := time.Now().Add(ExportURLExpiry)
+	link := AppURL + exportURL(chat.ID, uid, payload.Format, exp.Unix())
+	s.Email.QueueChatExportEmail(ctx, user.UserName, chatName(&chat, uid), payload.Format, link, exp.UTC().Format(exportTimeLayout), user.Email)
+
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": "The export link was sent to your email."})
+}
+
+// DownloadExport godoc
+// @Summary    Download an emailed chat export
+// @Description An endpoint for downloading a chat transcript through the signed link sent by email
+// @Tags Msg
+// @Produce json
+// @Produce text/markdown
+// @Produce html
+// @Param id path string true "Chat ID"
+// @Param uid query string true "User the link was signed for"
+// @Param format query string true "json, markdown or html"
+// @Param exp query int true "Expiry of the link (unix time)"
+// @Param sig query string true "Signature"
+// @Success 200 {object} schema.DocExportChat "Chat transcript"
+// @Failure 403 {object} schema.DocNormalResponse "Invalid or expired link"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/export/{id} [get]
+func (s *Service) DownloadExport(ctx *gin.Context) {
+	cid, uid, format := ctx.Param("id"), ctx.Query("uid"), ctx.Query("format")
+	exp, err := strconv.ParseInt(ctx.Query("exp"), 10, 64)
+	if _, ok := exportFormats[format]; err != nil || !ok || !model.IsValidUUID(cid) || !model.IsValidUUID(uid) ||
+		time.Now().Unix() > exp || !hmac.Equal([]byte(ctx.Query("sig")), []byte(signExport(cid, uid, format, exp))) {
+		ctx.JSON(http.StatusForbidden, gin.H{"msg": "Invalid or expired link."})
+		return
+	}
+
+	s.streamExport(ctx, cid, uid, format)
+}
+
+// streamExport -> Writes the transcript of a chat a batch of messages at a time. Failures past the first batch can
+// only cut the transcript short, as the response has started
+func (s *Service) streamExport(ctx *gin.Context, cid, uid, format string) {
+	if err := s.DB.WithContext(ctx).CheckChatMember(cid, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	var chat model.Chat
+	if err := s.DB.WithContext(ctx).FetchChatPreloadU(cid, &chat); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	var msgs []model.UserMessage
+	if err := s.DB.WithContext(ctx).FetchChatExport(cid, uid, nil, exportBatch, &msgs); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	by := uid
+	for _, u := range chat.Users {
+		if u.ID == uid {
+			by = u.UserName
+		}
+	}
+	header := schema.ExportHeader{ChatID: chat.ID, Name: chatName(&chat, uid), Group: chat.Group, Exported: time.Now().UTC(), By: by}
+
+	kind := exportFormats[format]
+	filename := fmt.Sprintf("chat-%s-%s.%s", chat.ID[:8], header.Exported.Format("20060102"), kind.ext)
+	ctx.Header("Content-Type", kind.contentType)
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.Status(http.StatusOK)
+
+	out := newTranscript(format, ctx.Writer)
+	if err := out.Header(header); err != nil {
+		return
+	}
+
+	// Attachment urls last as long as the emailed links, so a transcript stays usable for as long as it can be downloaded
+	exp := time.Now().Add(ExportURLExpiry).Unix()
+	for len(msgs) > 0 {
+		messages, err := s.exportMessages(ctx, uid, exp, msgs)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to export the chat", "component", "chat", "chat_id", cid, "err", err)
+			return
+		}
+		for _, msg := range messages {
+			if err := out.Message(msg); err != nil {
+				return
+			}
+		}
+		ctx.Writer.Flush()
+
+		if len(msgs) < exportBatch {
+			break
+		}
+		last := msgs[len(msgs)-1]
+		msgs = nil
+		if err := s.DB.WithContext(ctx).FetchChatExport(cid, uid, &last, exportBatch, &msgs); err != nil {
+			slog.ErrorContext(ctx, "Failed to export the chat", "component", "chat", "chat_id", cid, "err", err)
+			return
+		}
+	}
+
+	_ = out.Footer()
+}
+
+// exportMessages -> Messages of a transcript batch with their earlier versions and files. The messages deleted for
+// everyone only keep their tombstone
+func (s *Service) exportMessages(ctx *gin.Context, uid string, exp int64, msgs []model.UserMessage) ([]schema.ExportMessage, error) {
+	ids := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		if !msg.IsRemoved() {
+			ids = append(ids, msg.ID)
+		}
+	}
+
+	var attachments []model.Attachment
+	if err := s.DB.WithContext(ctx).FetchAttachments(ids, &attachments); err != nil {
+		return nil, err
+	}
+	var revisions []model.MessageRevision
+	if err := s.DB.WithContext(ctx).FetchRevisions(ids, &revisions); err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]schema.ViewAttachment)
+	for _, a := range attachments {
+		view := core.AttachmentViews([]model.Attachment{a})[0]
+		view.URL = AppURL + attachmentURL(a.ID, uid, exp, false)
+		files[*a.MsgID] = append(files[*a.MsgID], view)
+	}
+	edits := make(map[string][]schema.ViewRevision)
+	for _, r := range revisions {
+		edits[r.MsgID] = append(edits[r.MsgID], schema.ViewRevision{Message: r.Message, EditorID: r.EditorID, Replaced: r.CreatedAt})
+	}
+
+	messages := make([]schema.ExportMessage, len(msgs))
+	for i, msg := range msgs {
+		view := messageView(&msg)
+		messages[i] = schema.ExportMessage{
+			ID:          msg.ID,
+			UserID:      msg.FromID,
+			Username:    view.Username,
+			Message:     view.Message,
+			Sent:        msg.CreatedAt,
+			EditedAt:    msg.EditedAt,
+			System:      msg.System,
+			Deleted:     view.Deleted,
+			ReplyToID:   msg.ReplyToID,
+			Revisions:   edits[msg.ID],
+			Attachments: files[msg.ID],
+		}
+	}
+	return messages, nil
+}
+
+// chatName -> Name of a chat as seen by a member, the username of the other member for direct chats
+func chatName(chat *model.Chat, uid string) string {
+	if chat.Group {
+		return chat.Name
+	}
+	for _, u := range chat.Users {
+		if u.ID != uid {
+			return u.UserName
+		}
+	}
+	return chat.Name
+}
+
+// signExport -> Signature of an emailed export link, tied to the chat, the user, the format and the expiry
+func signExport(cid, uid, format string, exp int64) string {
+	mac := hmac.New(sha256.New, []byte(JWTSecret))
+	fmt.Fprintf(mac, "export|%s|%s|%s|%d", cid, uid, format, exp)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// exportURL -> Signed download link of a chat transcript for a user
+func exportURL(cid, uid, format string, exp int64) string {
+	query := url.Values{
+		"uid":    {uid},
+		"format": {format},
+		"exp":    {strconv.FormatInt(exp, 10)},
+		"sig":    {signExport(cid, uid, format, exp)},
+	}
+	return "/api/msg/export/" + cid + "?" + query.Encode()
+}
+
+// transcript -> Writes a chat export in one of the formats, message by message
+type transcript interface {
+	Header(h schema.ExportHeader) error
+	Message(m schema.ExportMessage) error
+	Footer() error
+}
+
+func newTranscript(format string, w io.Writer) transcript {
+	switch format {
+	case "markdown":
+		return &markdownTranscript{w: w}
+	case "html":
+		return &htmlTranscript{w: w}
+	default:
+		return &jsonTranscript{w: w}
+	}
+}
+
+// jsonTranscript -> Writes schema.DocExportChat without holding the messages in memory
+type jsonTranscript struct {
+	w     io.Writer
+	count int
+}
+
+func (t *jsonTranscript) Header(h schema.ExportHeader) error {
+	head, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	// The header fields are kept open to add the messages array after them
+	_, err = fmt.Fprintf(t.w, `%s,"messages":[`, head[:len(head)-1])
+	return err
+}
+
+func (t *jsonTranscript) Message(m schema.ExportMessage) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if t.count > 0 {
+		if _, err := io.WriteString(t.w, ","); err != nil {
+			return err
+		}
+	}
+	t.count++
+	_, err = t.w.Write(body)
+	return err
+}
+
+func (t *jsonTranscript) Footer() error {
+	_, err := io.WriteString(t.w, "]}")
+	return err
+}
+
+// markdownTranscript -> Writes a Markdown document, every text coming from the chat is escaped so it can't add html,
+// links or formatting
+type markdownTranscript struct {
+	w io.Writer
+}
+
+// markdownText -> Escapes the html and the Markdown metacharacters of a text, in a single pass so the entities aren't
+// escaped again
+var markdownText = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;",
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"#", `\#`, "|", `\|`, "~", `\~`, "!", `\!`,
+)
+
+// markdownURL -> Percent-encodes the characters that would end the destination of a Markdown link
+var markdownURL = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+
+func (t *markdownTranscript) Header(h schema.ExportHeader) error {
+	_, err := fmt.Fprintf(t.w, "# %s\n\n_Exported by %s on %s_\n\n---\n\n", markdownText.Replace(h.Name), markdownText.Replace(h.By), h.Exported.Format(exportTimeLayout))
+	return err
+}
+
+func (t *markdownTranscript) Message(m schema.ExportMessage) error {
+	var b strings.Builder
+	sent := m.Sent.UTC().Format(exportTimeLayout)
+	username, message := markdownText.Replace(m.Username), markdownText.Replace(m.Message)
+	switch {
+	case m.System:
+		fmt.Fprintf(&b, "_%s · %s_\n\n", message, sent)
+	case m.Deleted:
+		fmt.Fprintf(&b, "**%s** · %s\n\n> _%s_\n\n", username, sent, message)
+	default:
+		fmt.Fprintf(&b, "**%s** · %s", username, sent)
+		if m.EditedAt != nil {
+			fmt.Fprintf(&b, " (edited %s)", m.EditedAt.UTC().Format(exportTimeLayout))
+		}
+		b.WriteString("\n\n> " + strings.ReplaceAll(message, "\n", "\n> ") + "\n\n")
+		for _, r := range m.Revisions {
+			fmt.Fprintf(&b, "<details><summary>Before %s</summary>\n\n%s\n\n</details>\n\n", r.Replaced.UTC().Format(exportTimeLayout), markdownText.Replace(r.Message))
+		}
+		for _, a := range m.Attachments {
+			fmt.Fprintf(&b, "- [%s](%s)\n", markdownText.Replace(a.Name), markdownURL.Replace(a.URL))
+		}
+		if len(m.Attachments) > 0 {
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(t.w, b.String())
+	return err
+}
+
+func (t *markdownTranscript) Footer() error {
+	return nil
+}
+
+// htmlTranscript -> Writes a standalone page, every text coming from the chat is escaped
+type htmlTranscript struct {
+	w io.Writer
+}
+
+func (t *htmlTranscript) Header(h schema.ExportHeader) error {
+	name := html.EscapeString(h.Name)
+	_, err := fmt.Fprintf(t.w, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<title>%s</title>
+<style>
+body { font-family: Arial, sans-serif; background: #f9fafb; color: #111827; max-width: 760px; margin: 0 auto; padding: 24px; }
+.msg { background: #ffffff; border-radius: 8px; padding: 12px 16px; margin-bottom: 12px; }
+.meta { font-size: 12px; color: #6b7280; margin-bottom: 6px; }
+.system, .deleted { font-style: italic; color: #6b7280; }
+.text { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>%s</h1>
+<p class="meta">Exported by %s on %s</p>
+`, name, name, html.EscapeString(h.By), h.Exported.Format(exportTimeLayout))
+	return err
+}
+
+func (t *htmlTranscript) Message(m schema.ExportMessage) error {
+	var b strings.Builder
+	sent := m.Sent.UTC().Format(exportTimeLayout)
+	switch {
+	case m.System:
+		fmt.Fprintf(&b, "<div class=\"msg system\" id=\"%s\">%s · %s</div>\n", m.ID, html.EscapeString(m.Message), sent)
+	default:
+		fmt.Fprintf(&b, "<div class=\"msg\" id=\"%s\">\n<div class=\"meta\"><b>%s</b> · %s", m.ID, html.EscapeString(m.Username), sent)
+		if m.EditedAt != nil {
+			fmt.Fprintf(&b, " (edited %s)", m.EditedAt.UTC().Format(exportTimeLayout))
+		}
+		if m.ReplyToID != nil {
+			fmt.Fprintf(&b, ` · <a href="#%s">in reply</a>`, *m.ReplyToID)
+		}
+		class := "text"
+		if m.Deleted {
+			class = "text deleted"
+		}
+		fmt.Fprintf(&b, "</div>\n<div class=\"%s\">%s</div>\n", class, html.EscapeString(m.Message))
+		for _, r := range m.Revisions {
+			fmt.Fprintf(&b, "<details><summary>Before %s</summary><div class=\"text\">%s</div></details>\n", r.Replaced.UTC().Format(exportTimeLayout), html.EscapeString(r.Message))
+		}
+		for _, a := range m.Attachments {
+			fmt.Fprintf(&b, "<div><a href=\"%s\">%s</a></div>\n", html.EscapeString(a.URL), html.EscapeString(a.Name))
+		}
+		b.WriteString("</div>\n")
+	}
+	_, err := io.WriteString(t.w, b.String())
+	return err
+}
+
+func (t *htmlTranscript) Footer() error {
+	_, err := io.WriteString(t.w, "</body>\n</html>\n")
+	return err
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -19,8 +20,8 @@ type Git interface {
 	GitHubAddUser(ctx *gin.Context)
 	SelectCallback(ctx *gin.Context)
 	ConnectGitHub(ctx *gin.Context)
-	ConnectGitHubCallback(uid, code, state, storedState string) (string, error)
-	GitHubAddUserCallback(token, code, state, storedState string) (string, string, error)
+	ConnectGitHubCallback(ctx context.Context, uid, code, state, storedState string) (string, error)
+	GitHubAddUserCallback(ctx context.Context, token, code, state, storedState string) (string, string, error)
 	ViewRepo(ctx *gin.Context)
 }
 
@@ -120,7 +121,7 @@ func (g *GitService) SelectCallback(ctx *gin.Context) {
 	code := ctx.Query("code")
 	switch auth {
 	case "login":
-		jwtToken, gitToken, err := g.GitHubAddUserCallback(token, code, state, storedState)
+		jwtToken, gitToken, err := g.GitHubAddUserCallback(ctx, token, code, state, storedState)
 		if err != nil {
 			cm := err.(*core.CustomMessage)
 			ctx.AbortWithStatusJSON(cm.Code, gin.H{"msg": cm.Message})
@@ -130,7 +131,7 @@ func (g *GitService) SelectCallback(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, gin.H{"token": jwtToken})
 		return
 	case "auth":
-		gitToken, err := g.ConnectGitHubCallback(uid, code, state, storedState)
+		gitToken, err := g.ConnectGitHubCallback(ctx, uid, code, state, storedState)
 		if err != nil {
 			cm := err.(*core.CustomMessage)
 			ctx.AbortWithStatusJSON(cm.Code, gin.H{"msg": cm.Message})
@@ -145,7 +146,7 @@ func (g *GitService) SelectCallback(ctx *gin.Context) {
 }
 
 // GitHubAddUserCallback -> callback for the github sign-up/sign-in endpoint
-func (g *GitService) GitHubAddUserCallback(token, code, state, storedState string) (string, string, error) {
+func (g *GitService) GitHubAddUserCallback(ctx context.Context, token, code, state, storedState string) (string, string, error) {
 	if token == "" || !g.isTokenValid(token) {
 		if state != storedState {
 			return "", "", &core.CustomMessage{Code: http.StatusBadRequest, Message: "Invalid or expired state."}
@@ -191,7 +192,7 @@ func (g *GitService) GitHubAddUserCallback(token, code, state, storedState strin
 	}
 
 	var existingUser model.User
-	if err := g.DB.FindExistingGitID(&existingUser, user.ID); err == nil {
+	if err := g.DB.WithContext(ctx).FindExistingGitID(&existingUser, user.ID); err == nil {
 		premium := CheckSubscription(&existingUser)
 		userToken, err := GenerateJWT(existingUser.ID, "login", premium, JWTExpiry)
 		if err != nil {
@@ -234,12 +235,12 @@ func (g *GitService) GitHubAddUserCallback(token, code, state, storedState strin
 
 	}
 
-	if err := g.DB.CheckExistingEmail(user.Email); err != nil {
+	if err := g.DB.WithContext(ctx).CheckExistingEmail(user.Email); err != nil {
 		return "", "", &core.CustomMessage{Code: http.StatusConflict, Message: "There's an account associated with that email already!"}
 	}
 
 	newUsername := user.UserName
-	if err := g.DB.CheckExistingUsername(newUsername); err != nil {
+	if err := g.DB.WithContext(ctx).CheckExistingUsername(newUsername); err != nil {
 		newUsername = core.GenerateUsername(existingUser.UserName)
 	}
 
@@ -255,11 +256,11 @@ func (g *GitService) GitHubAddUserCallback(token, code, state, storedState strin
 		Bio:          user.Bio,
 	}
 
-	if err := g.DB.AddUser(&newUser); err != nil {
+	if err := g.DB.WithContext(ctx).AddUser(&newUser); err != nil {
 		return "", "", err
 	}
 
-	g.EmbHub.QueueUserCreate(newUser.ID, newUser.Bio, []string{""}, []string{""})
+	g.EmbHub.QueueUserCreate(ctx, newUser.ID, newUser.Bio, []string{""}, []string{""})
 
 	userToken, err := GenerateJWT(newUser.ID, "login", true, JWTExpiry)
 	if err != nil {
@@ -270,7 +271,7 @@ func (g *GitService) GitHubAddUserCallback(token, code, state, storedState strin
 }
 
 // ConnectGitHubCallback -> callback for the github connect endpoint
-func (g *GitService) ConnectGitHubCallback(uid, code, state, storedState string) (string, error) {
+func (g *GitService) ConnectGitHubCallback(ctx context.Context, uid, code, state, storedState string) (string, error) {
 	if uid == "" {
 		return "", &core.CustomMessage{Code: http.StatusUnauthorized, Message: "Unauthorized user."}
 	}
@@ -317,7 +318,7 @@ func (g *GitService) ConnectGitHubCallback(uid, code, state, storedState string)
 	}
 
 	var user model.User
-	if err := g.DB.FetchUser(&user, uid); err != nil {
+	if err := g.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		return "", err
 	}
 
@@ -325,7 +326,7 @@ func (g *GitService) ConnectGitHubCallback(uid, code, state, storedState string)
 	user.GitUser = true
 	user.GitUserName = &gitUser.UserName
 
-	if err := g.DB.SaveUser(&user); err != nil {
+	if err := g.DB.WithContext(ctx).SaveUser(&user); err != nil {
 		return "", err
 	}
 
@@ -354,7 +355,7 @@ func (g *GitService) ViewRepo(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := g.DB.FetchUser(&user, uid); err != nil {
+	if err := g.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
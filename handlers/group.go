@@ -0,0 +1,648 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"findme/core"
+	"findme/model"
+	"findme/schema"
+
+	"github.com/gin-gonic/gin"
+)
+
+// groupMember -> Retrieves a group chat along with the membership of the user in it
+func (s *Service) groupMember(ctx *gin.Context, chatID, uid string, chat *model.Chat, member *model.ChatUser) error {
+	if err := s.DB.WithContext(ctx).FetchChat(chatID, chat); err != nil {
+		return err
+	}
+	if !chat.Group {
+		return &core.CustomMessage{Code: http.StatusForbidden, Message: "This isn't a group chat."}
+	}
+	return s.DB.WithContext(ctx).FetchChatMember(chatID, uid, member)
+}
+
+// groupAdmin -> Retrieves a group chat the user manages (owner or admin)
+func (s *Service) groupAdmin(ctx *gin.Context, chatID, uid string, chat *model.Chat, member *model.ChatUser) error {
+	if err := s.groupMember(ctx, chatID, uid, chat, member); err != nil {
+		return err
+	}
+	if !model.IsAdminRole(member.Role) {
+		return &core.CustomMessage{Code: http.StatusForbidden, Message: "Only the admins of the group can do this."}
+	}
+	return nil
+}
+
+// systemMessage -> Records a change of a group in its history and sends it to the members, the change itself
+// already happened so a failure is only logged
+func (s *Service) systemMessage(ctx *gin.Context, chatID, uid, text string) {
+	msg := model.UserMessage{ChatID: chatID, FromID: uid, Message: text, System: true}
+	if err := s.DB.WithContext(ctx).AddMessage(&msg); err != nil {
+		slog.WarnContext(ctx, "Failed to record the system message", "component", "chat", "chat_id", chatID)
+		return
+	}
+	s.Chat.Publish(core.NewEvent(schema.EventMessageNew, chatID, ctx.GetString("requestID"), messageView(&msg)))
+}
+
+// ViewMembers godoc
+// @Summary    View the members of a chat
+// @Description An endpoint to view the members of a chat along with their role
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param id query string true "Chat ID"
+// @Security BearerAuth
+// @Success 200 {object} schema.DocViewMembers "Chat members"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/view-members [get]
+func (s *Service) ViewMembers(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	cid := ctx.Query("id")
+	if !model.IsValidUUID(cid) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid chat id."})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).CheckChatMember(cid, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	var members []model.ChatUser
+	if err := s.DB.WithContext(ctx).FetchChatMembers(cid, &members); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	views := make([]schema.ViewMember, len(members))
+	for i, m := range members {
+		views[i] = schema.ViewMember{UserID: m.UserID, Role: m.Role}
+		if m.User != nil {
+			views[i].Username = m.User.UserName
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": views})
+}
+
+// SetMemberRole godoc
+// @Summary    Change the role of a group member
+// @Description An endpoint for the owner of a group to make a member an admin or take it back
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param payload body schema.SetRole true "Role payload"
+// @Security BearerAuth
+// @Success 202 {object} schema.DocNormalResponse "Role updated"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/set-role [patch]
+func (s *Service) SetMemberRole(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var payload schema.SetRole
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
+		return
+	}
+
+	var chat model.Chat
+	var member model.ChatUser
+	if err := s.groupMember(ctx, payload.ChatID, uid, &chat, &member); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	if member.Role != model.RoleOwner {
+		ctx.JSON(http.StatusForbidden, gin.H{"msg": "Only the owner of the group can change the roles."})
+		return
+	}
+	if payload.UserID == uid {
+		ctx.JSON(http.StatusForbidden, gin.H{"msg": "Transfer the ownership to change your own role."})
+		return
+	}
+
+	var target model.ChatUser
+	if err := s.DB.WithContext(ctx).FetchChatMember(chat.ID, payload.UserID, &target); err != nil {
+		cm := err.(*core.CustomMessage)
+		if cm.Code == http.StatusForbidden {
+			ctx.JSON(http.StatusNotFound, gin.H{"msg": "User isn't a member of this chat."})
+			return
+		}
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).SetMemberRole(chat.ID, target.UserID, payload.Role); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	s.Chat.Publish(core.NewEvent(schema.EventMemberRole, chat.ID, ctx.GetString("requestID"), schema.WSMemberRole{
+		UserID: target.UserID,
+		Role:   payload.Role,
+		By:     uid,
+	}))
+	if payload.Role != target.Role {
+		text := fmt.Sprintf("%s made %s an admin", member.User.UserName, target.User.UserName)
+		if payload.Role == model.RoleMember {
+			text = fmt.Sprintf("%s removed %s as an admin", member.User.UserName, target.User.UserName)
+		}
+		s.systemMessage(ctx, chat.ID, uid, text)
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Member role updated successfully."})
+}
+
+// PinMessage godoc
+// @Summary    Pin a message in a group
+// @Description An endpoint for the admins of a group to pin a message at the top of the chat, or to remove the pinned message with an empty msg_id
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param payload body schema.PinMessage true "Pin payload"
+// @Security BearerAuth
+// @Success 202 {object} schema.DocNormalResponse "Message pinned"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/pin-message [patch]
+func (s *Service) PinMessage(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var payload schema.PinMessage
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
+		return
+	}
+	if payload.MsgID != "" && !model.IsValidUUID(payload.MsgID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid message id."})
+		return
+	}
+
+	var chat model.Chat
+	var member model.ChatUser
+	if err := s.groupAdmin(ctx, payload.ChatID, uid, &chat, &member); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	chat.PinnedMsgID = nil
+	if payload.MsgID != "" {
+		var msg model.UserMessage
+		if err := s.DB.WithContext(ctx).FetchMsg(&msg, payload.MsgID); err != nil || msg.ChatID != chat.ID || msg.IsRemoved() {
+			ctx.JSON(http.StatusNotFound, gin.H{"msg": "Message not found."})
+			return
+		}
+		chat.PinnedMsgID = &msg.ID
+	}
+
+	if err := s.DB.WithContext(ctx).SaveChat(&chat); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	s.Chat.Publish(core.NewEvent(schema.EventMessagePinned, chat.ID, ctx.GetString("requestID"), schema.WSMessagePinned{
+		MsgID: payload.MsgID,
+		By:    uid,
+	}))
+
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Pinned message updated successfully."})
+}
+
+// SetJoinApproval godoc
+// @Summary    Turn join approval on or off
+// @Description An endpoint for the admins of a group to require their approval for the users joining through an invite link
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param payload body schema.JoinApproval true "Approval payload"
+// @Security BearerAuth
+// @Success 202 {object} schema.DocNormalResponse "Chat updated"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/join-approval [patch]
+func (s *Service) SetJoinApproval(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var payload schema.JoinApproval
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
+		return
+	}
+
+	var chat model.Chat
+	var member model.ChatUser
+	if err := s.groupAdmin(ctx, payload.ChatID, uid, &chat, &member); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	chat.JoinApproval = *payload.Enabled
+	if err := s.DB.WithContext(ctx).SaveChat(&chat); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Join approval updated successfully."})
+}
+
+// CreateInvite godoc
+// @Summary    Create an invite link
+// @Description An endpoint for the admins of a group to create a shareable invite link, optionally expiring after some hours or a number of uses
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param payload body schema.NewInvite true "Invite payload"
+// @Security BearerAuth
+// @Success 201 {object} schema.DocViewInvite "Invite created"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/create-invite [post]
+func (s *Service) CreateInvite(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var payload schema.NewInvite
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
+		return
+	}
+
+	var chat model.Chat
+	var member model.ChatUser
+	if err := s.groupAdmin(ctx, payload.ChatID, uid, &chat, &member); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	token, err := core.GenerateState()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to create the invite link."})
+		return
+	}
+
+	invite := model.ChatInvite{ChatID: chat.ID, CreatorID: uid, Token: token, MaxUses: payload.MaxUses}
+	if payload.ExpiresIn > 0 {
+		expiry := time.Now().Add(time.Duration(payload.ExpiresIn) * time.Hour)
+		invite.ExpiresAt = &expiry
+	}
+
+	if err := s.DB.WithContext(ctx).AddChatInvite(&invite); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"msg": inviteView(&invite)})
+}
+
+// ViewInvites godoc
+// @Summary    View the invite links of a group
+// @Description An endpoint for the admins of a group to view its invite links that weren't revoked, newest first
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param id query string true "Chat ID"
+// @Security BearerAuth
+// @Success 200 {object} schema.DocViewInvites "Invite links"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/view-invites [get]
+func (s *Service) ViewInvites(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	cid := ctx.Query("id")
+	if !model.IsValidUUID(cid) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid chat id."})
+		return
+	}
+
+	var chat model.Chat
+	var member model.ChatUser
+	if err := s.groupAdmin(ctx, cid, uid, &chat, &member); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	var invites []model.ChatInvite
+	if err := s.DB.WithContext(ctx).FetchChatInvites(chat.ID, &invites); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	views := make([]schema.ViewInvite, len(invites))
+	for i := range invites {
+		views[i] = inviteView(&invites[i])
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": views})
+}
+
+// RevokeInvite godoc
+// @Summary    Revoke an invite link
+// @Description An endpoint for the admins of a group to stop an invite link from working
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param id query string true "Invite ID"
+// @Security BearerAuth
+// @Success 204 {object} nil "Invite revoked"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/revoke-invite [delete]
+func (s *Service) RevokeInvite(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	iid := ctx.Query("id")
+	if !model.IsValidUUID(iid) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid invite id."})
+		return
+	}
+
+	var invite model.ChatInvite
+	if err := s.DB.WithContext(ctx).FetchChatInvite(iid, &invite); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	var chat model.Chat
+	var member model.ChatUser
+	if err := s.groupAdmin(ctx, invite.ChatID, uid, &chat, &member); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).RevokeChatInvite(&invite); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// JoinChat godoc
+// @Summary    Join a group with an invite link
+// @Description An endpoint for joining a group through an invite link, groups with join approval turned on get a join request instead for their admins to review
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param token path string true "Invite token"
+// @Security BearerAuth
+// @Success 200 {object} schema.DocJoinChat "Chat joined"
+// @Success 202 {object} schema.DocNormalResponse "Join request sent"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 409 {object} schema.DocNormalResponse "Already a member"
+// @Failure 410 {object} schema.DocNormalResponse "Invite expired"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/join/{token} [post]
+func (s *Service) JoinChat(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var invite model.ChatInvite
+	if err := s.DB.WithContext(ctx).FetchChatInviteByToken(ctx.Param("token"), &invite); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+	if !invite.IsUsable(time.Now()) {
+		ctx.JSON(http.StatusGone, gin.H{"msg": "This invite link has expired."})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).CheckChatMember(invite.ChatID, uid); err == nil {
+		ctx.JSON(http.StatusConflict, gin.H{"msg": "You're already in the group."})
+		return
+	} else if cm := err.(*core.CustomMessage); cm.Code != http.StatusForbidden {
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	var user model.User
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	approval := invite.Chat.JoinApproval
+	if err := s.DB.WithContext(ctx).JoinChatInvite(&invite, uid, approval); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	joined := schema.WSMember{UserID: user.ID, UserName: user.UserName, By: uid}
+	if approval {
+		s.Chat.Publish(core.NewEvent(schema.EventJoinRequested, invite.ChatID, ctx.GetString("requestID"), joined))
+		ctx.JSON(http.StatusAccepted, gin.H{"msg": "Join request sent, an admin of the group will review it."})
+		return
+	}
+
+	s.Chat.SubscribeUser(invite.ChatID, user.ID)
+	s.Chat.Publish(core.NewEvent(schema.EventMemberJoined, invite.ChatID, ctx.GetString("requestID"), joined))
+	s.systemMessage(ctx, invite.ChatID, uid, user.UserName+" joined with an invite link")
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "You joined the group.", "chat_id": invite.ChatID})
+}
+
+// ViewJoinRequests godoc
+// @Summary    View the join requests of a group
+// @Description An endpoint for the admins of a group to view the users waiting for approval, oldest first
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param id query string true "Chat ID"
+// @Security BearerAuth
+// @Success 200 {object} schema.DocViewJoinRequests "Join requests"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/view-join-requests [get]
+func (s *Service) ViewJoinRequests(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	cid := ctx.Query("id")
+	if !model.IsValidUUID(cid) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid chat id."})
+		return
+	}
+
+	var chat model.Chat
+	var member model.ChatUser
+	if err := s.groupAdmin(ctx, cid, uid, &chat, &member); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	var reqs []model.ChatJoinRequest
+	if err := s.DB.WithContext(ctx).FetchJoinRequests(chat.ID, &reqs); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	views := make([]schema.ViewJoinRequest, len(reqs))
+	for i, r := range reqs {
+		views[i] = schema.ViewJoinRequest{UserID: r.UserID, Requested: r.CreatedAt}
+		if r.User != nil {
+			views[i].Username = r.User.UserName
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": views})
+}
+
+// ReviewJoinRequest godoc
+// @Summary    Approve or decline a join request
+// @Description An endpoint for the admins of a group to approve (adding the user to the group) or decline a join request
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param payload body schema.ReviewJoin true "Review payload"
+// @Security BearerAuth
+// @Success 202 {object} schema.DocNormalResponse "Request reviewed"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/review-join [patch]
+func (s *Service) ReviewJoinRequest(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var payload schema.ReviewJoin
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
+		return
+	}
+
+	var chat model.Chat
+	var member model.ChatUser
+	if err := s.groupAdmin(ctx, payload.ChatID, uid, &chat, &member); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	var req model.ChatJoinRequest
+	if err := s.DB.WithContext(ctx).FetchJoinRequest(chat.ID, payload.UserID, &req); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).ReviewJoinRequest(&req, *payload.Approve); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	if !*payload.Approve {
+		ctx.JSON(http.StatusAccepted, gin.H{"msg": "Join request declined."})
+		return
+	}
+
+	s.Chat.SubscribeUser(chat.ID, req.UserID)
+	s.Chat.Publish(core.NewEvent(schema.EventMemberJoined, chat.ID, ctx.GetString("requestID"), schema.WSMember{
+		UserID:   req.UserID,
+		UserName: req.User.UserName,
+		By:       uid,
+	}))
+	s.systemMessage(ctx, chat.ID, uid, fmt.Sprintf("%s approved %s to join", member.User.UserName, req.User.UserName))
+
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Join request approved."})
+}
+
+// inviteView -> View of an invite link
+func inviteView(invite *model.ChatInvite) schema.ViewInvite {
+	return schema.ViewInvite{
+		ID:        invite.ID,
+		Token:     invite.Token,
+		ExpiresAt: invite.ExpiresAt,
+		MaxUses:   invite.MaxUses,
+		Uses:      invite.Uses,
+		Created:   invite.CreatedAt,
+	}
+}
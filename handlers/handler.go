@@ -2,30 +2,52 @@ package handlers
 
 import (
 	"net/http"
+	"os"
 
 	"findme/core"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type Service struct {
-	DB     core.DB
-	RDB    core.Cache
-	Email  core.Email
-	Git    Git
-	Transc Transc
-	Emb    core.Embedding
-	Rec    core.Recommendation
-	Chat   *core.ChatHub
-	Client *http.Client
-	Cron   core.CronWorker
+	DB      core.DB
+	RDB     core.Cache
+	Email   core.Email
+	Git     Git
+	Transc  Transc
+	Emb     core.Embedding
+	Rec     core.Recommendation
+	Chat    *core.ChatHub
+	Client  *http.Client
+	Cron    core.CronWorker
+	Storage core.Storage
+	Notify  core.Notifier
+	Hooks   core.Webhooks
+
+	health healthProbes
 }
 
-func NewService(db core.DB, rdb core.Cache, email core.Email, git Git, transc Transc, embHub core.Embedding, recHub core.Recommendation, client *http.Client, chat *core.ChatHub, cron core.CronWorker) *Service {
-	return &Service{DB: db, RDB: rdb, Email: email, Git: git, Transc: transc, Emb: embHub, Rec: recHub, Client: client, Chat: chat, Cron: cron}
+func NewService(db core.DB, rdb core.Cache, email core.Email, git Git, transc Transc, embHub core.Embedding, recHub core.Recommendation, client *http.Client, chat *core.ChatHub, cron core.CronWorker, storage core.Storage, notify core.Notifier, hooks core.Webhooks) *Service {
+	return &Service{DB: db, RDB: rdb, Email: email, Git: git, Transc: transc, Emb: embHub, Rec: recHub, Client: client, Chat: chat, Cron: cron, Storage: storage, Notify: notify, Hooks: hooks}
 }
 
 func SetupHandler(router *gin.Engine, service *Service) {
+	// Lets the gin context be passed down as a context.Context carrying the request span
+	router.ContextWithFallback = true
+	router.Use(otelgin.Middleware("findme"), RequestID(), AccessLog(), Metrics())
+
+	// Metrics are only served on the public router when a token is set, see METRICS_ADDR for an internal listener
+	if token := os.Getenv("METRICS_TOKEN"); token != "" {
+		router.GET("/metrics", MetricsHandler(token))
+	}
+
+	// The email templates rendered with sample data, meant for development so only served when EMAIL_PREVIEW is set
+	if os.Getenv("EMAIL_PREVIEW") == "true" {
+		router.GET("/email-preview", EmailTemplates)
+		router.GET("/email-preview/:name", PreviewEmail)
+	}
+
 	router.GET("/", func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, gin.H{"message": "APP is up and running"})
 	})
@@ -35,6 +57,8 @@ func SetupHandler(router *gin.Engine, service *Service) {
 	})
 
 	router.GET("/health/detailed", service.DetailedHealth)
+	router.GET("/livez", service.Liveness)
+	router.GET("/readyz", service.Readiness)
 
 	router.POST("/signup", service.AddUser)
 	router.POST("/login", service.VerifyUser)
@@ -44,8 +68,13 @@ func SetupHandler(router *gin.Engine, service *Service) {
 
 	router.POST("/forgot-password", service.ForgotPassword)
 	router.GET("/verify-otp", service.VerifyOTP)
+	router.GET("/unsubscribe", service.UnsubscribePage)
+	router.POST("/unsubscribe", service.Unsubscribe)
 
 	router.POST("/api/transc/webhook", service.Transc.VerifyTranscWebhook)
+	// Loaded by img tags and links, which can't send the auth header, so the url itself is signed
+	router.GET("/api/msg/attachment/:id", service.DownloadAttachment)
+	router.GET("/api/msg/export/:id", service.DownloadExport)
 
 	protectedUserRoutes := router.Group("/api/user")
 	protectedProjectRoutes := router.Group("/api/post")
@@ -68,20 +97,35 @@ func SetupHandler(router *gin.Engine, service *Service) {
 	protectedUserRoutes.GET("/recommend", service.RecommendProjects)
 	protectedUserRoutes.GET("/view-subs", service.ViewSubscriptions)
 	protectedUserRoutes.GET("/payment-info", service.GetUserPaymentInfo)
+	protectedUserRoutes.GET("/view-notifications", service.ViewNotifications)
+	protectedUserRoutes.GET("/unread-notifications", service.UnreadNotifications)
+	protectedUserRoutes.GET("/view-notification-preferences", service.ViewNotificationPreferences)
+	protectedUserRoutes.GET("/view-webhooks", service.ViewWebhooks)
+	protectedUserRoutes.GET("/view-webhook-deliveries", service.ViewWebhookDeliveries)
 	protectedUserRoutes.POST("/send-user-req", service.SendFriendReq)
 	protectedUserRoutes.POST("/connect-github", service.Git.ConnectGitHub)
+	protectedUserRoutes.POST("/create-webhook", service.CreateWebhook)
+	protectedUserRoutes.POST("/redeliver-webhook", service.RedeliverWebhook)
 	protectedUserRoutes.PUT("/update-profile", service.UpdateUserInfo)
 	protectedUserRoutes.PATCH("/update-user-req", service.UpdateFriendReqStatus)
 	protectedUserRoutes.PATCH("/update-password", service.UpdateUserPassword)
 	protectedUserRoutes.PATCH("/update-availability/:status", service.UpdateUserAvaibilityStatus)
+	protectedUserRoutes.PATCH("/update-presence-visibility/:visible", service.UpdatePresenceVisibility)
+	protectedUserRoutes.PATCH("/update-digest/:frequency", service.UpdateDigestFrequency)
+	protectedUserRoutes.PATCH("/update-notification-preference", service.UpdateNotificationPreference)
+	protectedUserRoutes.PATCH("/update-webhook", service.UpdateWebhook)
 	protectedUserRoutes.PATCH("/update-bio", service.UpdateUserBio)
 	protectedUserRoutes.PATCH("/update-interest", service.UpdateUserInterests)
 	protectedUserRoutes.PATCH("/update-skills", service.UpdateUserSkills)
 	protectedUserRoutes.PATCH("/reset-password", service.ResetPassword)
+	protectedUserRoutes.PATCH("/read-notification", service.ReadNotification)
+	protectedUserRoutes.PATCH("/read-all-notifications", service.ReadAllNotifications)
 	protectedUserRoutes.DELETE("/delete-skills", service.DeleteUserSkills)
 	protectedUserRoutes.DELETE("/delete-user", service.DeleteUserAccount)
 	protectedUserRoutes.DELETE("/delete-friend-req", service.DeleteSentReq)
 	protectedUserRoutes.DELETE("/delete-user-friend", service.DeleteUserFriend)
+	protectedUserRoutes.DELETE("/delete-notification", service.DeleteNotification)
+	protectedUserRoutes.DELETE("/delete-webhook", service.DeleteWebhook)
 
 	protectedTranscRoutes.GET("/view", service.Transc.GetTransactions)
 	protectedTranscRoutes.GET("/initialize", service.Transc.InitializeTransaction)
@@ -91,17 +135,41 @@ func SetupHandler(router *gin.Engine, service *Service) {
 	protectedTranscRoutes.PATCH("/cancel-sub", service.Transc.CancelSubscription)
 
 	protectedMsgRoutes.GET("/ws/chat", service.WSChat)
+	protectedMsgRoutes.GET("/sse", service.SSEChat)
 
 	protectedMsgRoutes.GET("/view-hist", service.ViewMessages)
 	protectedMsgRoutes.GET("/view-chats", service.FetchUserChats)
+	protectedMsgRoutes.GET("/search", service.SearchMessages)
+	protectedMsgRoutes.GET("/view-thread", service.ViewThread)
+	protectedMsgRoutes.GET("/view-edits", service.ViewEdits)
+	protectedMsgRoutes.GET("/view-members", service.ViewMembers)
+	protectedMsgRoutes.GET("/view-invites", service.ViewInvites)
+	protectedMsgRoutes.GET("/view-join-requests", service.ViewJoinRequests)
+	protectedMsgRoutes.GET("/export", service.ExportChat)
+	protectedMsgRoutes.POST("/export-email", service.EmailExport)
 	protectedMsgRoutes.GET("/open-chat", service.OpenChat)
 	protectedMsgRoutes.POST("/send-message", service.CreateMessage)
+	protectedMsgRoutes.POST("/react", service.ReactMessage)
+	protectedMsgRoutes.POST("/upload", service.UploadAttachment)
+	protectedMsgRoutes.POST("/create-invite", service.CreateInvite)
+	protectedMsgRoutes.POST("/join/:token", service.JoinChat)
 	protectedMsgRoutes.PUT("/add-user", service.AddUserToChat)
 	protectedMsgRoutes.PATCH("/edit-message", service.EditMessage)
 	protectedMsgRoutes.PATCH("/rename-chat", service.RenameChat)
 	protectedMsgRoutes.PATCH("/transfer-owner", service.TransferOwner)
+	protectedMsgRoutes.PATCH("/set-role", service.SetMemberRole)
+	protectedMsgRoutes.PATCH("/pin-message", service.PinMessage)
+	protectedMsgRoutes.PATCH("/join-approval", service.SetJoinApproval)
+	protectedMsgRoutes.PATCH("/review-join", service.ReviewJoinRequest)
+	protectedMsgRoutes.PATCH("/mark-read", service.MarkRead)
+	protectedMsgRoutes.PATCH("/mute-chat", service.MuteChat)
+	protectedMsgRoutes.PATCH("/archive-chat", service.ArchiveChat)
+	protectedMsgRoutes.PATCH("/pin-chat", service.PinChat)
+	protectedMsgRoutes.PATCH("/notify-level", service.SetNotifyLevel)
 	protectedMsgRoutes.DELETE("/delete-message", service.DeleteMessage)
+	protectedMsgRoutes.DELETE("/unreact", service.UnreactMessage)
 	protectedMsgRoutes.DELETE("/remove-user", service.RemoveUserChat)
+	protectedMsgRoutes.DELETE("/revoke-invite", service.RevokeInvite)
 	protectedMsgRoutes.DELETE("/leave-chat", service.LeaveChat)
 	protectedMsgRoutes.DELETE("/delete-chat", service.DeleteChat)
 
@@ -2,14 +2,77 @@ package handlers
 
 import (
 	"net/http"
+	"sync"
 	"time"
 
+	"findme/core"
+
 	"github.com/gin-gonic/gin"
 )
 
+// checkResult -> Builds the entry of a single dependency check
+func checkResult(err error, extra gin.H) gin.H {
+	res := gin.H{"status": "healthy"}
+	if err != nil {
+		res["status"] = "unhealthy"
+		res["error"] = err.Error()
+	}
+	for k, v := range extra {
+		res[k] = v
+	}
+	return res
+}
+
+// checkDownstream -> Probes a gRPC service unless its circuit is already open
+func checkDownstream(circuit string, probe func() error) error {
+	if circuit == core.CircuitOpen {
+		return core.ErrCircuitOpen
+	}
+	return probe()
+}
+
+// healthTTL -> How long the results of the dependency probes are reused, so requests to the unauthenticated detailed
+// health endpoint can't open a session with the database, the ML services and the SMTP server each
+const healthTTL = 30 * time.Second
+
+// healthProbes -> The last results of the dependency probes, shared by the requests until they are older than healthTTL
+type healthProbes struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	errs      map[string]error
+}
+
+// results -> Returns the cached results, running every probe at once when they are stale. The requests arriving during
+// a probe wait for it instead of starting their own
+func (p *healthProbes) results(probes map[string]func() error) (map[string]error, time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.errs != nil && time.Since(p.checkedAt) < healthTTL {
+		return p.errs, p.checkedAt
+	}
+
+	errs := make(map[string]error, len(probes))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, probe := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := probe()
+			mu.Lock()
+			errs[name] = err
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	p.errs, p.checkedAt = errs, time.Now()
+	return p.errs, p.checkedAt
+}
+
 // DetailedHealth godoc
 // @Summary Checks the health of the services running
-// @Description This gives a detailed health status of the running services
+// @Description This gives a detailed health status of the running services. The dependencies are probed concurrently and the results are reused for 30 seconds, checked_at tells when they were taken
 // @Tags Health
 // @Accept json
 // @Produce json
@@ -17,38 +80,41 @@ import (
 // @Failure 503 {object} map[string]any "Service Degraded"
 // @Router /health/detailed [get]
 func (s *Service) DetailedHealth(ctx *gin.Context) {
-	health := gin.H{
-		"status":    "ok",
-		"timestamp": time.Now().Unix(),
-		"checks":    gin.H{},
-	}
+	// An open circuit counts as down without waiting on the probe
+	errs, checkedAt := s.health.results(map[string]func() error{
+		"database":       s.DB.CheckHealth,
+		"cache":          s.RDB.CheckHealth,
+		"embedding":      func() error { return checkDownstream(s.Emb.CircuitState(), s.Emb.CheckHealth) },
+		"recommendation": func() error { return checkDownstream(s.Rec.CircuitState(), s.Rec.CheckHealth) },
+		"email":          s.Email.CheckHealth,
+	})
 
+	checks := gin.H{}
 	overallHealthy := true
-
-	// Check Database
-	if err := s.DB.CheckHealth(); err != nil {
-		health["checks"].(gin.H)["database"] = gin.H{
-			"status": "unhealthy",
-			"error":  err.Error(),
-		}
-		overallHealthy = false
-	} else {
-		health["checks"].(gin.H)["database"] = gin.H{
-			"status": "healthy",
+	for _, err := range errs {
+		if err != nil {
+			overallHealthy = false
 		}
 	}
 
-	// Check Redis
-	if err := s.RDB.CheckHealth(); err != nil {
-		health["checks"].(gin.H)["cache"] = gin.H{
-			"status": "unhealthy",
-			"error":  err.Error(),
-		}
-		overallHealthy = false
-	} else {
-		health["checks"].(gin.H)["cache"] = gin.H{
-			"status": "healthy",
-		}
+	checks["database"] = checkResult(errs["database"], nil)
+	checks["cache"] = checkResult(errs["cache"], nil)
+	checks["embedding"] = checkResult(errs["embedding"], gin.H{"circuit": s.Emb.CircuitState(), "queue": s.Emb.Stats()})
+	checks["recommendation"] = checkResult(errs["recommendation"], gin.H{"circuit": s.Rec.CircuitState(), "queue": s.Rec.Stats()})
+	checks["email"] = checkResult(errs["email"], gin.H{"queue": s.Email.Stats()})
+	checks["chat"] = checkResult(nil, gin.H{"queue": s.Chat.Stats()})
+
+	lastRuns := gin.H{}
+	for job, at := range s.Cron.LastRuns() {
+		lastRuns[job] = at.Unix()
+	}
+	checks["cron"] = checkResult(nil, gin.H{"last_runs": lastRuns})
+
+	health := gin.H{
+		"status":     "ok",
+		"timestamp":  time.Now().Unix(),
+		"checked_at": checkedAt.Unix(),
+		"checks":     checks,
 	}
 
 	if !overallHealthy {
@@ -59,3 +125,38 @@ func (s *Service) DetailedHealth(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, health)
 }
+
+// Liveness godoc
+// @Summary Checks that the app process is up
+// @Description Liveness probe, only reports that the process is running and serving requests
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]any "Alive"
+// @Router /livez [get]
+func (s *Service) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness godoc
+// @Summary Checks that the app can serve traffic
+// @Description Readiness probe, reports ready only when the database and cache are reachable
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]any "Ready"
+// @Failure 503 {object} map[string]any "Not ready"
+// @Router /readyz [get]
+func (s *Service) Readiness(ctx *gin.Context) {
+	checks := gin.H{
+		"database": checkResult(s.DB.CheckHealth(), nil),
+		"cache":    checkResult(s.RDB.CheckHealth(), nil),
+	}
+
+	for _, check := range checks {
+		if check.(gin.H)["status"] != "healthy" {
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "checks": checks})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+}
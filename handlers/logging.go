@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"log/slog"
+	"regexp"
+	"time"
+
+	"findme/core"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern -> Incoming ids are only trusted when they look like the ones nginx or we generate
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9\-_.]{8,128}$`)
+
+// RequestID -> Tags the request with the id set by nginx (or a new one) and adds it to the request logs
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = uuid.NewString()
+		}
+
+		ctx.Set("requestID", id)
+		ctx.Header(RequestIDHeader, id)
+		ctx.Request = ctx.Request.WithContext(core.WithLogFields(ctx.Request.Context(),
+			"request_id", id,
+			"method", ctx.Request.Method,
+			"route", ctx.FullPath(),
+		))
+
+		ctx.Next()
+	}
+}
+
+// AccessLog -> Logs every request once it is served, server errors at error level
+func AccessLog() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		slog.Log(ctx.Request.Context(), level, "Request served",
+			"component", "http",
+			"path", ctx.Request.URL.Path,
+			"status", status,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"client_ip", ctx.ClientIP(),
+			"size", ctx.Writer.Size(),
+		)
+	}
+}
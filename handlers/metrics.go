@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"findme/core"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics -> Records the count and latency of every request per route and status
+func Metrics() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		// Unmatched paths are collapsed so scanners can't blow up the label cardinality
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(ctx.Writer.Status())
+
+		core.HTTPRequests.WithLabelValues(ctx.Request.Method, route, status).Inc()
+		core.HTTPDuration.WithLabelValues(ctx.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// MetricsHandler -> Serves the prometheus metrics behind a static bearer token
+func MetricsHandler(token string) gin.HandlerFunc {
+	handler := promhttp.Handler()
+	return func(ctx *gin.Context) {
+		bearer, found := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid metrics token!"})
+			return
+		}
+		handler.ServeHTTP(ctx.Writer, ctx.Request)
+	}
+}
@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"findme/core"
 	"findme/model"
@@ -16,293 +21,1145 @@ import (
 // @Tags Msg
 // @Accept json
 // @Produce json
-// @Param payload body schema.NewMessage true "Message payload"
+// @Param payload body schema.NewMessage true "Message payload, the files are uploaded beforehand through /api/msg/upload"
 // @Security BearerAuth
 // @Success 201 {object} schema.DocMsgResponse "Message Sent"
 // @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/send-message [post]
+func (s *Service) CreateMessage(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var payload schema.NewMessage
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
+		return
+	}
+
+	// Stored the same way as the messages sent over the socket
+	payload.Message = strings.TrimSpace(payload.Message)
+	if payload.Message == "" && len(payload.Attachments) == 0 {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Message can't be empty."})
+		return
+	}
+
+	var chat model.Chat
+	if err := s.DB.WithContext(ctx).FetchChat(payload.ChatID, &chat); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).CheckChatMember(chat.ID, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	msg := model.UserMessage{
+		ChatID:  payload.ChatID,
+		Message: payload.Message,
+		FromID:  uid,
+	}
+
+	if payload.ReplyToID != "" {
+		var quoted model.UserMessage
+		if !model.IsValidUUID(payload.ReplyToID) || s.DB.WithContext(ctx).FetchMsg(&quoted, payload.ReplyToID) != nil || quoted.ChatID != chat.ID {
+			ctx.JSON(http.StatusNotFound, gin.H{"msg": "Replied message not found."})
+			return
+		}
+		msg.ReplyToID = &quoted.ID
+	}
+
+	var err error
+	var attachments []model.Attachment
+	if len(payload.Attachments) > 0 {
+		if err = s.DB.WithContext(ctx).AddMessageAttachments(&msg, payload.Attachments); err == nil {
+			err = s.DB.WithContext(ctx).FetchAttachments([]string{msg.ID}, &attachments)
+		}
+	} else {
+		err = s.DB.WithContext(ctx).AddMessage(&msg)
+	}
+	if err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	mesRes := schema.ViewMessage{
+		ID:          msg.ID,
+		Message:     msg.Message,
+		UserID:      uid,
+		Sent:        msg.CreatedAt,
+		Edited:      msg.UpdatedAt,
+		ReplyToID:   msg.ReplyToID,
+		Attachments: core.AttachmentViews(attachments),
+		Mentions:    s.Chat.Mentions(ctx, &msg),
+	}
+
+	// The other members get the attachments without urls, as those are signed for the sender
+	s.Chat.Publish(core.NewEvent(schema.EventMessageNew, chat.ID, ctx.GetString("requestID"), mesRes))
+
+	mesRes.Attachments = s.attachmentViews(uid, attachments)
+	ctx.JSON(http.StatusCreated, gin.H{"msg": mesRes})
+}
+
+// ViewMessages godoc
+// @Summary    View the messages in a chat
+// @Description An endpoint to view a page of the chat history, oldest first. Without a cursor the page holds the latest messages, with before (or after) the messages right before (or after) that message
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param id query string true "Chat ID"
+// @Param before query string false "Msg ID to read the history before"
+// @Param after query string false "Msg ID to read the history after"
+// @Param limit query int false "Page size, 50 by default and 100 at most"
+// @Security BearerAuth
+// @Success 200 {object} schema.DocViewChatHistory "Chat history"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/view-hist [get]
+func (s *Service) ViewMessages(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	cid, before, after := ctx.Query("id"), ctx.Query("before"), ctx.Query("after")
+	if !model.IsValidUUID(cid) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid chat id."})
+		return
+	}
+	if (before != "" && !model.IsValidUUID(before)) || (after != "" && !model.IsValidUUID(after)) || (before != "" && after != "") {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid cursor, use either before or after with a message id."})
+		return
+	}
+
+	limit, ok := pageLimit(ctx, historyPage, 100)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid limit."})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).CheckChatMember(cid, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	var chat model.Chat
+	if err := s.DB.WithContext(ctx).FetchChatPreloadU(cid, &chat); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	// One more message than the page tells whether the history goes on
+	var msgs []model.UserMessage
+	if err := s.DB.WithContext(ctx).FetchChatMessages(cid, uid, before, after, limit+1, &msgs); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	var hist schema.ViewChat
+	hist.CID = cid
+	hist.Group = chat.Group
+	hist.HasMore = len(msgs) > limit
+	if hist.HasMore {
+		if after != "" {
+			msgs = msgs[:limit]
+		} else {
+			msgs = msgs[1:]
+		}
+	}
+	if hist.Group {
+		hist.Name = chat.Name
+	} else {
+		if chat.Users[0].ID == uid {
+			hist.Name = chat.Users[1].UserName
+		} else {
+			hist.Name = chat.Users[0].UserName
+		}
+	}
+
+	// Read cursors of the group members, a member has seen every message sent up to their cursor
+	var cursors []model.ChatUser
+	if hist.Group {
+		if err := s.DB.WithContext(ctx).FetchReadCursors(cid, &cursors); err != nil {
+			cm := err.(*core.CustomMessage)
+			ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+			return
+		}
+	}
+
+	views, err := s.messageViews(ctx, uid, msgs)
+	if err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	for i, msg := range msgs {
+		for _, cursor := range cursors {
+			if cursor.UserID != msg.FromID && cursor.LastReadAt != nil && !cursor.LastReadAt.Before(msg.CreatedAt) {
+				views[i].SeenBy = append(views[i].SeenBy, cursor.UserID)
+			}
+		}
+	}
+	hist.Message = views
+
+	if hist.Group {
+		for _, cursor := range cursors {
+			if cursor.UserID == uid {
+				hist.Role = cursor.Role
+			}
+		}
+		hist.JoinApproval = chat.JoinApproval
+
+		var pinned model.UserMessage
+		if chat.PinnedMsgID != nil && s.DB.WithContext(ctx).FetchMsg(&pinned, *chat.PinnedMsgID) == nil && !pinned.IsRemoved() {
+			view := messageView(&pinned)
+			hist.Pinned = &view
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": hist})
+}
+
+// messageViews -> Builds the views of messages with their sender, reply count and reactions as seen by the user
+func (s *Service) messageViews(ctx *gin.Context, uid string, msgs []model.UserMessage) ([]schema.ViewMessage, error) {
+	ids := make([]string, len(msgs))
+	for i, msg := range msgs {
+		ids[i] = msg.ID
+	}
+
+	var replies []model.ReplyCount
+	if err := s.DB.WithContext(ctx).FetchReplyCounts(ids, &replies); err != nil {
+		return nil, err
+	}
+	var reactions []model.ReactionCount
+	if err := s.DB.WithContext(ctx).FetchReactionCounts(ids, uid, &reactions); err != nil {
+		return nil, err
+	}
+	var attachments []model.Attachment
+	if err := s.DB.WithContext(ctx).FetchAttachments(ids, &attachments); err != nil {
+		return nil, err
+	}
+	var mentions []model.MessageMention
+	if err := s.DB.WithContext(ctx).FetchMentions(ids, &mentions); err != nil {
+		return nil, err
+	}
+
+	replyCounts := make(map[string]int64, len(replies))
+	for _, count := range replies {
+		replyCounts[count.MsgID] = count.Count
+	}
+	reactionCounts := make(map[string][]schema.ReactionCount)
+	for _, count := range reactions {
+		reactionCounts[count.MsgID] = append(reactionCounts[count.MsgID], schema.ReactionCount{
+			Emoji: count.Emoji,
+			Count: count.Count,
+			Me:    count.Mine,
+		})
+	}
+
+	files := make(map[string][]model.Attachment)
+	for _, a := range attachments {
+		files[*a.MsgID] = append(files[*a.MsgID], a)
+	}
+	mentioned := make(map[string][]schema.ViewMention)
+	for _, m := range mentions {
+		mentioned[m.MsgID] = append(mentioned[m.MsgID], schema.ViewMention{UserID: m.UserID, Username: m.User.UserName})
+	}
+
+	views := make([]schema.ViewMessage, len(msgs))
+	for i, msg := range msgs {
+		views[i] = messageView(&msg)
+		views[i].Replies = replyCounts[msg.ID]
+		if msg.IsRemoved() {
+			continue
+		}
+		views[i].Reactions = reactionCounts[msg.ID]
+		views[i].Mentions = mentioned[msg.ID]
+		for _, m := range views[i].Mentions {
+			views[i].Mentioned = views[i].Mentioned || m.UserID == uid
+		}
+		if len(files[msg.ID]) > 0 {
+			views[i].Attachments = s.attachmentViews(uid, files[msg.ID])
+		}
+	}
+	return views, nil
+}
+
+// messageView -> View of a single message, without its replies, reactions and files. The messages deleted for
+// everyone only keep their tombstone
+func messageView(msg *model.UserMessage) schema.ViewMessage {
+	view := schema.ViewMessage{
+		ID:        msg.ID,
+		UserID:    msg.FromID,
+		Sent:      msg.CreatedAt,
+		Edited:    msg.UpdatedAt,
+		IsEdited:  msg.EditedAt != nil,
+		System:    msg.System,
+		Message:   msg.Message,
+		ReplyToID: msg.ReplyToID,
+	}
+	if msg.FromUser != nil {
+		view.Username = msg.FromUser.UserName
+	}
+	if msg.IsRemoved() {
+		view.Message, view.Deleted = deletedMessage, true
+	}
+	return view
+}
+
+// ViewThread godoc
+// @Summary    View the thread of a message
+// @Description An endpoint to view a message along with all the replies to it, oldest first
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param id query string true "Msg ID"
+// @Security BearerAuth
+// @Success 200 {object} schema.DocViewThread "Message thread"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/view-thread [get]
+func (s *Service) ViewThread(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	mid := ctx.Query("id")
+	if !model.IsValidUUID(mid) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid message id."})
+		return
+	}
+
+	var root model.UserMessage
+	if err := s.DB.WithContext(ctx).FetchMsg(&root, mid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).CheckChatMember(root.ChatID, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	var replies []model.UserMessage
+	if err := s.DB.WithContext(ctx).FetchReplies(root.ID, &replies); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	views, err := s.messageViews(ctx, uid, append([]model.UserMessage{root}, replies...))
+	if err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": schema.ViewThread{Root: views[0], Replies: views[1:]}})
+}
+
+// ReactMessage godoc
+// @Summary    React to a message
+// @Description An endpoint for reacting to a message with an emoji, a user reacts at most once with each emoji
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param payload body schema.React true "Reaction payload"
+// @Security BearerAuth
+// @Success 201 {object} schema.DocNormalResponse "Reaction added"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid reaction"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 409 {object} schema.DocNormalResponse "Reaction already added"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/react [post]
+func (s *Service) ReactMessage(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var payload schema.React
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Invalid payload."})
+		return
+	}
+
+	s.react(ctx, uid, payload.MsgID, payload.Emoji, true)
+}
+
+// UnreactMessage godoc
+// @Summary    Remove a reaction from a message
+// @Description An endpoint for removing the reaction of the current user with an emoji from a message
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param id query string true "Msg ID"
+// @Param emoji query string true "Emoji"
+// @Security BearerAuth
+// @Success 204 {object} nil "Reaction removed"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid reaction"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/unreact [delete]
+func (s *Service) UnreactMessage(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	s.react(ctx, uid, ctx.Query("id"), ctx.Query("emoji"), false)
+}
+
+// react -> Adds (or removes) the reaction of a member to a message and tells the chat
+func (s *Service) react(ctx *gin.Context, uid, mid, emoji string, add bool) {
+	if !model.IsValidUUID(mid) || !model.IsValidEmoji(emoji) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid reaction."})
+		return
+	}
+
+	var msg model.UserMessage
+	if err := s.DB.WithContext(ctx).FetchMsg(&msg, mid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).CheckChatMember(msg.ChatID, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	if msg.IsRemoved() {
+		ctx.JSON(http.StatusNotFound, gin.H{"msg": "Message not found."})
+		return
+	}
+
+	var err error
+	event := schema.EventReactionAdded
+	if add {
+		err = s.DB.WithContext(ctx).AddReaction(&model.MessageReaction{MsgID: msg.ID, UserID: uid, Emoji: emoji})
+	} else {
+		event = schema.EventReactionRemoved
+		err = s.DB.WithContext(ctx).RemoveReaction(msg.ID, uid, emoji)
+	}
+	if err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	s.Chat.Publish(core.NewEvent(event, msg.ChatID, ctx.GetString("requestID"), schema.WSReaction{MsgID: msg.ID, UserID: uid, Emoji: emoji}))
+
+	if add {
+		ctx.JSON(http.StatusCreated, gin.H{"msg": "Reaction added."})
+	} else {
+		ctx.JSON(http.StatusNoContent, nil)
+	}
+}
+
+// SearchMessages godoc
+// @Summary    Search messages
+// @Description An endpoint for searching the messages of a chat, or of every chat of the current user when no chat id is given. Supports the web search syntax ("quoted phrases", -excluded words, or), the best matches come first
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param q query string true "Search"
+// @Param id query string false "Chat ID"
+// @Param limit query int false "Number of results, 20 by default and 50 at most"
+// @Security BearerAuth
+// @Success 200 {object} schema.DocSearchMessages "Matching messages"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid search"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/search [get]
+func (s *Service) SearchMessages(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	query, cid := strings.TrimSpace(ctx.Query("q")), ctx.Query("id")
+	if query == "" || len(query) > 200 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid search."})
+		return
+	}
+	if cid != "" && !model.IsValidUUID(cid) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid chat id."})
+		return
+	}
+
+	limit, ok := pageLimit(ctx, 20, 50)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid limit."})
+		return
+	}
+
+	if cid != "" {
+		if err := s.DB.WithContext(ctx).CheckChatMember(cid, uid); err != nil {
+			cm := err.(*core.CustomMessage)
+			ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+			return
+		}
+	}
+
+	var hits []model.MessageHit
+	if err := s.DB.WithContext(ctx).SearchMessages(uid, cid, query, limit, &hits); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	results := make([]schema.SearchMessage, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, schema.SearchMessage{
+			ID:        hit.ID,
+			ChatID:    hit.ChatID,
+			UserID:    hit.FromID,
+			Username:  hit.FromName,
+			Message:   hit.Message,
+			Highlight: hit.Highlight,
+			Sent:      hit.CreatedAt,
+			Edited:    hit.UpdatedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": results})
+}
+
+// pageLimit -> Reads the limit query param, def when missing, false when it isn't a number between 1 and max
+func pageLimit(ctx *gin.Context, def, max int) (int, bool) {
+	raw := ctx.Query("limit")
+	if raw == "" {
+		return def, true
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > max {
+		return 0, false
+	}
+	return limit, true
+}
+
+// FetchUserChats godoc
+// @Summary    Fetch the current user chats
+// @Description An endpoint for fetching the chats of the current user, the pinned ones first by their position then the most recently active, archived chats are only listed with archived=true
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param archived query bool false "List the archived chats instead"
+// @Security BearerAuth
+// @Success 200 {object} schema.DocViewAllChats "User chats"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/view-chats [get]
+func (s *Service) FetchUserChats(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var summaries []model.ChatSummary
+	if err := s.DB.WithContext(ctx).FetchChatSummaries(uid, ctx.Query("archived") == "true", &summaries); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	// The other members of the direct chats, whose presence is shown along the chat
+	peers := make(map[string]*model.User)
+	var peerList []*model.User
+	for _, sum := range summaries {
+		if !sum.IsGroup && sum.PeerID != nil {
+			peer := &model.User{UserName: *sum.PeerName, LastSeen: sum.PeerLastSeen}
+			peer.ID = *sum.PeerID
+			peer.HidePresence = sum.PeerHidden != nil && *sum.PeerHidden
+			peers[sum.ChatID] = peer
+			peerList = append(peerList, peer)
+		}
+	}
+	presence := s.presenceOf(ctx, peerList)
+
+	chats := make([]schema.ViewChat, 0, len(summaries))
+	for _, sum := range summaries {
+		chat := schema.ViewChat{
+			Name:           sum.Name,
+			CID:            sum.ChatID,
+			Group:          sum.IsGroup,
+			Unread:         sum.Unread,
+			Archived:       sum.Archived,
+			UnreadMentions: sum.UnreadMentions,
+			PinPosition:    sum.PinPosition,
+			NotifyLevel:    sum.NotifyLevel,
+		}
+		if sum.MutedUntil != nil && sum.MutedUntil.After(time.Now()) {
+			chat.MutedUntil = sum.MutedUntil
+		}
+		if peer, ok := peers[sum.ChatID]; ok {
+			chat.Name = peer.UserName
+			chat.Presence = presence[peer.ID]
+			chat.LastSeen = lastSeen(peer)
+		}
+		if sum.LastMsgID != nil {
+			last := schema.ViewMessage{
+				ID:       *sum.LastMsgID,
+				Message:  *sum.LastMsg,
+				UserID:   *sum.LastFromID,
+				Username: *sum.LastFromName,
+				Sent:     *sum.LastSent,
+				Edited:   *sum.LastEdited,
+				IsEdited: sum.LastEditedAt != nil,
+			}
+			if sum.LastRemovedAt != nil {
+				last.Message, last.Deleted = deletedMessage, true
+			}
+			chat.Message = []schema.ViewMessage{last}
+			chat.Preview = preview(last.Message)
+		}
+		chats = append(chats, chat)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": chats})
+}
+
+// previewLength -> Number of characters of the last message shown in the chat list
+const previewLength = 100
+
+// historyPage -> Number of messages in a page of a chat history, unless view-hist is given another limit
+const historyPage = 50
+
+// deletedMessage -> Text shown in place of the messages deleted for everyone
+const deletedMessage = "Message deleted."
+
+// preview -> Shortens a message to the chat list preview
+func preview(msg string) string {
+	runes := []rune(msg)
+	if len(runes) <= previewLength {
+		return msg
+	}
+	return string(runes[:previewLength]) + "…"
+}
+
+// EditMessage godoc
+// @Summary    Editing a sent message
+// @Description An endpoint for editing a sent message
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param payload body schema.EditMessage true "Message payload"
+// @Security BearerAuth
+// @Success 202 {object} schema.DocMsgResponse "Message edited"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
 // @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/edit-message [patch]
+func (s *Service) EditMessage(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var payload schema.EditMessage
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
+		return
+	}
+
+	var msg model.UserMessage
+	if err := s.DB.WithContext(ctx).FetchMsg(&msg, payload.ID); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	// Members who left or were removed can't change what the chat sees anymore
+	if err := s.DB.WithContext(ctx).CheckChatMember(msg.ChatID, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	if msg.FromID != uid || msg.System {
+		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You cannot edit a message that's not owned by you."})
+		return
+	}
+	if msg.IsRemoved() {
+		ctx.JSON(http.StatusNotFound, gin.H{"msg": "Message not found."})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).EditMsg(&msg, payload.Message); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	msgRes := messageView(&msg)
+
+	s.Chat.Publish(core.NewEvent(schema.EventMessageEdited, msg.ChatID, ctx.GetString("requestID"), msgRes))
+
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": msgRes})
+}
+
+// ViewEdits godoc
+// @Summary    View the edit history of a message
+// @Description An endpoint to view the previous texts of a message along with the current one, oldest first
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param id query string true "Msg ID"
+// @Security BearerAuth
+// @Success 200 {object} schema.DocViewEdits "Edit history"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
 // @Failure 404 {object} schema.DocNormalResponse "Record not found"
 // @Failure 500 {object} schema.DocNormalResponse "Server error"
-// @Router /api/msg/send-message [post]
-func (s *Service) CreateMessage(ctx *gin.Context) {
+// @Router /api/msg/view-edits [get]
+func (s *Service) ViewEdits(ctx *gin.Context) {
 	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
 	if !model.IsValidUUID(uid) || tp != "login" {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
 		return
 	}
 
-	var payload schema.NewMessage
-	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
+	mid := ctx.Query("id")
+	if !model.IsValidUUID(mid) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid message id."})
 		return
 	}
 
-	var chat model.Chat
-	if err := s.DB.FetchChat(payload.ChatID, &chat); err != nil {
+	var msg model.UserMessage
+	if err := s.DB.WithContext(ctx).FetchMsg(&msg, mid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	msg := model.UserMessage{
-		ChatID:  payload.ChatID,
-		Message: payload.Message,
-		FromID:  uid,
+	if err := s.DB.WithContext(ctx).CheckChatMember(msg.ChatID, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	// The texts of the deleted messages are only kept for moderation
+	if msg.IsRemoved() {
+		ctx.JSON(http.StatusNotFound, gin.H{"msg": "Message not found."})
+		return
 	}
 
-	if err := s.DB.AddMessage(&msg); err != nil {
+	var revisions []model.MessageRevision
+	if err := s.DB.WithContext(ctx).FetchMsgRevisions(msg.ID, &revisions); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	mesRes := schema.ViewMessage{
-		ID:      msg.ID,
-		Message: msg.Message,
-		UserID:  uid,
-		Sent:    msg.CreatedAt,
-		Edited:  msg.UpdatedAt,
+	edits := schema.ViewEdits{ID: msg.ID, Message: msg.Message, Revisions: make([]schema.ViewRevision, len(revisions))}
+	for i, r := range revisions {
+		edits.Revisions[i] = schema.ViewRevision{Message: r.Message, EditorID: r.EditorID, Replaced: r.CreatedAt}
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{"msg": mesRes})
+	ctx.JSON(http.StatusOK, gin.H{"msg": edits})
 }
 
-// ViewMessages godoc
-// @Summary    View All messages in a chat
-// @Description An endpoint to view all messages in a chat (the chat history)
+// DeleteMessage godoc
+// @Summary     Delete a sent message
+// @Description An endpoint for deleting a message, for everyone (the sender, or the owner of a group for any message) which leaves a tombstone in the chat, or only for the current user (any member)
 // @Tags Msg
 // @Accept json
 // @Produce json
-// @Param id query string true "Chat ID"
+// @Param id query string true "Msg ID"
+// @Param for query string false "Delete for me or for everyone (default)" Enums(me, everyone)
 // @Security BearerAuth
-// @Success 200 {object} schema.DocViewChatHistory "Chat history"
+// @Success 204 {object} nil "Message deleted"
 // @Failure 400 {object} schema.DocNormalResponse "Invalid id"
 // @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
 // @Failure 404 {object} schema.DocNormalResponse "Record not found"
 // @Failure 500 {object} schema.DocNormalResponse "Server error"
-// @Router /api/msg/view-hist [get]
-func (s *Service) ViewMessages(ctx *gin.Context) {
+// @Router /api/msg/delete-message [delete]
+func (s *Service) DeleteMessage(ctx *gin.Context) {
 	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
 	if !model.IsValidUUID(uid) || tp != "login" {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
 		return
 	}
 
-	cid := ctx.Query("id")
-	if !model.IsValidUUID(cid) {
-		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid chat id."})
+	mid, scope := ctx.Query("id"), ctx.DefaultQuery("for", "everyone")
+	if !model.IsValidUUID(mid) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid message id."})
+		return
+	}
+	if scope != "me" && scope != "everyone" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid scope, use either me or everyone."})
+		return
+	}
+
+	var msg model.UserMessage
+	if err := s.DB.WithContext(ctx).FetchMsg(&msg, mid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	var chat model.Chat
-	if err := s.DB.GetChatHistory(cid, &chat); err != nil {
+	if err := s.DB.WithContext(ctx).FetchChat(msg.ChatID, &chat); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
-	var hist schema.ViewChat
-	hist.CID = cid
-	hist.Group = chat.Group
-	if hist.Group {
-		hist.Name = chat.Name
-	} else {
-		if chat.Users[0].ID == uid {
-			hist.Name = chat.Users[1].UserName
-		} else {
-			hist.Name = chat.Users[0].UserName
+
+	if err := s.DB.WithContext(ctx).CheckChatMember(chat.ID, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	if scope == "me" {
+		if err := s.DB.WithContext(ctx).HideMsg(msg.ID, uid); err != nil {
+			cm := err.(*core.CustomMessage)
+			ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+			return
 		}
+
+		// Only the other sockets of the user drop the message
+		s.Chat.PublishUser(uid, core.NewEvent(schema.EventMessageDeleted, msg.ChatID, ctx.GetString("requestID"),
+			schema.WSMessageDeleted{ID: msg.ID, By: uid, ForMe: true}))
+
+		ctx.JSON(http.StatusNoContent, nil)
+		return
 	}
 
-	for _, msg := range chat.Messages {
-		hist.Message = append(hist.Message, schema.ViewMessage{
-			ID:      msg.ID,
-			UserID:  uid,
-			Sent:    msg.CreatedAt,
-			Edited:  msg.UpdatedAt,
-			Message: msg.Message,
-		})
+	owner := chat.Group && chat.OwnerID != nil && *chat.OwnerID == uid
+	if (msg.FromID != uid && !owner) || msg.System {
+		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You cannot delete a message that's not owned by you."})
+		return
+	}
+	if msg.IsRemoved() {
+		ctx.JSON(http.StatusNotFound, gin.H{"msg": "Message not found."})
+		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"msg": hist})
+	var attachments []model.Attachment
+	if err := s.DB.WithContext(ctx).FetchAttachments([]string{msg.ID}, &attachments); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).RemoveMsg(&msg, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	// The files go with the message, a failure only leaves an orphan object behind
+	for _, a := range attachments {
+		keys := []string{a.Key}
+		if a.ThumbKey != nil {
+			keys = append(keys, *a.ThumbKey)
+		}
+		for _, key := range keys {
+			if err := s.Storage.Delete(ctx, key); err != nil {
+				slog.ErrorContext(ctx, "Failed to delete the attachment", "component", "storage", "key", key, "err", err)
+			}
+		}
+	}
+
+	s.Chat.Publish(core.NewEvent(schema.EventMessageDeleted, msg.ChatID, ctx.GetString("requestID"),
+		schema.WSMessageDeleted{ID: msg.ID, By: uid}))
+
+	ctx.JSON(http.StatusNoContent, nil)
 }
 
-// FetchUserChats godoc
-// @Summary    Fetch the current user chats
-// @Description An endpoint for fetching all chats of the current user
+// MuteChat godoc
+// @Summary    Mute a chat
+// @Description An endpoint for muting the notifications of a chat until a time, or unmuting it with an empty until
 // @Tags Msg
 // @Accept json
 // @Produce json
+// @Param payload body schema.MuteChat true "Mute payload"
 // @Security BearerAuth
-// @Success 200 {object} schema.DocViewAllChats "User chats"
+// @Success 202 {object} schema.DocNormalResponse "Chat updated"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid time"
 // @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
-// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
 // @Failure 500 {object} schema.DocNormalResponse "Server error"
-// @Router /api/msg/view-chats [get]
-func (s *Service) FetchUserChats(ctx *gin.Context) {
+// @Router /api/msg/mute-chat [patch]
+func (s *Service) MuteChat(ctx *gin.Context) {
 	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
 	if !model.IsValidUUID(uid) || tp != "login" {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
 		return
 	}
 
-	var user model.User
-	if err := s.DB.FetchUserPreloadCM(&user, uid); err != nil {
-		cm := err.(*core.CustomMessage)
-		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+	var payload schema.MuteChat
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
+		return
+	}
+	if payload.Until != nil && !payload.Until.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "The mute has to end in the future."})
 		return
 	}
 
-	var chats []schema.ViewChat
-	for _, chat := range user.Chats {
-		var lastChat *model.UserMessage
-		var chatName string
-		if !chat.Group {
-			if chat.Users[0].ID == uid {
-				chatName = chat.Users[1].UserName
-			} else {
-				chatName = chat.Users[0].UserName
-			}
-		} else {
-			chatName = chat.Name
-		}
-		if len(chat.Messages) > 0 {
-			lastChat = chat.Messages[len(chat.Messages)-1]
-			chats = append(chats, schema.ViewChat{
-				Name: chatName,
-				CID:  chat.ID,
-				Message: []schema.ViewMessage{
-					{
-						ID:      lastChat.ID,
-						Message: lastChat.Message,
-						UserID:  lastChat.FromID,
-						Sent:    lastChat.CreatedAt,
-						Edited:  lastChat.UpdatedAt,
-					},
-				},
-				Group: chat.Group,
-			})
-		} else {
-			chats = append(chats, schema.ViewChat{
-				Name:  chatName,
-				CID:   chat.ID,
-				Group: chat.Group,
-			})
-		}
+	s.updateChatMember(ctx, uid, payload.ChatID, map[string]any{"muted_until": payload.Until})
+}
+
+// ArchiveChat godoc
+// @Summary    Archive a chat
+// @Description An endpoint for moving a chat out of the chat list into the archived chats, or back
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param payload body schema.ArchiveChat true "Archive payload"
+// @Security BearerAuth
+// @Success 202 {object} schema.DocNormalResponse "Chat updated"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/archive-chat [patch]
+func (s *Service) ArchiveChat(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
 
+	var payload schema.ArchiveChat
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
+		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"msg": chats})
+	// Archived chats leave the pinned ones
+	fields := map[string]any{"archived": *payload.Archived}
+	if *payload.Archived {
+		fields["pin_position"] = nil
+	}
+	s.updateChatMember(ctx, uid, payload.ChatID, fields)
 }
 
-// EditMessage godoc
-// @Summary    Editing a sent message
-// @Description An endpoint for editing a sent message
+// PinChat godoc
+// @Summary    Pin a chat
+// @Description An endpoint for pinning a chat at a position on top of the chat list (up to 5 chats), or unpinning it with an empty position
 // @Tags Msg
 // @Accept json
 // @Produce json
-// @Param payload body schema.EditMessage true "Message payload"
+// @Param payload body schema.PinChat true "Pin payload"
 // @Security BearerAuth
-// @Success 202 {object} schema.DocMsgResponse "Message edited"
+// @Success 202 {object} schema.DocNormalResponse "Chat updated"
 // @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
-// @Failure 403 {object} schema.DocNormalResponse "permission denied"
-// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 409 {object} schema.DocNormalResponse "Too many pinned chats"
 // @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
 // @Failure 500 {object} schema.DocNormalResponse "Server error"
-// @Router /api/msg/edit-message [patch]
-func (s *Service) EditMessage(ctx *gin.Context) {
+// @Router /api/msg/pin-chat [patch]
+func (s *Service) PinChat(ctx *gin.Context) {
 	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
 	if !model.IsValidUUID(uid) || tp != "login" {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
 		return
 	}
 
-	var payload schema.EditMessage
+	var payload schema.PinChat
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
 		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
 		return
 	}
 
-	var msg model.UserMessage
-	if err := s.DB.FetchMsg(&msg, payload.ID); err != nil {
-		cm := err.(*core.CustomMessage)
-		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+	if payload.Position != nil {
+		var member model.ChatUser
+		if err := s.DB.WithContext(ctx).FetchChatMember(payload.ChatID, uid, &member); err != nil {
+			cm := err.(*core.CustomMessage)
+			ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+			return
+		}
+
+		// Moving a pinned chat doesn't take another slot
+		var pinned int64
+		if err := s.DB.WithContext(ctx).CountPinnedChats(uid, &pinned); err != nil {
+			cm := err.(*core.CustomMessage)
+			ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+			return
+		}
+		if member.PinPosition == nil && pinned >= model.MaxPinnedChats {
+			ctx.JSON(http.StatusConflict, gin.H{"msg": fmt.Sprintf("You can only pin up to %d chats.", model.MaxPinnedChats)})
+			return
+		}
+	}
+
+	// Pinned chats are taken out of the archive
+	fields := map[string]any{"pin_position": payload.Position}
+	if payload.Position != nil {
+		fields["archived"] = false
+	}
+	s.updateChatMember(ctx, uid, payload.ChatID, fields)
+}
+
+// SetNotifyLevel godoc
+// @Summary    Set the notification level of a chat
+// @Description An endpoint for choosing which messages of a chat send notifications, all of them, only the ones mentioning the user or none
+// @Tags Msg
+// @Accept json
+// @Produce json
+// @Param payload body schema.NotifyLevel true "Level payload"
+// @Security BearerAuth
+// @Success 202 {object} schema.DocNormalResponse "Chat updated"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/msg/notify-level [patch]
+func (s *Service) SetNotifyLevel(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
 		return
 	}
 
-	if msg.FromID != uid {
-		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You cannot delete a message that's not owned by you."})
+	var payload schema.NotifyLevel
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
 		return
 	}
 
-	msg.Message = payload.Message
-	if err := s.DB.SaveMsg(&msg); err != nil {
-		cm := err.(*core.CustomMessage)
-		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+	s.updateChatMember(ctx, uid, payload.ChatID, map[string]any{"notify_level": payload.Level})
+}
+
+// updateChatMember -> Saves the preferences of the user for a chat
+func (s *Service) updateChatMember(ctx *gin.Context, uid, cid string, fields map[string]any) {
+	if !model.IsValidUUID(cid) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid chat id."})
 		return
 	}
 
-	msgRes := schema.ViewMessage{
-		ID:      msg.ID,
-		Message: msg.Message,
-		UserID:  msg.FromID,
-		Sent:    msg.CreatedAt,
-		Edited:  msg.UpdatedAt,
+	if err := s.DB.WithContext(ctx).UpdateChatMember(cid, uid, fields); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
 	}
 
-	ctx.JSON(http.StatusAccepted, gin.H{"msg": msgRes})
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Chat preferences updated successfully."})
 }
 
-// DeleteMessage godoc
-// @Summary     Delete a sent message
-// @Description An endpoint for deleting a sent message of the current user
+// MarkRead godoc
+// @Summary    Mark a chat as read
+// @Description An endpoint for moving the read cursor of the current user up to a message, the latest message of the chat when no message id is given
 // @Tags Msg
 // @Accept json
 // @Produce json
-// @Param id query string true "Msg ID"
+// @Param payload body schema.MarkRead true "Read payload"
 // @Security BearerAuth
-// @Success 204 {object} nil "Message deleted"
+// @Success 202 {object} schema.DocNormalResponse "Chat marked as read"
 // @Failure 400 {object} schema.DocNormalResponse "Invalid id"
 // @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
 // @Failure 403 {object} schema.DocNormalResponse "Permission denied"
 // @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
 // @Failure 500 {object} schema.DocNormalResponse "Server error"
-// @Router /api/msg/delete-message [delete]
-func (s *Service) DeleteMessage(ctx *gin.Context) {
+// @Router /api/msg/mark-read [patch]
+func (s *Service) MarkRead(ctx *gin.Context) {
 	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
 	if !model.IsValidUUID(uid) || tp != "login" {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
 		return
 	}
 
-	mid := ctx.Query("id")
-	if !model.IsValidUUID(mid) {
-		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid message id."})
+	var payload schema.MarkRead
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Invalid payload."})
+		return
+	}
+
+	if !model.IsValidUUID(payload.ChatID) || (payload.MsgID != "" && !model.IsValidUUID(payload.MsgID)) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid id."})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).CheckChatMember(payload.ChatID, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	var msg model.UserMessage
-	if err := s.DB.FetchMsg(&msg, mid); err != nil {
+	var err error
+	if payload.MsgID == "" {
+		err = s.DB.WithContext(ctx).FetchLastMsg(payload.ChatID, &msg)
+	} else {
+		err = s.DB.WithContext(ctx).FetchMsg(&msg, payload.MsgID)
+	}
+	if err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if msg.FromID != uid {
-		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You cannot delete a message that's not owned by you."})
+	if msg.ChatID != payload.ChatID {
+		ctx.JSON(http.StatusNotFound, gin.H{"msg": "Msg not found."})
+		return
 	}
 
-	if err := s.DB.DeleteMsg(&msg); err != nil {
+	if err := s.DB.WithContext(ctx).MarkChatRead(payload.ChatID, uid, msg.ID, msg.CreatedAt); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	ctx.JSON(http.StatusNoContent, nil)
+	s.Chat.Publish(core.NewEvent(schema.EventMessageRead, payload.ChatID, ctx.GetString("requestID"), schema.WSReadReceipt{
+		UserID: uid,
+		MsgID:  msg.ID,
+		ReadAt: time.Now(),
+	}))
+
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Chat marked as read."})
 }
 
 // OpenChat godoc
 // @Summary     Open a chat between users
-// @Description An endpoint for opening a chat between users with IDs.
+// @Description An endpoint for opening a chat between users with IDs, with the latest page of its history (older messages through view-hist)
 // @Tags Msg
 // @Accept json
 // @Produce json
@@ -328,43 +1185,48 @@ func (s *Service) OpenChat(ctx *gin.Context) {
 	}
 
 	var chat model.Chat
-	if err := s.DB.FindChat(uid, fid, &chat); err != nil {
+	if err := s.DB.WithContext(ctx).FindChat(uid, fid, &chat); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	var msgs []schema.ViewMessage
-	for _, msg := range chat.Messages {
-		msgs = append(msgs, schema.ViewMessage{
-			ID:      msg.ID,
-			Message: msg.Message,
-			UserID:  msg.FromID,
-			Sent:    msg.CreatedAt,
-			Edited:  msg.UpdatedAt,
-		})
+	// The latest page of the history, the older messages are fetched through view-hist with a before cursor
+	var msgs []model.UserMessage
+	if err := s.DB.WithContext(ctx).FetchChatMessages(chat.ID, uid, "", "", historyPage+1, &msgs); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
 	}
 
-	var chatName string
+	result := schema.ViewChat{
+		CID:     chat.ID,
+		Group:   chat.Group,
+		HasMore: len(msgs) > historyPage,
+	}
+	if result.HasMore {
+		msgs = msgs[1:]
+	}
 	if chat.Users[0].ID == uid {
-		chatName = chat.Users[1].UserName
+		result.Name = chat.Users[1].UserName
 	} else {
-		chatName = chat.Users[0].UserName
+		result.Name = chat.Users[0].UserName
 	}
 
-	result := schema.ViewChat{
-		Name:    chatName,
-		CID:     chat.ID,
-		Message: msgs,
-		Group:   chat.Group,
+	views, err := s.messageViews(ctx, uid, msgs)
+	if err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
 	}
+	result.Message = views
 
 	ctx.JSON(http.StatusOK, gin.H{"msg": result})
 }
 
 // RenameChat godoc
 // @Summary   Renaming a group chat
-// @Description An endpoint for renaming a group chat
+// @Description An endpoint for the admins of a group chat to rename it
 // @Tags Msg
 // @Accept json
 // @Produce json
@@ -391,30 +1253,29 @@ func (s *Service) RenameChat(ctx *gin.Context) {
 	}
 
 	var chat model.Chat
-	if err := s.DB.FetchChat(payload.ChatID, &chat); err != nil {
+	var member model.ChatUser
+	if err := s.groupAdmin(ctx, payload.ChatID, uid, &chat, &member); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if *chat.OwnerID != uid {
-		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You don't have permission to rename this group chat."})
-		return
-	}
-
 	chat.Name = payload.Name
-	if err := s.DB.SaveChat(&chat); err != nil {
+	if err := s.DB.WithContext(ctx).SaveChat(&chat); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
+	s.Chat.Publish(core.NewEvent(schema.EventChatRenamed, chat.ID, ctx.GetString("requestID"), schema.WSChatRenamed{Name: chat.Name, By: uid}))
+	s.systemMessage(ctx, chat.ID, uid, fmt.Sprintf("%s renamed the group to %q", member.User.UserName, chat.Name))
+
 	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Chat name updated successfully."})
 }
 
 // AddUserToChat godoc
 // @Summary Add a user to a group chat
-// @Description An endpoint for adding users to a group chat
+// @Description An endpoint for the admins of a group chat to add users to it
 // @Tags Msg
 // @Accept json
 // @Produce json
@@ -424,6 +1285,7 @@ func (s *Service) RenameChat(ctx *gin.Context) {
 // @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
 // @Failure 403 {object} schema.DocNormalResponse "Permission denied"
 // @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 409 {object} schema.DocNormalResponse "Already a member"
 // @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
 // @Failure 500 {object} schema.DocNormalResponse "Server error"
 // @Router /api/msg/add-user [put]
@@ -441,41 +1303,50 @@ func (s *Service) AddUserToChat(ctx *gin.Context) {
 	}
 
 	var chat model.Chat
-	if err := s.DB.FetchChat(payload.ChatID, &chat); err != nil {
+	var member model.ChatUser
+	if err := s.groupAdmin(ctx, payload.ChatID, uid, &chat, &member); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if *chat.OwnerID != uid {
-		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You aren't permitted to add users to this chat."})
-		return
-	}
-
 	if payload.UserID == uid {
 		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You're already in the group."})
 		return
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, payload.UserID); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, payload.UserID); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if err := s.DB.AddUserChat(&chat, &user); err != nil {
+	if err := s.DB.WithContext(ctx).CheckChatMember(chat.ID, user.ID); err == nil {
+		ctx.JSON(http.StatusConflict, gin.H{"msg": "User is already in the group."})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).AddUserChat(&chat, &user); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
+	s.Chat.SubscribeUser(chat.ID, user.ID)
+	s.Chat.Publish(core.NewEvent(schema.EventMemberJoined, chat.ID, ctx.GetString("requestID"), schema.WSMember{
+		UserID:   user.ID,
+		UserName: user.UserName,
+		By:       uid,
+	}))
+	s.systemMessage(ctx, chat.ID, uid, fmt.Sprintf("%s added %s", member.User.UserName, user.UserName))
+
 	ctx.JSON(http.StatusAccepted, gin.H{"msg": "User added to Chat."})
 }
 
 // RemoveUserChat godoc
 // @Summary Remove a user from a group
-// @Description An endpoint for removing a user from a group chat
+// @Description An endpoint for the admins of a group chat to remove a member, only the owner removes other admins
 // @Tags Msg
 // @Accept json
 // @Produce json
@@ -502,36 +1373,50 @@ func (s *Service) RemoveUserChat(ctx *gin.Context) {
 	}
 
 	var chat model.Chat
-
-	if err := s.DB.FetchChat(payload.ChatID, &chat); err != nil {
+	var member model.ChatUser
+	if err := s.groupAdmin(ctx, payload.ChatID, uid, &chat, &member); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if *chat.OwnerID != uid {
-		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You aren't permitted to remove users from this chat."})
-		return
-	}
-
 	if payload.UserID == uid {
 		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You can't remove yourself from the group."})
 		return
 	}
 
-	var user model.User
-	if err := s.DB.FetchUser(&user, payload.UserID); err != nil {
+	var target model.ChatUser
+	if err := s.DB.WithContext(ctx).FetchChatMember(chat.ID, payload.UserID, &target); err != nil {
 		cm := err.(*core.CustomMessage)
+		if cm.Code == http.StatusForbidden {
+			ctx.JSON(http.StatusNotFound, gin.H{"msg": "User isn't a member of this chat."})
+			return
+		}
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if err := s.DB.RemoveUserChat(&chat, &user); err != nil {
+	// Admins only remove members, the owner removes anyone
+	if member.Role != model.RoleOwner && model.IsAdminRole(target.Role) {
+		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You aren't permitted to remove an admin of the group."})
+		return
+	}
+
+	user := *target.User
+	if err := s.DB.WithContext(ctx).RemoveUserChat(&chat, &user); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
+	s.Chat.KickUser(chat.ID, user.ID, "You were removed from this chat.")
+	s.Chat.Publish(core.NewEvent(schema.EventMemberLeft, chat.ID, ctx.GetString("requestID"), schema.WSMember{
+		UserID:   user.ID,
+		UserName: user.UserName,
+		By:       uid,
+	}))
+	s.systemMessage(ctx, chat.ID, uid, fmt.Sprintf("%s removed %s", member.User.UserName, user.UserName))
+
 	ctx.JSON(http.StatusNoContent, nil)
 }
 
@@ -564,30 +1449,47 @@ func (s *Service) TransferOwner(ctx *gin.Context) {
 	}
 
 	var chat model.Chat
-	if err := s.DB.FetchChat(payload.ChatID, &chat); err != nil {
+	var member model.ChatUser
+	if err := s.groupMember(ctx, payload.ChatID, uid, &chat, &member); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if !chat.Group {
-		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You can only transfer ownership in group chats."})
+	if member.Role != model.RoleOwner {
+		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You don't have permission to transfer ownership."})
+		return
+	}
+	if payload.UserID == uid {
+		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You already own the group."})
 		return
 	}
 
-	if chat.OwnerID == nil || *chat.OwnerID != uid {
-		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You don't have permission to transfer ownership."})
+	var target model.ChatUser
+	if err := s.DB.WithContext(ctx).FetchChatMember(chat.ID, payload.UserID, &target); err != nil {
+		cm := err.(*core.CustomMessage)
+		if cm.Code == http.StatusForbidden {
+			ctx.JSON(http.StatusNotFound, gin.H{"msg": "User isn't a member of this chat."})
+			return
+		}
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	ownerID := payload.UserID
-	chat.OwnerID = &ownerID
-	if err := s.DB.SaveChat(&chat); err != nil {
+	if err := s.DB.WithContext(ctx).TransferChatOwner(&chat, target.UserID); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
+	for _, change := range []schema.WSMemberRole{
+		{UserID: target.UserID, Role: model.RoleOwner, By: uid},
+		{UserID: uid, Role: model.RoleAdmin, By: uid},
+	} {
+		s.Chat.Publish(core.NewEvent(schema.EventMemberRole, chat.ID, ctx.GetString("requestID"), change))
+	}
+	s.systemMessage(ctx, chat.ID, uid, fmt.Sprintf("%s made %s the owner", member.User.UserName, target.User.UserName))
+
 	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Ownership transferred successfully."})
 }
 
@@ -620,30 +1522,43 @@ func (s *Service) LeaveChat(ctx *gin.Context) {
 	}
 
 	var chat model.Chat
-	if err := s.DB.FetchChat(cid, &chat); err != nil {
+	if err := s.DB.WithContext(ctx).FetchChat(cid, &chat); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if *chat.OwnerID == user.ID {
+	if !chat.Group {
+		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You can only leave group chats."})
+		return
+	}
+
+	if chat.OwnerID != nil && *chat.OwnerID == user.ID {
 		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You can't leave this chat you can to delete it if you must or transfer ownership."})
 		return
 	}
 
-	if err := s.DB.LeaveChat(&chat, &user); err != nil {
+	if err := s.DB.WithContext(ctx).LeaveChat(&chat, &user); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
+	s.Chat.KickUser(chat.ID, user.ID, "You left this chat.")
+	s.Chat.Publish(core.NewEvent(schema.EventMemberLeft, chat.ID, ctx.GetString("requestID"), schema.WSMember{
+		UserID:   user.ID,
+		UserName: user.UserName,
+		By:       uid,
+	}))
+	s.systemMessage(ctx, chat.ID, uid, user.UserName+" left")
+
 	ctx.JSON(http.StatusNoContent, nil)
 }
 
@@ -676,22 +1591,24 @@ func (s *Service) DeleteChat(ctx *gin.Context) {
 	}
 
 	var chat model.Chat
-	if err := s.DB.FetchChat(cid, &chat); err != nil {
+	if err := s.DB.WithContext(ctx).FetchChat(cid, &chat); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if *chat.OwnerID != uid {
+	if chat.OwnerID == nil || *chat.OwnerID != uid {
 		ctx.JSON(http.StatusForbidden, gin.H{"msg": "You don't have the permission to delete this chat."})
 		return
 	}
 
-	if err := s.DB.DeleteChat(&chat); err != nil {
+	if err := s.DB.WithContext(ctx).DeleteChat(&chat); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
+	s.Chat.CloseRoom(chat.ID, "This chat was deleted.")
+
 	ctx.JSON(http.StatusNoContent, nil)
 }
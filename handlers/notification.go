@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"findme/core"
+	"findme/model"
+	"findme/schema"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ViewNotifications godoc
+// @Summary    View the notification center
+// @Description An endpoint to view a page of the notifications of the current user (friend requests, application decisions, subscription events and trial reminders), newest first, along with the unread count
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param before query string false "Notification ID to read the notifications before"
+// @Param limit query int false "Page size, 20 by default and 50 at most"
+// @Security BearerAuth
+// @Success 200 {object} schema.ViewNotifications "Notifications"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid cursor"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/user/view-notifications [get]
+func (s *Service) ViewNotifications(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	before := ctx.Query("before")
+	if before != "" && !model.IsValidUUID(before) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid cursor, use before with a notification id."})
+		return
+	}
+
+	limit, ok := pageLimit(ctx, 20, 50)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid limit."})
+		return
+	}
+
+	// One more notification than the page tells whether there are older ones
+	var notifications []model.Notification
+	if err := s.DB.WithContext(ctx).FetchNotifications(uid, before, limit+1, &notifications); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	unread, err := s.Notify.Unread(ctx, uid)
+	if err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	res := schema.ViewNotifications{Unread: unread, HasMore: len(notifications) > limit}
+	if res.HasMore {
+		notifications = notifications[:limit]
+	}
+	res.Notifications = make([]schema.ViewNotification, len(notifications))
+	for i := range notifications {
+		res.Notifications[i] = core.ViewNotification(&notifications[i])
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// UnreadNotifications godoc
+// @Summary    Count the unread notifications
+// @Description An endpoint for the badge of the notification center, the number of notifications the current user hasn't read
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} schema.DocUnreadNotifications "Unread count"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/user/unread-notifications [get]
+func (s *Service) UnreadNotifications(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	unread, err := s.Notify.Unread(ctx, uid)
+	if err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"unread": unread})
+}
+
+// ReadNotification godoc
+// @Summary    Mark a notification as read
+// @Description An endpoint for marking a notification of the current user as read
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param id query string true "Notification ID"
+// @Security BearerAuth
+// @Success 202 {object} schema.DocNormalResponse "Notification read"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/user/read-notification [patch]
+func (s *Service) ReadNotification(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	id := ctx.Query("id")
+	if !model.IsValidUUID(id) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid notification id."})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).MarkNotificationRead(id, uid, time.Now()); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	s.Notify.PublishRead(ctx, uid, id, false)
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Notification marked as read."})
+}
+
+// ReadAllNotifications godoc
+// @Summary    Mark every notification as read
+// @Description An endpoint for marking all the notifications of the current user as read
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} schema.DocNormalResponse "Notifications read"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/user/read-all-notifications [patch]
+func (s *Service) ReadAllNotifications(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).MarkAllNotificationsRead(uid, time.Now()); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	s.Notify.PublishRead(ctx, uid, "", false)
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Notifications marked as read."})
+}
+
+// DeleteNotification godoc
+// @Summary    Delete a notification
+// @Description An endpoint for removing a notification from the notification center of the current user
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param id query string true "Notification ID"
+// @Security BearerAuth
+// @Success 204 {object} nil "Notification deleted"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/user/delete-notification [delete]
+func (s *Service) DeleteNotification(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	id := ctx.Query("id")
+	if !model.IsValidUUID(id) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid notification id."})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).DeleteNotification(id, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	s.Notify.PublishRead(ctx, uid, id, true)
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// ViewNotificationPreferences godoc
+// @Summary    View the notification preferences
+// @Description An endpoint to view the channels (email, in_app, digest) the current user gets each notification category on. Password resets and payment failures aren't listed as they always go out
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} schema.DocNotificationPreferences "Preferences"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/user/view-notification-preferences [get]
+func (s *Service) ViewNotificationPreferences(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var saved []model.NotificationPreference
+	if err := s.DB.WithContext(ctx).FetchNotificationPreferences(uid, &saved); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	prefs := make(map[string]model.NotificationPreference, len(saved))
+	for _, pref := range saved {
+		prefs[pref.Category] = pref
+	}
+
+	views := make([]schema.ViewNotificationPreference, len(model.NotifyCategories))
+	for i, category := range model.NotifyCategories {
+		pref, ok := prefs[category]
+		if !ok {
+			pref = model.DefaultPreference(uid, category)
+		}
+		views[i] = notificationPreferenceView(&pref)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"preferences": views})
+}
+
+// UpdateNotificationPreference godoc
+// @Summary    Update a notification preference
+// @Description An endpoint for turning the channels of a notification category on or off for the current user, the channels left out keep their value. Categories: friend_requests, applications, subscription and trial (email, in_app), mentions (email, digest) and messages (digest)
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param payload body schema.UpdateNotificationPreference true "Preference"
+// @Security BearerAuth
+// @Success 202 {object} schema.ViewNotificationPreference "Preference updated"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/user/update-notification-preference [patch]
+func (s *Service) UpdateNotificationPreference(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var payload schema.UpdateNotificationPreference
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
+		return
+	}
+
+	if len(model.CategoryChannels(payload.Category)) == 0 {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Unknown notification category."})
+		return
+	}
+	if (payload.Email != nil && !model.HasChannel(payload.Category, model.ChannelEmail)) ||
+		(payload.InApp != nil && !model.HasChannel(payload.Category, model.ChannelInApp)) ||
+		(payload.Digest != nil && !model.HasChannel(payload.Category, model.ChannelDigest)) {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "The category isn't delivered on that channel."})
+		return
+	}
+
+	var pref model.NotificationPreference
+	if err := s.DB.WithContext(ctx).FetchNotificationPreference(uid, payload.Category, &pref); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	if payload.Email != nil {
+		pref.Email = *payload.Email
+	}
+	if payload.InApp != nil {
+		pref.InApp = *payload.InApp
+	}
+	if payload.Digest != nil {
+		pref.Digest = *payload.Digest
+	}
+
+	if err := s.DB.WithContext(ctx).SaveNotificationPreference(&pref); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, notificationPreferenceView(&pref))
+}
+
+// notificationPreferenceView -> Maps a preference to its view, with only the channels of its category
+func notificationPreferenceView(pref *model.NotificationPreference) schema.ViewNotificationPreference {
+	view := schema.ViewNotificationPreference{Category: pref.Category}
+	for _, channel := range model.CategoryChannels(pref.Category) {
+		on := pref.Allows(channel)
+		switch channel {
+		case model.ChannelEmail:
+			view.Email = &on
+		case model.ChannelInApp:
+			view.InApp = &on
+		case model.ChannelDigest:
+			view.Digest = &on
+		}
+	}
+	return view
+}
@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -33,7 +33,7 @@ func (s *Service) GetProjects(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUserProjects(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUserProjects(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -88,7 +88,7 @@ func (s *Service) ViewProject(ctx *gin.Context) {
 	}
 
 	var project model.Project
-	if err := s.DB.FetchProjectPreloadTU(&project, pid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchProjectPreloadTU(&project, pid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -146,7 +146,7 @@ func (s *Service) ViewSingleProjectApplication(ctx *gin.Context) {
 	}
 
 	var project model.Project
-	if err := s.DB.FetchProjectPreloadA(&project, id); err != nil {
+	if err := s.DB.WithContext(ctx).FetchProjectPreloadA(&project, id); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -204,7 +204,7 @@ func (s *Service) SearchProject(ctx *gin.Context) {
 	}
 
 	var projects []model.Project
-	if err := s.DB.SearchProjectsBySKills(&projects, payload.Tags, uid); err != nil {
+	if err := s.DB.WithContext(ctx).SearchProjectsBySKills(&projects, payload.Tags, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -260,9 +260,9 @@ func (s *Service) CreateProject(ctx *gin.Context) {
 	for i := range payload.Tags {
 		payload.Tags[i] = strings.ToLower(payload.Tags[i])
 	}
-	allskills, err := s.CheckAndUpdateSkills(payload.Tags)
+	allskills, err := s.CheckAndUpdateSkills(ctx, payload.Tags)
 	if err != nil {
-		log.Printf("An error occured while trying to add a new skill to db -> %v", err)
+		slog.ErrorContext(ctx, "Failed to add new skills to db", "err", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to create new project."})
 		return
 	}
@@ -281,7 +281,7 @@ func (s *Service) CreateProject(ctx *gin.Context) {
 		project.GitLink = payload.GitLink
 	}
 
-	if err := s.DB.AddProject(&project); err != nil {
+	if err := s.DB.WithContext(ctx).AddProject(&project); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -298,7 +298,7 @@ func (s *Service) CreateProject(ctx *gin.Context) {
 		Views:       project.Views,
 	}
 
-	s.Emb.QueueProjectCreate(project.ID, project.Title, project.Description, uid, payload.Tags)
+	s.Emb.QueueProjectCreate(ctx, project.ID, project.Title, project.Description, uid, payload.Tags)
 
 	ctx.JSON(http.StatusCreated, gin.H{"project": result})
 }
@@ -338,15 +338,15 @@ func (s *Service) RecommendUsers(ctx *gin.Context) {
 	}
 
 	var project model.Project
-	if err := s.DB.FetchProject(&project, pid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchProject(&project, pid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	res, err := s.Rec.GetRecommendation(project.ID, core.UserRecommendation)
+	res, err := s.Rec.GetRecommendation(ctx, project.ID, core.UserRecommendation)
 	if err != nil || res == nil {
-		log.Printf("[gRPC Recommendation] Failed to get recommendation for project -> %v, err -> %v", project.ID, err)
+		slog.ErrorContext(ctx, "Failed to get recommendation for project", "component", "recommendation", "project_id", project.ID, "err", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to retrieve users for the project."})
 		return
 	}
@@ -358,7 +358,7 @@ func (s *Service) RecommendUsers(ctx *gin.Context) {
 		ids = append(ids, id)
 	}
 
-	if err := s.DB.FindUsers(&users, ids); err != nil {
+	if err := s.DB.WithContext(ctx).FindUsers(&users, ids); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -429,7 +429,7 @@ func (s *Service) EditProject(ctx *gin.Context) {
 	}
 
 	var project model.Project
-	if err := s.DB.FetchProjectPreloadT(&project, pid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchProjectPreloadT(&project, pid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -444,9 +444,9 @@ func (s *Service) EditProject(ctx *gin.Context) {
 		payload.Tags[i] = strings.ToLower(payload.Tags[i])
 	}
 
-	allskills, err := s.CheckAndUpdateSkills(payload.Tags)
+	allskills, err := s.CheckAndUpdateSkills(ctx, payload.Tags)
 	if err != nil {
-		log.Printf("An error occured while trying to add a new skill to db %v", err)
+		slog.ErrorContext(ctx, "Failed to add new skills to db", "err", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to update project."})
 		return
 	}
@@ -459,7 +459,7 @@ func (s *Service) EditProject(ctx *gin.Context) {
 		project.GitLink = payload.GitLink
 	}
 
-	if err := s.DB.EditProject(&project, allskills); err != nil {
+	if err := s.DB.WithContext(ctx).EditProject(&project, allskills); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -476,7 +476,15 @@ func (s *Service) EditProject(ctx *gin.Context) {
 		Views:       project.Views,
 	}
 
-	s.Emb.QueueProjectUpdate(project.ID, project.Title, project.Description, payload.Tags)
+	s.Emb.QueueProjectUpdate(ctx, project.ID, project.Title, project.Description, payload.Tags)
+	s.Hooks.Dispatch(ctx, model.WebhookProjectUpdated, []string{uid}, project.ID, schema.WebhookProject{
+		ProjectID:   project.ID,
+		Title:       project.Title,
+		Description: project.Description,
+		Available:   project.Availability,
+		Tags:        payload.Tags,
+		UpdatedAt:   project.UpdatedAt,
+	})
 
 	ctx.JSON(http.StatusAccepted, gin.H{"project": result})
 }
@@ -509,7 +517,7 @@ func (s *Service) EditProjectView(ctx *gin.Context) {
 	}
 
 	var project model.Project
-	if err := s.DB.FetchProjectPreloadT(&project, id); err != nil {
+	if err := s.DB.WithContext(ctx).FetchProjectPreloadT(&project, id); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -517,7 +525,7 @@ func (s *Service) EditProjectView(ctx *gin.Context) {
 
 	if project.UserID != uid {
 		project.Views++
-		if err := s.DB.SaveProject(&project); err != nil {
+		if err := s.DB.WithContext(ctx).SaveProject(&project); err != nil {
 			cm := err.(*core.CustomMessage)
 			ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 			return
@@ -578,7 +586,7 @@ func (s *Service) EditProjectAvailability(ctx *gin.Context) {
 	}
 
 	var project model.Project
-	if err := s.DB.FetchProject(&project, pid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchProject(&project, pid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -590,7 +598,7 @@ func (s *Service) EditProjectAvailability(ctx *gin.Context) {
 	}
 
 	project.Availability = stat
-	if err := s.DB.SaveProject(&project); err != nil {
+	if err := s.DB.WithContext(ctx).SaveProject(&project); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -611,7 +619,15 @@ func (s *Service) EditProjectAvailability(ctx *gin.Context) {
 		Available:   project.Availability,
 	}
 
-	s.Emb.QueueProjectUpdateStatus(project.ID, project.Availability)
+	s.Emb.QueueProjectUpdateStatus(ctx, project.ID, project.Availability)
+	s.Hooks.Dispatch(ctx, model.WebhookProjectUpdated, []string{uid}, project.ID, schema.WebhookProject{
+		ProjectID:   project.ID,
+		Title:       project.Title,
+		Description: project.Description,
+		Available:   project.Availability,
+		Tags:        tags,
+		UpdatedAt:   project.UpdatedAt,
+	})
 
 	ctx.JSON(http.StatusAccepted, gin.H{"project": result})
 }
@@ -645,14 +661,14 @@ func (s *Service) SaveProject(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	var project model.Project
-	if err := s.DB.FetchProject(&project, pid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchProject(&project, pid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -663,7 +679,7 @@ func (s *Service) SaveProject(ctx *gin.Context) {
 		return
 	}
 
-	if err := s.DB.BookmarkProject(&user, &project); err != nil {
+	if err := s.DB.WithContext(ctx).BookmarkProject(&user, &project); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -707,7 +723,7 @@ func (s *Service) ViewSavedProject(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUserPreloadB(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUserPreloadB(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -759,20 +775,20 @@ func (s *Service) RemoveSavedProject(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	var project model.Project
-	if err := s.DB.FetchProject(&project, pid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchProject(&project, pid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if err := s.DB.RemoveBookmarkedProject(&user, &project); err != nil {
+	if err := s.DB.WithContext(ctx).RemoveBookmarkedProject(&user, &project); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -819,14 +835,14 @@ func (s *Service) ApplyForProject(ctx *gin.Context) {
 	}
 
 	var project model.Project
-	if err := s.DB.FetchProjectPreloadU(&project, pid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchProjectPreloadU(&project, pid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -837,7 +853,7 @@ func (s *Service) ApplyForProject(ctx *gin.Context) {
 		return
 	}
 
-	if err, exists := s.DB.CheckExistingAppReq(pid, uid); err != nil || exists {
+	if err, exists := s.DB.WithContext(ctx).CheckExistingAppReq(pid, uid); err != nil || exists {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -858,7 +874,7 @@ func (s *Service) ApplyForProject(ctx *gin.Context) {
 		req.Message = payload.Message
 	}
 
-	if err := s.DB.AddProjectApplicationReq(&req); err != nil {
+	if err := s.DB.WithContext(ctx).AddProjectApplicationReq(&req); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -871,7 +887,17 @@ func (s *Service) ApplyForProject(ctx *gin.Context) {
 		Username: project.User.UserName,
 	}
 
-	s.Email.QueueProjectApplication(user.UserName, project.User.UserName, project.Description, "nil", project.User.Email)
+	s.Email.QueueProjectApplication(ctx, user.UserName, project.User.UserName, project.Description, "nil", project.User.Email)
+	s.Hooks.Dispatch(ctx, model.WebhookApplicationReceived, []string{project.User.ID}, project.ID, schema.WebhookApplication{
+		ApplicationID: req.ID,
+		ProjectID:     project.ID,
+		ProjectTitle:  project.Title,
+		Applicant:     user.UserName,
+		Owner:         project.User.UserName,
+		Message:       req.Message,
+		Status:        req.Status,
+	})
+	s.Notify.Notify(ctx, project.User.ID, model.NotifyApplicationReceived, "New project application", user.UserName+" applied to join your project "+project.Title+".", &req.ID)
 
 	ctx.JSON(http.StatusOK, gin.H{"project_req": application})
 }
@@ -896,7 +922,7 @@ func (s *Service) ViewProjectApplications(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.ViewProjectApplications(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).ViewProjectApplications(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -967,7 +993,7 @@ func (s *Service) UpdateProjectApplication(ctx *gin.Context) {
 	}
 
 	var req model.ProjectReq
-	if err := s.DB.FetchProjectApplication(&req, rid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchProjectApplication(&req, rid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -980,24 +1006,26 @@ func (s *Service) UpdateProjectApplication(ctx *gin.Context) {
 
 	switch status {
 	case model.StatusRejected:
-		if err := s.DB.UpdateProjectAppliationReject(&req); err != nil {
+		if err := s.DB.WithContext(ctx).UpdateProjectAppliationReject(&req); err != nil {
 			cm := err.(*core.CustomMessage)
 			ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 			return
 		}
 
-		s.Email.QueueProjectApplicationReject(req.ToUser.UserName, req.FromUser.UserName, req.Project.Description, payload.Reason, req.FromUser.Email)
+		s.Email.QueueProjectApplicationReject(ctx, req.ToUser.UserName, req.FromUser.UserName, req.Project.Description, payload.Reason, req.FromUser.Email)
+		s.Notify.Notify(ctx, req.FromUser.ID, model.NotifyApplicationRejected, "Application rejected", req.ToUser.UserName+" rejected your application to "+req.Project.Title+": "+payload.Reason, &req.ID)
 
 	case model.StatusAccepted:
 		var err error
 
+		chat := req.Project.Chat
 		if req.Project.ChatID == nil {
-			var chat model.Chat
+			chat = &model.Chat{}
 			chat.Group = true
 			chat.OwnerID = &uid
-			err = s.DB.UpdateProjectApplicationAcceptF(&req, req.ToUser, req.FromUser, req.Project, &chat)
+			err = s.DB.WithContext(ctx).UpdateProjectApplicationAcceptF(&req, req.ToUser, req.FromUser, req.Project, chat)
 		} else {
-			err = s.DB.UpdateProjectApplicationAccept(&req, req.FromUser, req.Project.Chat)
+			err = s.DB.WithContext(ctx).UpdateProjectApplicationAccept(&req, req.FromUser, req.Project.Chat)
 		}
 
 		if err != nil {
@@ -1006,7 +1034,29 @@ func (s *Service) UpdateProjectApplication(ctx *gin.Context) {
 			return
 		}
 
-		s.Email.QueueProjectApplicationAccept(req.ToUser.UserName, req.FromUser.UserName, req.Project.Description, "", req.FromUser.Email)
+		s.Chat.SubscribeUser(chat.ID, req.ToUser.ID)
+		s.Chat.SubscribeUser(chat.ID, req.FromUser.ID)
+		// A new chat only holds the two of them, an existing one has members to tell about the applicant
+		if req.Project.ChatID != nil {
+			s.Chat.Publish(core.NewEvent(schema.EventMemberJoined, chat.ID, ctx.GetString("requestID"), schema.WSMember{
+				UserID:   req.FromUser.ID,
+				UserName: req.FromUser.UserName,
+				By:       uid,
+			}))
+			s.systemMessage(ctx, chat.ID, uid, req.ToUser.UserName+" accepted "+req.FromUser.UserName+" to the project")
+		}
+
+		s.Email.QueueProjectApplicationAccept(ctx, req.ToUser.UserName, req.FromUser.UserName, req.Project.Description, "", req.FromUser.Email)
+		s.Hooks.Dispatch(ctx, model.WebhookApplicationAccepted, []string{req.ToUser.ID, req.FromUser.ID}, req.ProjectID, schema.WebhookApplication{
+			ApplicationID: req.ID,
+			ProjectID:     req.ProjectID,
+			ProjectTitle:  req.Project.Title,
+			Applicant:     req.FromUser.UserName,
+			Owner:         req.ToUser.UserName,
+			Message:       req.Message,
+			Status:        model.StatusAccepted,
+		})
+		s.Notify.Notify(ctx, req.FromUser.ID, model.NotifyApplicationAccepted, "Application accepted", req.ToUser.UserName+" accepted your application to "+req.Project.Title+", you can now chat with the team.", &req.ID)
 
 	default:
 		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid status."})
@@ -1045,14 +1095,14 @@ func (s *Service) DeleteProjectApplication(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	var req model.ProjectReq
-	if err := s.DB.FetchProjectAppPreloadFU(&req, rid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchProjectAppPreloadFU(&req, rid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1063,7 +1113,7 @@ func (s *Service) DeleteProjectApplication(ctx *gin.Context) {
 		return
 	}
 
-	if err := s.DB.DeleteProjectApplicationReq(&req); err != nil {
+	if err := s.DB.WithContext(ctx).DeleteProjectApplicationReq(&req); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1101,7 +1151,7 @@ func (s *Service) ClearProjectApplication(ctx *gin.Context) {
 	}
 
 	var project model.Project
-	if err := s.DB.FetchProjectPreloadA(&project, pid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchProjectPreloadA(&project, pid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1117,7 +1167,7 @@ func (s *Service) ClearProjectApplication(ctx *gin.Context) {
 		return
 	}
 
-	if err := s.DB.ClearProjectApplication(project.Applications); err != nil {
+	if err := s.DB.WithContext(ctx).ClearProjectApplication(project.Applications); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1153,7 +1203,7 @@ func (s *Service) DeleteProject(ctx *gin.Context) {
 	}
 
 	var project model.Project
-	if err := s.DB.FetchProject(&project, pid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchProject(&project, pid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1164,13 +1214,13 @@ func (s *Service) DeleteProject(ctx *gin.Context) {
 		return
 	}
 
-	if err := s.DB.DeleteProject(&project); err != nil {
+	if err := s.DB.WithContext(ctx).DeleteProject(&project); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	s.Emb.QueueProjectDelete(project.ID)
+	s.Emb.QueueProjectDelete(ctx, project.ID)
 
 	ctx.JSON(http.StatusNoContent, nil)
 }
@@ -1,26 +1,27 @@
 package handlers
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"findme/core"
 	"findme/model"
-	"findme/schema"
 
 	"github.com/gin-gonic/gin"
 )
 
 // WSChat godoc
-// @Summary  A websocket message hub for real-time chatting
-// @Description An endpoint that upgrades client to a websocket connection for real-time chatting experience
+// @Summary  A websocket hub for real-time chatting and personal events
+// @Description An endpoint that upgrades client to a single websocket connection per user, subscribed to all of the user's chats. Chats can be unsubscribed from and subscribed to again over the socket, every frame is a schema.WSEnvelope (see the event types in schema/ws.go)
 // @Tags Msg
 // @Accept json
 // @Produce json
-// @Param id query string true "Chat ID"
 // @Security BearerAuth
 // @Success 101 {string} string "Switching Protocols"
 // @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
-// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
 // @Failure 500 {object} schema.DocNormalResponse "server error"
 // @Router /api/msg/ws/chat [get]
 func (s *Service) WSChat(ctx *gin.Context) {
@@ -30,27 +31,110 @@ func (s *Service) WSChat(ctx *gin.Context) {
 		return
 	}
 
-	cid := ctx.Query("id")
-	if !model.IsValidUUID(cid) {
-		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid chat id."})
+	var chats []string
+	if err := s.DB.WithContext(ctx).FetchUserChatIDs(uid, &chats); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	conn, err := upgrade.Upgrade(ctx.Writer, ctx.Request, nil)
 	if err != nil {
+		slog.WarnContext(ctx, "Failed to upgrade the websocket connection", "component", "chat", "err", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to create a websocket connection."})
 		return
 	}
 
-	client := &core.Client{
-		Conn:     conn,
-		UserID:   uid,
-		ChatID:   cid,
-		SendChan: make(chan *schema.ViewMessage),
-	}
+	// The socket outlives the handler, so it keeps the request values without its cancellation
+	client := core.NewClient(context.WithoutCancel(ctx.Request.Context()), conn, uid, chats)
 
 	s.Chat.Register <- client
 
 	go client.ReadPump(s.Chat)
 	go client.WritePump()
 }
+
+// SSEChat godoc
+// @Summary  A server-sent events stream of the chat and personal events
+// @Description A fallback for the clients that can't keep a websocket open (proxies breaking the upgrade), delivering the same events as the chat socket, every data line is a schema.WSEnvelope. The stream is read only, actions go through the REST endpoints. A reconnecting client sends the id of the last event it got in the Last-Event-ID header (or the last_event_id query) and first receives the events it missed, or a resync event when they are past the replay buffer
+// @Tags Msg
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "Id of the last event received"
+// @Param last_event_id query string false "Id of the last event received, for the clients that can't set the header"
+// @Security BearerAuth
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid event id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 500 {object} schema.DocNormalResponse "server error"
+// @Router /api/msg/sse [get]
+func (s *Service) SSEChat(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	lastID := ctx.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = ctx.Query("last_event_id")
+	}
+	var after int64
+	if lastID != "" {
+		var err error
+		if after, err = strconv.ParseInt(lastID, 10, 64); err != nil || after < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid event id."})
+			return
+		}
+	}
+
+	var chats []string
+	if err := s.DB.WithContext(ctx).FetchUserChatIDs(uid, &chats); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// Nginx would otherwise hold the events back in its buffers
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	client := core.NewClient(context.WithoutCancel(ctx.Request.Context()), nil, uid, chats)
+	s.Chat.Register <- client
+
+	client.ServeEvents(ctx.Request.Context(), s.Chat, ctx.Writer, after, chats)
+}
+
+// presenceOf -> Returns the presence state shown to others for each user, users hiding their presence always look offline
+func (s *Service) presenceOf(ctx context.Context, users []*model.User) map[string]string {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		if !user.HidePresence {
+			ids = append(ids, user.ID)
+		}
+	}
+
+	presence, err := s.Chat.Presence(ids)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to fetch presence, showing users offline", "component", "chat", "err", err)
+	}
+
+	result := make(map[string]string, len(users))
+	for _, user := range users {
+		result[user.ID] = core.PresenceOffline
+		if status, ok := presence[user.ID]; ok {
+			result[user.ID] = status
+		}
+	}
+	return result
+}
+
+// lastSeen -> Returns the last seen time shown to others, nil when the user hides their presence
+func lastSeen(user *model.User) *time.Time {
+	if user.HidePresence {
+		return nil
+	}
+	return user.LastSeen
+}
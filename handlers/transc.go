@@ -2,13 +2,14 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha512"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -27,19 +28,20 @@ type Transc interface {
 	ViewPlans(ctx *gin.Context)
 	VerifyTranscWebhook(ctx *gin.Context)
 	RetryFailedPayment(ctx *gin.Context)
-	FetchCustomerDetails(cusCode string) (*schema.UpdatedPaymentInfo, error)
+	FetchCustomerDetails(ctx context.Context, cusCode string) (*schema.UpdatedPaymentInfo, error)
 }
 
 type TranscService struct {
 	Email     core.Email
+	Notify    core.Notifier
 	DB        core.DB
 	RDB       core.Cache
 	SecretKey string
 	Client    *http.Client
 }
 
-func NewTranscService(db core.DB, rdb core.Cache, email core.Email, secret string, client *http.Client) *TranscService {
-	return &TranscService{DB: db, RDB: rdb, Email: email, SecretKey: secret, Client: client}
+func NewTranscService(db core.DB, rdb core.Cache, email core.Email, notify core.Notifier, secret string, client *http.Client) *TranscService {
+	return &TranscService{DB: db, RDB: rdb, Email: email, Notify: notify, SecretKey: secret, Client: client}
 }
 
 // GetTransactions godoc
@@ -62,7 +64,7 @@ func (t *TranscService) GetTransactions(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := t.DB.FetchUserPreloadT(&user, uid); err != nil {
+	if err := t.DB.WithContext(ctx).FetchUserPreloadT(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -114,7 +116,7 @@ func (t *TranscService) InitializeTransaction(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := t.DB.FetchUser(&user, uid); err != nil {
+	if err := t.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -135,7 +137,7 @@ func (t *TranscService) InitializeTransaction(ctx *gin.Context) {
 
 	resp, err := t.Client.Do(req)
 	if err != nil || resp.StatusCode != http.StatusOK {
-		log.Println("[TRANSACTION] Failed to initialize paystack transaction, err -> ", err)
+		slog.ErrorContext(ctx, "Failed to initialize paystack transaction", "component", "transaction", "err", err)
 		ctx.JSON(http.StatusBadGateway, gin.H{"msg": "Failed to initialize paystack transaction"})
 		return
 	}
@@ -147,7 +149,7 @@ func (t *TranscService) InitializeTransaction(ctx *gin.Context) {
 	data, _ := io.ReadAll(resp.Body)
 
 	if err := json.Unmarshal(data, &paystack); err != nil {
-		log.Println("[TRANSACTION] Failed to unmarshal the response of the paystack init transaction, err -> ", err)
+		slog.ErrorContext(ctx, "Failed to unmarshal the response of the paystack init transaction", "component", "transaction", "err", err)
 		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse paystack response"})
 	}
 
@@ -170,6 +172,11 @@ func (t *TranscService) InitializeTransaction(ctx *gin.Context) {
 // @Failure 500 {object} schema.DocNormalResponse "Server error"
 // @Router /api/transc/webhook [post]
 func (t *TranscService) VerifyTranscWebhook(ctx *gin.Context) {
+	eventType := "unknown"
+	defer func() {
+		core.PaystackWebhookEvents.WithLabelValues(eventType, webhookOutcome(ctx.Writer.Status())).Inc()
+	}()
+
 	body, err := io.ReadAll(ctx.Request.Body)
 	if err != nil {
 		ctx.Status(http.StatusBadRequest)
@@ -188,16 +195,24 @@ func (t *TranscService) VerifyTranscWebhook(ctx *gin.Context) {
 	}
 
 	var event schema.PaystackEvent
-	log.Println(string(body))
 	if err := json.Unmarshal(body, &event); err != nil {
-		log.Println("[TRANSACTION] An error occured in the webhook for the transaction while parsing payload, err -> ", err.Error())
+		slog.ErrorContext(ctx, "Failed to parse the paystack webhook payload", "component", "transaction", "err", err)
 		ctx.Status(http.StatusUnprocessableEntity)
 		return
 	}
 
+	slog.InfoContext(ctx, "Paystack webhook received", "component", "transaction", "event", event.Event)
+
+	switch event.Event {
+	case model.PaystackChargeSuccess, model.PaystackInvoiceUpdate, model.PaystackSubscriptionCreate, model.PaystackSubscriptionNotRenew:
+		eventType = event.Event
+	default:
+		eventType = "other"
+	}
+
 	var user model.User
-	if err := t.DB.SearchUserEmail(&user, event.Data.Customer.Email); err != nil {
-		log.Println("[TRANSACTION] Failed to complete transaction as the customer could not be identified, err -> ", err.Error())
+	if err := t.DB.WithContext(ctx).SearchUserEmail(&user, event.Data.Customer.Email); err != nil {
+		slog.ErrorContext(ctx, "Failed to complete transaction as the customer could not be identified", "component", "transaction", "err", err)
 		ctx.Status(http.StatusUnauthorized)
 		return
 	}
@@ -214,13 +229,14 @@ func (t *TranscService) VerifyTranscWebhook(ctx *gin.Context) {
 		user.ExpMonth = &event.Data.Authorization.ExpMonth
 		user.ExpYear = &event.Data.Authorization.ExpYear
 
-		if err := t.DB.SaveUser(&user); err != nil {
+		if err := t.DB.WithContext(ctx).SaveUser(&user); err != nil {
 			ctx.Status(http.StatusInternalServerError)
 			return
 		}
 
 		amount := fmt.Sprintf("%d", event.Data.Amount)
-		t.Email.QueueSubscriptionCreate(user.UserName, amount, event.Data.Currency, event.Data.Plan.Name, "", user.Email)
+		t.Email.QueueSubscriptionCreate(ctx, user.UserName, amount, event.Data.Currency, event.Data.Plan.Name, "", user.Email)
+		t.Notify.Notify(ctx, user.ID, model.NotifySubscriptionCreated, "Subscription started", fmt.Sprintf("You are now subscribed to the %s plan.", event.Data.Plan.Name), nil)
 
 		ctx.Status(http.StatusOK)
 		return
@@ -251,8 +267,8 @@ func (t *TranscService) VerifyTranscWebhook(ctx *gin.Context) {
 
 		if isManual && model.IsValidUUID(sid) {
 			var sub model.Subscriptions
-			if err := t.DB.FetchSub(&sub, sid); err != nil {
-				log.Println("[TRANSACTION] An error occured while trying to fetch sub for manual retry payment, err -> ", err.Error())
+			if err := t.DB.WithContext(ctx).FetchSub(&sub, sid); err != nil {
+				slog.ErrorContext(ctx, "Failed to fetch sub for manual retry payment", "component", "transaction", "err", err)
 				ctx.Status(http.StatusInternalServerError)
 				return
 			}
@@ -261,12 +277,12 @@ func (t *TranscService) VerifyTranscWebhook(ctx *gin.Context) {
 			user.LastSub = &sub.EndDate
 			user.NextPaymentDate = user.LastSub
 
-			if err := t.DB.AddTranscSaveSub(&transc, &sub, &user); err != nil {
+			if err := t.DB.WithContext(ctx).AddTranscSaveSub(&transc, &sub, &user); err != nil {
 				ctx.Status(http.StatusInternalServerError)
 				return
 			}
 		} else {
-			details, err := t.FetchSubscriptionDetails(*user.PaystackSubCode)
+			details, err := t.FetchSubscriptionDetails(ctx, *user.PaystackSubCode)
 			if err != nil || details == nil {
 				ctx.Status(http.StatusInternalServerError)
 				return
@@ -283,7 +299,7 @@ func (t *TranscService) VerifyTranscWebhook(ctx *gin.Context) {
 			user.NextPaymentDate = &sub.EndDate
 			user.LastSub = user.NextPaymentDate
 
-			if err := t.DB.AddTranscSub(&transc, &sub, &user); err != nil {
+			if err := t.DB.WithContext(ctx).AddTranscSub(&transc, &sub, &user); err != nil {
 				ctx.Status(http.StatusInternalServerError)
 				return
 			}
@@ -304,22 +320,24 @@ func (t *TranscService) VerifyTranscWebhook(ctx *gin.Context) {
 			grace := user.LastSub.Add(time.Hour * 24 * 7)
 			user.LastSub = &grace
 
-			if err := t.DB.AddFailedSub(&sub, &user); err != nil {
+			if err := t.DB.WithContext(ctx).AddFailedSub(&sub, &user); err != nil {
 				ctx.Status(http.StatusInternalServerError)
 				return
 			}
 
 			amount := fmt.Sprintf("%d", event.Data.Amount)
-			t.Email.QueueTransactionFailedEmail(user.UserName, amount, event.Data.Currency, event.Data.Plan.Name, "", user.Email)
+			t.Email.QueueTransactionFailedEmail(ctx, user.UserName, amount, event.Data.Currency, event.Data.Plan.Name, "", user.Email)
+			t.Notify.Notify(ctx, user.ID, model.NotifyPaymentFailed, "Payment failed", fmt.Sprintf("The payment of %s %s for the %s plan failed, update your card or retry it to keep your subscription.", amount, event.Data.Currency, event.Data.Plan.Name), &sub.ID)
 		}
 
 		ctx.Status(http.StatusOK)
 		return
 	case model.PaystackSubscriptionNotRenew:
-		t.Email.QueueSubscriptionCancelled(user.UserName, user.NextPaymentDate.Format("January 02, 2006"), user.Email)
+		t.Email.QueueSubscriptionCancelled(ctx, user.UserName, user.NextPaymentDate.Format("January 02, 2006"), user.Email)
+		t.Notify.Notify(ctx, user.ID, model.NotifySubscriptionEnded, "Subscription cancelled", "Your subscription won't renew, it stays active until "+user.NextPaymentDate.Format("January 02, 2006")+".", nil)
 
 		user.NextPaymentDate = nil
-		if err := t.DB.SaveUser(&user); err != nil {
+		if err := t.DB.WithContext(ctx).SaveUser(&user); err != nil {
 			ctx.Status(http.StatusInternalServerError)
 		}
 	default:
@@ -328,6 +346,20 @@ func (t *TranscService) VerifyTranscWebhook(ctx *gin.Context) {
 	}
 }
 
+// webhookOutcome -> Maps the webhook response status to a metrics outcome
+func webhookOutcome(status int) string {
+	switch {
+	case status < 300:
+		return "processed"
+	case status == http.StatusUnauthorized:
+		return "rejected"
+	case status < 500:
+		return "invalid"
+	default:
+		return "failed"
+	}
+}
+
 // UpdateSubscriptionCard godoc
 // @Summary This is an endpoint for udpating card details on paystack
 // @Description This is an endpoint for retrieving link for updating card details used for transaction on paystack
@@ -350,7 +382,7 @@ func (t *TranscService) UpdateSubscriptionCard(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := t.DB.FetchUser(&user, uid); err != nil {
+	if err := t.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -362,7 +394,7 @@ func (t *TranscService) UpdateSubscriptionCard(ctx *gin.Context) {
 
 	resp, err := t.Client.Do(req)
 	if err != nil || resp.StatusCode != http.StatusOK {
-		log.Println("[TRANSACTION] An error occured while trying to generate a update card link on paystack, err -> ", err)
+		slog.ErrorContext(ctx, "Failed to generate an update card link on paystack", "component", "transaction", "err", err)
 		ctx.JSON(http.StatusBadGateway, gin.H{"msg": "Failed to communicate with paystack"})
 		return
 	}
@@ -373,7 +405,7 @@ func (t *TranscService) UpdateSubscriptionCard(ctx *gin.Context) {
 	body, _ := io.ReadAll(resp.Body)
 
 	if err := json.Unmarshal(body, &card); err != nil {
-		log.Println("[TRANSACTION] An error occured while trying to Unmarshal payload from paystack, err -> ", err.Error())
+		slog.ErrorContext(ctx, "Failed to unmarshal payload from paystack", "component", "transaction", "err", err)
 		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse response from paystack"})
 		return
 	}
@@ -384,7 +416,7 @@ func (t *TranscService) UpdateSubscriptionCard(ctx *gin.Context) {
 	}
 
 	user.PaystackCardUpdate = true
-	if err := t.DB.SaveUser(&user); err != nil {
+	if err := t.DB.WithContext(ctx).SaveUser(&user); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -424,14 +456,14 @@ func (t *TranscService) RetryFailedPayment(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := t.DB.FetchUser(&user, uid); err != nil {
+	if err := t.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	var sub model.Subscriptions
-	if err := t.DB.FetchSub(&sub, subID); err != nil {
+	if err := t.DB.WithContext(ctx).FetchSub(&sub, subID); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -442,7 +474,7 @@ func (t *TranscService) RetryFailedPayment(ctx *gin.Context) {
 		return
 	}
 
-	plans, err := t.RetrievePlans()
+	plans, err := t.RetrievePlans(ctx)
 	if err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
@@ -486,7 +518,7 @@ func (t *TranscService) RetryFailedPayment(ctx *gin.Context) {
 	chargeData, _ := io.ReadAll(chargeResp.Body)
 
 	if err := json.Unmarshal(chargeData, &chargeResponse); err != nil {
-		log.Println("[TRANSACTION] An error occured while trying to unmarshal the response of paystack, err -> ", err.Error())
+		slog.ErrorContext(ctx, "Failed to unmarshal the response of paystack", "component", "transaction", "err", err)
 		ctx.JSON(http.StatusBadGateway, gin.H{"msg": "Unable to communicate with paystack to initiate transaction."})
 		return
 	}
@@ -521,7 +553,7 @@ func (t *TranscService) CancelSubscription(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := t.DB.FetchUser(&user, uid); err != nil {
+	if err := t.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -540,7 +572,7 @@ func (t *TranscService) CancelSubscription(ctx *gin.Context) {
 
 	resp, err := t.Client.Do(req)
 	if err != nil || resp.StatusCode != http.StatusOK {
-		log.Println("[TRANSACTION] An error occured while trying to cancel a subscription, err -> ", err)
+		slog.ErrorContext(ctx, "Failed to cancel a subscription on paystack", "component", "transaction", "err", err)
 		ctx.JSON(http.StatusBadGateway, gin.H{"msg": "Failed to communicate with paystack."})
 		return
 	}
@@ -551,7 +583,7 @@ func (t *TranscService) CancelSubscription(ctx *gin.Context) {
 	body, _ = io.ReadAll(resp.Body)
 
 	if err := json.Unmarshal(body, &sub); err != nil {
-		log.Println("[TRANSACTION] An error occured while trying to Unmarshal payload from paystack, err -> ", err.Error())
+		slog.ErrorContext(ctx, "Failed to unmarshal payload from paystack", "component", "transaction", "err", err)
 		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse response from paystack"})
 		return
 	}
@@ -583,7 +615,7 @@ func (t *TranscService) ViewPlans(ctx *gin.Context) {
 		return
 	}
 
-	plans, err := t.RetrievePlans()
+	plans, err := t.RetrievePlans(ctx)
 	if err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
@@ -594,8 +626,8 @@ func (t *TranscService) ViewPlans(ctx *gin.Context) {
 }
 
 // RetrievePlans -> A helper func to retrieve plans from cache if available or fetch from paystack
-func (t *TranscService) RetrievePlans() ([]schema.ViewPlansResp, error) {
-	if plan, err := t.RDB.RetrieveCachedPlans(); err == nil && plan != nil && len(plan) != 0 {
+func (t *TranscService) RetrievePlans(ctx context.Context) ([]schema.ViewPlansResp, error) {
+	if plan, err := t.RDB.WithContext(ctx).RetrieveCachedPlans(); err == nil && plan != nil && len(plan) != 0 {
 		return plan, nil
 	}
 
@@ -613,7 +645,7 @@ func (t *TranscService) RetrievePlans() ([]schema.ViewPlansResp, error) {
 	body, _ := io.ReadAll(resp.Body)
 
 	if err := json.Unmarshal(body, &plans); err != nil {
-		log.Println("[TRANSACTION] An error occured while trying to Unmarshal payload from paystack, err -> ", err.Error())
+		slog.ErrorContext(ctx, "Failed to unmarshal payload from paystack", "component", "transaction", "err", err)
 		return nil, &core.CustomMessage{Code: http.StatusUnprocessableEntity, Message: "Failed to parse response from paystack."}
 	}
 
@@ -633,12 +665,12 @@ func (t *TranscService) RetrievePlans() ([]schema.ViewPlansResp, error) {
 		})
 	}
 
-	_ = t.RDB.CachePlans(res)
+	_ = t.RDB.WithContext(ctx).CachePlans(res)
 	return res, nil
 }
 
 // FetchSubscriptionDetails -> A helper func to fetch the subscription details from paystack
-func (t *TranscService) FetchSubscriptionDetails(subCode string) (*schema.SubscriptionDetails, error) {
+func (t *TranscService) FetchSubscriptionDetails(ctx context.Context, subCode string) (*schema.SubscriptionDetails, error) {
 	req, _ := http.NewRequest(http.MethodGet, "https://api.paystack.co/subscription/"+subCode, nil)
 	req.Header.Set("Authorization", "Bearer "+t.SecretKey)
 
@@ -653,7 +685,7 @@ func (t *TranscService) FetchSubscriptionDetails(subCode string) (*schema.Subscr
 	body, _ := io.ReadAll(resp.Body)
 
 	if err := json.Unmarshal(body, &sub); err != nil {
-		log.Println("[TRANSACTION] Failed to unmarshal paystack response for subscription details, err -> ", err.Error())
+		slog.ErrorContext(ctx, "Failed to unmarshal paystack response for subscription details", "component", "transaction", "err", err)
 		return nil, &core.CustomMessage{Code: http.StatusUnprocessableEntity, Message: "Failed to unmarshal paystack response"}
 	}
 
@@ -661,7 +693,7 @@ func (t *TranscService) FetchSubscriptionDetails(subCode string) (*schema.Subscr
 }
 
 // FetchCustomerDetails -> A helper func to fetch the customer details from paystack
-func (t *TranscService) FetchCustomerDetails(cusCode string) (*schema.UpdatedPaymentInfo, error) {
+func (t *TranscService) FetchCustomerDetails(ctx context.Context, cusCode string) (*schema.UpdatedPaymentInfo, error) {
 	req, _ := http.NewRequest(http.MethodGet, "https://api.paystack.co/customer/"+cusCode, nil)
 	req.Header.Set("Authorization", "Bearer "+t.SecretKey)
 
@@ -676,7 +708,7 @@ func (t *TranscService) FetchCustomerDetails(cusCode string) (*schema.UpdatedPay
 	body, _ := io.ReadAll(resp.Body)
 
 	if err := json.Unmarshal(body, &cus); err != nil {
-		log.Println("[TRANSACTION] Failed to unmarshal paystack response for customer details, err -> ", err.Error())
+		slog.ErrorContext(ctx, "Failed to unmarshal paystack response for customer details", "component", "transaction", "err", err)
 		return nil, &core.CustomMessage{Code: http.StatusUnprocessableEntity, Message: "Failed to unmarshal paystack response"}
 	}
 
@@ -1,7 +1,9 @@
 package handlers
 
 import (
-	"log"
+	"bytes"
+	"html/template"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -29,14 +31,14 @@ import (
 func (s *Service) AddUser(ctx *gin.Context) {
 	var payload schema.SignupRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		log.Println(err)
+		slog.DebugContext(ctx, "Failed to parse the signup payload", "err", err)
 		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
 		return
 	}
 
 	// Checking for existing username | email
 	var existingUser model.User
-	if err := s.DB.CheckExistingUser(&existingUser, payload.Email, payload.UserName); err != nil {
+	if err := s.DB.WithContext(ctx).CheckExistingUser(&existingUser, payload.Email, payload.UserName); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -48,9 +50,9 @@ func (s *Service) AddUser(ctx *gin.Context) {
 		for i := range payload.Skills {
 			payload.Skills[i] = strings.ToLower(payload.Skills[i])
 		}
-		allskills, err = s.CheckAndUpdateSkills(payload.Skills)
+		allskills, err = s.CheckAndUpdateSkills(ctx, payload.Skills)
 		if err != nil {
-			log.Printf("Failed to create skills for new user -> %s", err)
+			slog.ErrorContext(ctx, "Failed to create skills for new user", "err", err)
 		}
 	}
 
@@ -74,7 +76,7 @@ func (s *Service) AddUser(ctx *gin.Context) {
 		Availability: true,
 	}
 
-	if err := s.DB.AddUser(&user); err != nil {
+	if err := s.DB.WithContext(ctx).AddUser(&user); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -82,12 +84,12 @@ func (s *Service) AddUser(ctx *gin.Context) {
 
 	jwtToken, err := GenerateJWT(user.ID, "login", true, JWTExpiry)
 	if err != nil {
-		log.Println("[APP] Failed to generate jwt token -> ", err.Error())
+		slog.ErrorContext(ctx, "Failed to generate jwt token", "err", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to generate jwt token."})
 		return
 	}
 
-	s.Emb.QueueUserCreate(user.ID, user.Bio, payload.Skills, user.Interests)
+	s.Emb.QueueUserCreate(ctx, user.ID, user.Bio, payload.Skills, user.Interests)
 
 	ctx.JSON(http.StatusCreated, gin.H{"token": jwtToken})
 }
@@ -112,7 +114,7 @@ func (s *Service) VerifyUser(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.VerifyUser(&user, payload.UserName); err != nil {
+	if err := s.DB.WithContext(ctx).VerifyUser(&user, payload.UserName); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -156,7 +158,7 @@ func (s *Service) GetUser(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUserPreloadSP(&user, userID); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUserPreloadSP(&user, userID); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -222,7 +224,7 @@ func (s *Service) GetUserInfo(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUserPreloadS(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUserPreloadS(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -271,7 +273,7 @@ func (s *Service) GetUserPaymentInfo(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -284,7 +286,7 @@ func (s *Service) GetUserPaymentInfo(ctx *gin.Context) {
 
 	if user.PaystackCardUpdate {
 		if user.PaystackCusCode != nil {
-			if cus, err := s.Transc.FetchCustomerDetails(*user.PaystackCusCode); err != nil {
+			if cus, err := s.Transc.FetchCustomerDetails(ctx, *user.PaystackCusCode); err != nil {
 				user.Last4 = &cus.Last4
 				user.ExpMonth = &cus.ExpMonth
 				user.ExpYear = &cus.ExpYear
@@ -292,7 +294,7 @@ func (s *Service) GetUserPaymentInfo(ctx *gin.Context) {
 				user.PaystackAuthCode = &cus.AuthorizationCode
 				user.PaystackCardUpdate = false
 
-				if err := s.DB.SaveUser(&user); err != nil {
+				if err := s.DB.WithContext(ctx).SaveUser(&user); err != nil {
 					cm := err.(*core.CustomMessage)
 					ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 					return
@@ -361,15 +363,15 @@ func (s *Service) RecommendProjects(ctx *gin.Context) {
 		return
 	}
 
-	rec, err := s.Rec.GetRecommendation(uid, core.ProjectRecommendation)
+	rec, err := s.Rec.GetRecommendation(ctx, uid, core.ProjectRecommendation)
 	if err != nil || rec == nil {
-		log.Printf("[gRPC Recommendation] Failed to get recommendation for user -> %v, err -> %v", uid, err)
+		slog.ErrorContext(ctx, "Failed to get recommendation for user", "component", "recommendation", "err", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to retreive projects for the user."})
 		return
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -382,7 +384,7 @@ func (s *Service) RecommendProjects(ctx *gin.Context) {
 	}
 
 	var projects []model.Project
-	if err := s.DB.FindProjects(&projects, ids); err != nil {
+	if err := s.DB.WithContext(ctx).FindProjects(&projects, ids); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -441,7 +443,7 @@ func (s *Service) ViewUser(ctx *gin.Context) {
 		return
 	}
 	var user model.User
-	if err := s.DB.SearchUserPreloadSP(&user, username); err != nil {
+	if err := s.DB.WithContext(ctx).SearchUserPreloadSP(&user, username); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -511,7 +513,7 @@ func (s *Service) ViewGitUser(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.SearchUserGitPreloadSP(&user, username); err != nil {
+	if err := s.DB.WithContext(ctx).SearchUserGitPreloadSP(&user, username); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -580,7 +582,7 @@ func (s *Service) ViewUserbySkills(ctx *gin.Context) {
 	}
 
 	var users []model.User
-	if err := s.DB.SearchUsersBySKills(&users, payload.Skills, uid); err != nil {
+	if err := s.DB.WithContext(ctx).SearchUsersBySKills(&users, payload.Skills, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -635,26 +637,26 @@ func (s *Service) SendFriendReq(ctx *gin.Context) {
 		return
 	}
 
-	if err, friends := s.DB.CheckExistingFriends(uid, payload.ID); err != nil || friends {
+	if err, friends := s.DB.WithContext(ctx).CheckExistingFriends(uid, payload.ID); err != nil || friends {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if err, exists := s.DB.CheckExistingFriendReq(uid, payload.ID); err != nil || exists {
+	if err, exists := s.DB.WithContext(ctx).CheckExistingFriendReq(uid, payload.ID); err != nil || exists {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	var friend, user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if err := s.DB.FetchUser(&friend, payload.ID); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&friend, payload.ID); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -671,13 +673,21 @@ func (s *Service) SendFriendReq(ctx *gin.Context) {
 		req.Message = payload.Message
 	}
 
-	if err := s.DB.AddFriendReq(&req); err != nil {
+	if err := s.DB.WithContext(ctx).AddFriendReq(&req); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	s.Email.QueueFriendReqEmail(user.UserName, friend.UserName, req.Message, "", friend.Email)
+	s.Email.QueueFriendReqEmail(ctx, user.UserName, friend.UserName, req.Message, "", friend.Email)
+	s.Notify.Notify(ctx, friend.ID, model.NotifyFriendRequest, "New friend request", user.UserName+" sent you a friend request.", &req.ID)
+	s.Chat.PublishUser(friend.ID, core.NewEvent(schema.EventFriendRequest, "", ctx.GetString("requestID"), schema.FriendReqStatus{
+		ID:       req.ID,
+		Username: user.UserName,
+		Message:  req.Message,
+		Status:   req.Status,
+		Sent:     req.CreatedAt,
+	}))
 
 	friendReq := schema.FriendReqStatus{
 		ID:       req.ID,
@@ -709,7 +719,7 @@ func (s *Service) ViewFriendReq(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.ViewFriendReq(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).ViewFriendReq(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -768,14 +778,14 @@ func (s *Service) UpdateFriendReqStatus(ctx *gin.Context) {
 	}
 
 	var req model.FriendReq
-	if err := s.DB.FetchFriendReq(&req, reqID); err != nil {
+	if err := s.DB.WithContext(ctx).FetchFriendReq(&req, reqID); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -788,7 +798,7 @@ func (s *Service) UpdateFriendReqStatus(ctx *gin.Context) {
 
 	var friend model.User
 	var chat model.Chat
-	if err := s.DB.FetchUser(&friend, req.UserID); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&friend, req.UserID); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -796,17 +806,25 @@ func (s *Service) UpdateFriendReqStatus(ctx *gin.Context) {
 
 	switch status {
 	case model.StatusRejected:
-		if err := s.DB.UpdateFriendReqReject(&req); err != nil {
+		if err := s.DB.WithContext(ctx).UpdateFriendReqReject(&req); err != nil {
 			cm := err.(*core.CustomMessage)
 			ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 			return
 		}
 	case model.StatusAccepted:
-		if err := s.DB.UpdateFriendReqAccept(&req, &user, &friend, &chat); err != nil {
+		if err := s.DB.WithContext(ctx).UpdateFriendReqAccept(&req, &user, &friend, &chat); err != nil {
 			cm := err.(*core.CustomMessage)
 			ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 			return
 		}
+
+		s.Chat.SubscribeUser(chat.ID, user.ID)
+		s.Chat.SubscribeUser(chat.ID, friend.ID)
+		s.Chat.PublishUser(friend.ID, core.NewEvent(schema.EventFriendAccepted, "", ctx.GetString("requestID"), schema.WSFriendAccepted{
+			UserID:   user.ID,
+			UserName: user.UserName,
+			ChatID:   chat.ID,
+		}))
 	default:
 		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid status."})
 		return
@@ -844,14 +862,14 @@ func (s *Service) DeleteSentReq(ctx *gin.Context) {
 	}
 
 	var req model.FriendReq
-	if err := s.DB.FetchFriendReq(&req, reqID); err != nil {
+	if err := s.DB.WithContext(ctx).FetchFriendReq(&req, reqID); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -862,7 +880,7 @@ func (s *Service) DeleteSentReq(ctx *gin.Context) {
 		return
 	}
 
-	if err := s.DB.DeleteFriendReq(&req); err != nil {
+	if err := s.DB.WithContext(ctx).DeleteFriendReq(&req); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -891,18 +909,22 @@ func (s *Service) ViewUserFriends(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUserPreloadF(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUserPreloadF(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
+	presence := s.presenceOf(ctx, user.Friends)
+
 	var friends []schema.ViewFriends
 	for _, fr := range user.Friends {
 		friends = append(friends, schema.ViewFriends{
 			ID:       fr.ID,
 			Username: fr.UserName,
 			Bio:      fr.Bio,
+			Presence: presence[fr.ID],
+			LastSeen: lastSeen(fr),
 		})
 	}
 
@@ -937,7 +959,7 @@ func (s *Service) ViewUserFriendsByID(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUserPreloadF(&user, id); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUserPreloadF(&user, id); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -984,31 +1006,33 @@ func (s *Service) DeleteUserFriend(ctx *gin.Context) {
 	}
 
 	var user, friend model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if err := s.DB.FetchUser(&friend, id); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&friend, id); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	var chat model.Chat
-	if err := s.DB.FetchChat(cid, &chat); err != nil {
+	if err := s.DB.WithContext(ctx).FetchChat(cid, &chat); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if err := s.DB.DeleteFriend(&user, &friend, &chat); err != nil {
+	if err := s.DB.WithContext(ctx).DeleteFriend(&user, &friend, &chat); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
+	s.Chat.CloseRoom(chat.ID, "This chat was deleted.")
+
 	ctx.JSON(http.StatusNoContent, nil)
 }
 
@@ -1032,20 +1056,20 @@ func (s *Service) ForgotPassword(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.SearchUserEmail(&user, payload.Email); err != nil {
+	if err := s.DB.WithContext(ctx).SearchUserEmail(&user, payload.Email); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	token := core.GenerateOTP()
-	if err := s.RDB.SetOTP(token, user.ID); err != nil {
+	if err := s.RDB.WithContext(ctx).SetOTP(token, user.ID); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	s.Email.QueueForgotPassEmail(user.Email, user.UserName, token)
+	s.Email.QueueForgotPassEmail(ctx, user.Email, user.UserName, token)
 
 	ctx.JSON(http.StatusOK, gin.H{"msg": "Check email for otp."})
 }
@@ -1069,7 +1093,7 @@ func (s *Service) VerifyOTP(ctx *gin.Context) {
 		return
 	}
 
-	uid, err := s.RDB.GetOTP(id)
+	uid, err := s.RDB.WithContext(ctx).GetOTP(id)
 	if err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
@@ -1113,7 +1137,7 @@ func (s *Service) ResetPassword(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1127,7 +1151,7 @@ func (s *Service) ResetPassword(ctx *gin.Context) {
 
 	user.Password = hashed
 
-	if err := s.DB.SaveUser(&user); err != nil {
+	if err := s.DB.WithContext(ctx).SaveUser(&user); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1165,13 +1189,13 @@ func (s *Service) UpdateUserInfo(ctx *gin.Context) {
 		return
 	}
 
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if err := s.DB.CheckExistingUserUpdate(&existingUser, payload.Email, payload.UserName, user.ID); err != nil {
+	if err := s.DB.WithContext(ctx).CheckExistingUserUpdate(&existingUser, payload.Email, payload.UserName, user.ID); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1182,7 +1206,7 @@ func (s *Service) UpdateUserInfo(ctx *gin.Context) {
 	user.UserName = payload.UserName
 	user.Country = payload.Country
 
-	if err := s.DB.SaveUser(&user); err != nil {
+	if err := s.DB.WithContext(ctx).SaveUser(&user); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1231,14 +1255,14 @@ func (s *Service) UpdateUserBio(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUserPreloadS(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUserPreloadS(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	user.Bio = payload.Bio
-	if err := s.DB.SaveUser(&user); err != nil {
+	if err := s.DB.WithContext(ctx).SaveUser(&user); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1249,7 +1273,7 @@ func (s *Service) UpdateUserBio(ctx *gin.Context) {
 		skills = append(skills, skill.Name)
 	}
 
-	s.Emb.QueueUserUpdate(user.ID, user.Bio, skills, user.Interests)
+	s.Emb.QueueUserUpdate(ctx, user.ID, user.Bio, skills, user.Interests)
 
 	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Bio updated successfully."})
 }
@@ -1282,14 +1306,14 @@ func (s *Service) UpdateUserInterests(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUserPreloadS(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUserPreloadS(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
 	user.Interests = payload.Interest
-	if err := s.DB.SaveUser(&user); err != nil {
+	if err := s.DB.WithContext(ctx).SaveUser(&user); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1300,7 +1324,7 @@ func (s *Service) UpdateUserInterests(ctx *gin.Context) {
 		skills = append(skills, skill.Name)
 	}
 
-	s.Emb.QueueUserUpdate(user.ID, user.Bio, skills, user.Interests)
+	s.Emb.QueueUserUpdate(ctx, user.ID, user.Bio, skills, user.Interests)
 
 	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Interests updated successfully."})
 }
@@ -1333,7 +1357,7 @@ func (s *Service) UpdateUserPassword(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1353,7 +1377,7 @@ func (s *Service) UpdateUserPassword(ctx *gin.Context) {
 	}
 	user.Password = hashed
 
-	if err := s.DB.SaveUser(&user); err != nil {
+	if err := s.DB.WithContext(ctx).SaveUser(&user); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1391,7 +1415,7 @@ func (s *Service) UpdateUserAvaibilityStatus(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1399,17 +1423,188 @@ func (s *Service) UpdateUserAvaibilityStatus(ctx *gin.Context) {
 
 	user.Availability = statusbool
 
-	if err := s.DB.SaveUser(&user); err != nil {
+	if err := s.DB.WithContext(ctx).SaveUser(&user); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	s.Emb.QueueUserUpdateStatus(user.ID, user.Availability)
+	s.Emb.QueueUserUpdateStatus(ctx, user.ID, user.Availability)
 
 	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Availability updated successfully."})
 }
 
+// UpdatePresenceVisibility godoc
+// @Summary    Show or hide the online status
+// @Description An endpoint for choosing whether friends can see the online status and last seen time of the current user, hidden users always look offline
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param visible path string true "Presence visibility"
+// @Security BearerAuth
+// @Success 202 {object} schema.DocNormalResponse "Visibility updated"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "server error"
+// @Router /api/user/update-presence-visibility/{visible} [patch]
+func (s *Service) UpdatePresenceVisibility(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	visible, err := strconv.ParseBool(ctx.Param("visible"))
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Presence visibility can only be true or false."})
+		return
+	}
+
+	var user model.User
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	user.HidePresence = !visible
+	if err := s.DB.WithContext(ctx).SaveUser(&user); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Presence visibility updated successfully."})
+}
+
+// UpdateDigestFrequency godoc
+// @Summary    Choose how often the message digests come
+// @Description An endpoint for choosing how often the current user gets an email of the messages they missed while offline: instant (once a message stays unread for a few minutes), hourly, daily or off
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param frequency path string true "instant, hourly, daily or off"
+// @Security BearerAuth
+// @Success 202 {object} schema.DocNormalResponse "Frequency updated"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "server error"
+// @Router /api/user/update-digest/{frequency} [patch]
+func (s *Service) UpdateDigestFrequency(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	frequency := ctx.Param("frequency")
+	if !model.IsValidDigestFrequency(frequency) {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Digest frequency can only be instant, hourly, daily or off."})
+		return
+	}
+
+	var user model.User
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	user.DigestFrequency = frequency
+	if err := s.DB.WithContext(ctx).SaveUser(&user); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"msg": "Digest frequency updated successfully."})
+}
+
+// unsubscribePage -> Asks to confirm the unsubscribe, the form posts back to the link it was opened from
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Unsubscribe</title></head>
+<body style="font-family: sans-serif; max-width: 480px; margin: 48px auto; padding: 0 16px;">
+<h1 style="font-size: 20px;">Unsubscribe</h1>
+<p>{{if .}}You won't get these emails anymore.{{else}}You won't get message digests anymore.{{end}} You can turn them back on in your notification settings.</p>
+<form method="post"><button type="submit">Unsubscribe</button></form>
+</body>
+</html>
+`))
+
+// UnsubscribePage godoc
+// @Summary    Confirm an unsubscribe
+// @Description An endpoint for the unsubscribe links of the emails, working without logging in. It only shows a page asking to confirm, which posts to the same link, so link scanners opening the emails don't unsubscribe the users
+// @Tags User
+// @Produce html
+// @Param token query string true "Unsubscribe token"
+// @Param category query string false "Notification category" Enums(friend_requests, applications, subscription, trial, mentions)
+// @Success 200 {string} string "Confirmation page"
+// @Failure 404 {object} schema.DocNormalResponse "Invalid link"
+// @Failure 500 {object} schema.DocNormalResponse "server error"
+// @Router /unsubscribe [get]
+func (s *Service) UnsubscribePage(ctx *gin.Context) {
+	token, category := ctx.Query("token"), ctx.Query("category")
+	if token == "" || (category != "" && !model.HasChannel(category, model.ChannelEmail)) {
+		ctx.JSON(http.StatusNotFound, gin.H{"msg": "Invalid unsubscribe link."})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).CheckUnsubscribeToken(token); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	var page bytes.Buffer
+	if err := unsubscribePage.Execute(&page, category != ""); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to render the unsubscribe page."})
+		return
+	}
+
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
+
+// Unsubscribe godoc
+// @Summary    Unsubscribe from an email category
+// @Description An endpoint for confirming the unsubscribe links of the emails, working without logging in. Without a category it turns the message digests off, with one the emails of that category. Mail clients POST to it for the RFC 8058 one-click List-Unsubscribe header
+// @Tags User
+// @Produce json
+// @Param token query string true "Unsubscribe token"
+// @Param category query string false "Notification category" Enums(friend_requests, applications, subscription, trial, mentions)
+// @Success 200 {object} schema.DocNormalResponse "Unsubscribed"
+// @Failure 404 {object} schema.DocNormalResponse "Invalid link"
+// @Failure 500 {object} schema.DocNormalResponse "server error"
+// @Router /unsubscribe [post]
+func (s *Service) Unsubscribe(ctx *gin.Context) {
+	token, category := ctx.Query("token"), ctx.Query("category")
+	if token == "" || (category != "" && !model.HasChannel(category, model.ChannelEmail)) {
+		ctx.JSON(http.StatusNotFound, gin.H{"msg": "Invalid unsubscribe link."})
+		return
+	}
+
+	if category == "" {
+		if err := s.DB.WithContext(ctx).UnsubscribeDigest(token); err != nil {
+			cm := err.(*core.CustomMessage)
+			ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"msg": "You won't get message digests anymore."})
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).UnsubscribeEmail(token, category); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "You won't get these emails anymore."})
+}
+
 // UpdateUserSkills godoc
 // @Summary     Update User skills
 // @Description An endpoint for updating the skills of the current user it internally calls a service to update the user vector
@@ -1438,7 +1633,7 @@ func (s *Service) UpdateUserSkills(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1448,19 +1643,19 @@ func (s *Service) UpdateUserSkills(ctx *gin.Context) {
 		payload.Skills[i] = strings.ToLower(payload.Skills[i])
 	}
 
-	allskills, err := s.CheckAndUpdateSkills(payload.Skills)
+	allskills, err := s.CheckAndUpdateSkills(ctx, payload.Skills)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to update user skills."})
 		return
 	}
 
-	if err := s.DB.UpdateSkills(&user, allskills); err != nil {
+	if err := s.DB.WithContext(ctx).UpdateSkills(&user, allskills); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	s.Emb.QueueUserUpdate(user.ID, user.Bio, payload.Skills, user.Interests)
+	s.Emb.QueueUserUpdate(ctx, user.ID, user.Bio, payload.Skills, user.Interests)
 
 	ctx.JSON(http.StatusAccepted, gin.H{"skills": payload.Skills})
 }
@@ -1493,7 +1688,7 @@ func (s *Service) DeleteUserSkills(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUserPreloadS(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUserPreloadS(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1514,13 +1709,13 @@ func (s *Service) DeleteUserSkills(ctx *gin.Context) {
 		}
 	}
 
-	if err := s.DB.DeleteSkills(&user, skillsToDelete); err != nil {
+	if err := s.DB.WithContext(ctx).DeleteSkills(&user, skillsToDelete); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	s.Emb.QueueUserUpdate(user.ID, user.Bio, skills, user.Interests)
+	s.Emb.QueueUserUpdate(ctx, user.ID, user.Bio, skills, user.Interests)
 
 	ctx.JSON(http.StatusNoContent, nil)
 }
@@ -1545,7 +1740,7 @@ func (s *Service) ViewSubscriptions(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUserPreloadSub(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUserPreloadSub(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
@@ -1585,19 +1780,19 @@ func (s *Service) DeleteUserAccount(ctx *gin.Context) {
 	}
 
 	var user model.User
-	if err := s.DB.FetchUser(&user, uid); err != nil {
+	if err := s.DB.WithContext(ctx).FetchUser(&user, uid); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	if err := s.DB.DeleteUser(&user); err != nil {
+	if err := s.DB.WithContext(ctx).DeleteUser(&user); err != nil {
 		cm := err.(*core.CustomMessage)
 		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
 		return
 	}
 
-	s.Emb.QueueUserDelete(user.ID)
+	s.Emb.QueueUserDelete(ctx, user.ID)
 
 	ctx.JSON(http.StatusNoContent, nil)
 }
@@ -0,0 +1,423 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"findme/core"
+	"findme/model"
+	"findme/schema"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateWebhook godoc
+// @Summary    Create a webhook
+// @Description An endpoint for sending events to an url of the current user, or of one of their projects with project_id. The url must resolve to a public address, deliveries to internal addresses and redirects are refused. Events: application.received, application.accepted, project.updated and message.mention (not for project webhooks). Deliveries are signed in X-FindMe-Signature with sha256= and the hex HMAC-SHA256 of "<X-FindMe-Timestamp>.<body>" using the secret, which is generated when none is given and only shown here
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param payload body schema.CreateWebhook true "Webhook"
+// @Security BearerAuth
+// @Success 201 {object} schema.DocWebhook "Webhook created"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 403 {object} schema.DocNormalResponse "Permission denied"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 409 {object} schema.DocNormalResponse "Too many webhooks"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/user/create-webhook [post]
+func (s *Service) CreateWebhook(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var payload schema.CreateWebhook
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
+		return
+	}
+
+	if err := core.CheckWebhookURL(ctx, payload.URL); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	events, ok := webhookEvents(payload.Events, payload.ProjectID != "")
+	if !ok {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Invalid webhook event, project webhooks only get project events."})
+		return
+	}
+
+	hook := model.Webhook{UserID: uid, URL: payload.URL, Secret: payload.Secret, Events: events, Active: true}
+	if payload.ProjectID != "" {
+		if !model.IsValidUUID(payload.ProjectID) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid project id."})
+			return
+		}
+
+		var project model.Project
+		if err := s.DB.WithContext(ctx).FetchProject(&project, payload.ProjectID); err != nil {
+			cm := err.(*core.CustomMessage)
+			ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+			return
+		}
+		if project.UserID != uid {
+			ctx.JSON(http.StatusForbidden, gin.H{"msg": "Only the owner of the project can add webhooks to it."})
+			return
+		}
+		hook.ProjectID = &project.ID
+	}
+
+	var count int64
+	if err := s.DB.WithContext(ctx).CountWebhooks(uid, &count); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+	if count >= model.MaxWebhooks {
+		ctx.JSON(http.StatusConflict, gin.H{"msg": "You can't have more than 10 webhooks."})
+		return
+	}
+
+	if hook.Secret == "" {
+		secret, err := core.GenerateState()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to create the webhook."})
+			return
+		}
+		hook.Secret = secret
+	}
+
+	if err := s.DB.WithContext(ctx).AddWebhook(&hook); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	view := webhookView(&hook)
+	view.Secret = hook.Secret
+	ctx.JSON(http.StatusCreated, gin.H{"webhook": view})
+}
+
+// ViewWebhooks godoc
+// @Summary    View the webhooks
+// @Description An endpoint to view the webhooks of the current user and of their projects, without their secrets
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} schema.DocViewWebhooks "Webhooks"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/user/view-webhooks [get]
+func (s *Service) ViewWebhooks(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var hooks []model.Webhook
+	if err := s.DB.WithContext(ctx).FetchUserWebhooks(uid, &hooks); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	views := make([]schema.ViewWebhook, len(hooks))
+	for i := range hooks {
+		views[i] = webhookView(&hooks[i])
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"webhooks": views})
+}
+
+// UpdateWebhook godoc
+// @Summary    Update a webhook
+// @Description An endpoint for changing the url or events of a webhook of the current user, or turning it on or off. Turning a webhook disabled after repeated failures back on clears its failures
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param payload body schema.UpdateWebhook true "Webhook"
+// @Security BearerAuth
+// @Success 202 {object} schema.DocWebhook "Webhook updated"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 422 {object} schema.DocNormalResponse "Invalid payload"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/user/update-webhook [patch]
+func (s *Service) UpdateWebhook(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	var payload schema.UpdateWebhook
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Failed to parse the payload."})
+		return
+	}
+	if !model.IsValidUUID(payload.ID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid webhook id."})
+		return
+	}
+
+	hook, ok := s.userWebhook(ctx, payload.ID, uid)
+	if !ok {
+		return
+	}
+
+	fields := make(map[string]any)
+	if payload.URL != nil {
+		if err := core.CheckWebhookURL(ctx, *payload.URL); err != nil {
+			cm := err.(*core.CustomMessage)
+			ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+			return
+		}
+		fields["url"] = *payload.URL
+	}
+	if payload.Events != nil {
+		events, ok := webhookEvents(payload.Events, hook.ProjectID != nil)
+		if !ok {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Invalid webhook event, project webhooks only get project events."})
+			return
+		}
+		fields["events"] = events
+	}
+	if payload.Active != nil && *payload.Active != hook.Active {
+		fields["active"] = *payload.Active
+		if *payload.Active {
+			fields["failures"], fields["disabled_at"] = 0, nil
+		} else {
+			fields["disabled_at"] = time.Now()
+		}
+	}
+
+	if len(fields) > 0 {
+		if err := s.DB.WithContext(ctx).UpdateWebhook(hook, fields); err != nil {
+			cm := err.(*core.CustomMessage)
+			ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+			return
+		}
+		if err := s.DB.WithContext(ctx).FetchWebhook(hook.ID, hook); err != nil {
+			cm := err.(*core.CustomMessage)
+			ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"webhook": webhookView(hook)})
+}
+
+// DeleteWebhook godoc
+// @Summary    Delete a webhook
+// @Description An endpoint for deleting a webhook of the current user along with its delivery log
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param id query string true "Webhook ID"
+// @Security BearerAuth
+// @Success 204 {object} nil "Webhook deleted"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/user/delete-webhook [delete]
+func (s *Service) DeleteWebhook(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	id := ctx.Query("id")
+	if !model.IsValidUUID(id) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid webhook id."})
+		return
+	}
+
+	hook, ok := s.userWebhook(ctx, id, uid)
+	if !ok {
+		return
+	}
+
+	if err := s.DB.WithContext(ctx).DeleteWebhook(hook); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// ViewWebhookDeliveries godoc
+// @Summary    View the delivery log of a webhook
+// @Description An endpoint to view the latest deliveries of a webhook of the current user, newest first, with their status, attempts and the response code of the last attempt
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param id query string true "Webhook ID"
+// @Param limit query int false "Number of deliveries, 20 by default and 100 at most"
+// @Security BearerAuth
+// @Success 200 {object} schema.DocWebhookDeliveries "Deliveries"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/user/view-webhook-deliveries [get]
+func (s *Service) ViewWebhookDeliveries(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	id := ctx.Query("id")
+	if !model.IsValidUUID(id) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid webhook id."})
+		return
+	}
+
+	limit, ok := pageLimit(ctx, 20, 100)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid limit."})
+		return
+	}
+
+	if _, ok := s.userWebhook(ctx, id, uid); !ok {
+		return
+	}
+
+	var deliveries []model.WebhookDelivery
+	if err := s.DB.WithContext(ctx).FetchWebhookDeliveries(id, limit, &deliveries); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	views := make([]schema.ViewWebhookDelivery, len(deliveries))
+	for i := range deliveries {
+		views[i] = webhookDeliveryView(&deliveries[i])
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"deliveries": views})
+}
+
+// RedeliverWebhook godoc
+// @Summary    Redeliver a webhook event
+// @Description An endpoint for sending the event of a delivery again to its webhook, as a new delivery sharing the event id
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param id query string true "Delivery ID"
+// @Security BearerAuth
+// @Success 202 {object} schema.DocWebhookDelivery "Redelivery queued"
+// @Failure 400 {object} schema.DocNormalResponse "Invalid id"
+// @Failure 401 {object} schema.DocNormalResponse "Unauthorized"
+// @Failure 404 {object} schema.DocNormalResponse "Record not found"
+// @Failure 409 {object} schema.DocNormalResponse "Webhook disabled"
+// @Failure 500 {object} schema.DocNormalResponse "Server error"
+// @Router /api/user/redeliver-webhook [post]
+func (s *Service) RedeliverWebhook(ctx *gin.Context) {
+	uid, tp := ctx.GetString("userID"), ctx.GetString("purpose")
+	if !model.IsValidUUID(uid) || tp != "login" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized user."})
+		return
+	}
+
+	id := ctx.Query("id")
+	if !model.IsValidUUID(id) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid delivery id."})
+		return
+	}
+
+	var delivery model.WebhookDelivery
+	if err := s.DB.WithContext(ctx).FetchWebhookDelivery(id, &delivery); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+	// The deliveries of other users aren't disclosed
+	if delivery.Webhook == nil || delivery.Webhook.UserID != uid {
+		ctx.JSON(http.StatusNotFound, gin.H{"msg": "Delivery not found."})
+		return
+	}
+
+	redelivery, err := s.Hooks.Redeliver(ctx, &delivery)
+	if err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"delivery": webhookDeliveryView(redelivery)})
+}
+
+// userWebhook -> Fetches a webhook of the user, answering 404 for missing webhooks and those of other users
+func (s *Service) userWebhook(ctx *gin.Context, id, uid string) (*model.Webhook, bool) {
+	var hook model.Webhook
+	if err := s.DB.WithContext(ctx).FetchWebhook(id, &hook); err != nil {
+		cm := err.(*core.CustomMessage)
+		ctx.JSON(cm.Code, gin.H{"msg": cm.Message})
+		return nil, false
+	}
+	if hook.UserID != uid {
+		ctx.JSON(http.StatusNotFound, gin.H{"msg": "Webhook not found."})
+		return nil, false
+	}
+	return &hook, true
+}
+
+// webhookEvents -> Returns the events without duplicates, false when one of them can't be subscribed to
+func webhookEvents(events []string, project bool) ([]string, bool) {
+	if len(events) == 0 {
+		return nil, false
+	}
+
+	var unique []string
+	for _, event := range events {
+		if !model.IsValidWebhookEvent(event, project) {
+			return nil, false
+		}
+		if !slices.Contains(unique, event) {
+			unique = append(unique, event)
+		}
+	}
+	return unique, true
+}
+
+func webhookView(hook *model.Webhook) schema.ViewWebhook {
+	return schema.ViewWebhook{
+		ID:         hook.ID,
+		URL:        hook.URL,
+		ProjectID:  hook.ProjectID,
+		Events:     hook.Events,
+		Active:     hook.Active,
+		Failures:   hook.Failures,
+		DisabledAt: hook.DisabledAt,
+		CreatedAt:  hook.CreatedAt,
+	}
+}
+
+func webhookDeliveryView(delivery *model.WebhookDelivery) schema.ViewWebhookDelivery {
+	return schema.ViewWebhookDelivery{
+		ID:           delivery.ID,
+		EventID:      delivery.EventID,
+		Event:        delivery.Event,
+		Status:       delivery.Status,
+		Attempts:     delivery.Attempts,
+		StatusCode:   delivery.StatusCode,
+		Error:        delivery.Error,
+		RedeliveryOf: delivery.RedeliveryOf,
+		CreatedAt:    delivery.CreatedAt,
+		DeliveredAt:  delivery.DeliveredAt,
+	}
+}
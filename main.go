@@ -8,9 +8,12 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"findme/core"
@@ -21,6 +24,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/robfig/cron/v3"
 
 	swagFiles "github.com/swaggo/files"
@@ -28,17 +33,43 @@ import (
 )
 
 func main() {
-	log.SetPrefix("[FindMe]")
-	log.SetFlags(log.Lshortfile)
+	// `findme email-preview [template] [html|text]` renders an email with its sample data and exits
+	if len(os.Args) > 1 && os.Args[1] == "email-preview" {
+		previewEmail(os.Args[2:])
+		return
+	}
 
 	err := godotenv.Load()
+	core.SetupLogging(os.Stdout)
+	if err != nil {
+		slog.Warn("Error loading .env file, ignore if in production", "err", err)
+	}
+
+	// Tracing is exported over OTLP when OTEL_EXPORTER_OTLP_ENDPOINT is set
+	shutdownTracing, err := core.SetupTracing(context.Background(), "findme")
 	if err != nil {
-		log.Println("[WARNING] Error loading .env file ->", err, "Ignore if in production")
+		fatal("Failed to set up tracing", err)
 	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Warn("Failed to flush the pending spans", "err", err)
+		}
+	}()
 
 	// Setup db, redis and cron
 	dbClient := database.Connect()
+	if err := dbClient.Use(core.GormMetrics{}); err != nil {
+		slog.Warn("Failed to register the db metrics plugin", "err", err)
+	}
+	if err := dbClient.Use(core.GormTracing{}); err != nil {
+		slog.Warn("Failed to register the db tracing plugin", "err", err)
+	}
 	rdbClient := database.ConnectRedis()
+	if err := redisotel.InstrumentTracing(rdbClient); err != nil {
+		slog.Warn("Failed to instrument the redis client", "err", err)
+	}
 	db := core.NewGormDB(dbClient)
 	rdb := core.NewRDB(rdbClient)
 	cron := cron.New()
@@ -47,30 +78,67 @@ func main() {
 	client := &http.Client{Timeout: 10 * time.Minute}
 	email := core.NewEmailService("smtp.gmail.com", os.Getenv("EMAIL"), os.Getenv("EMAIL_APP_PASSWORD"), 587)
 
-	chathub := core.NewChatHub(1000)
+	// shared gRPC connections to the ML services
+	embConn, err := core.NewGRPCConn("Embedding", core.GRPCConfigFromEnv("EMB", "emb:8000"))
+	if err != nil {
+		fatal("Failed to set up the embedding service connection", err)
+	}
+	defer embConn.Close()
+
+	recConn, err := core.NewGRPCConn("Recommendation", core.GRPCConfigFromEnv("REC", "rec:8050"))
+	if err != nil {
+		fatal("Failed to set up the recommendation service connection", err)
+	}
+	defer recConn.Close()
+
+	chathub := core.NewChatHub(db, rdb, 1000)
 	emailHub := core.NewEmailHub(2000, 5, email)
-	embHub := core.NewEmbeddingHub(100, 10, "emb:8000")
-	recHub := core.NewRecommendationHub(10, 100, "rec:8050")
+	emailHub.DB = db
+	emailHub.AppURL = handlers.AppURL
+	chathub.Email = emailHub
+	webhookHub := core.NewWebhookHub(500, 5, db, core.NewWebhookClient(15*time.Second))
+	chathub.Webhooks = webhookHub
+	embHub := core.NewEmbeddingHub(100, 10, embConn)
+	recHub := core.NewRecommendationHub(10, 100, recConn)
+
+	notifier := core.NewNotificationService(db, chathub)
 
 	worker := core.NewCron(db, emailHub, cron)
+	worker.AppURL = handlers.AppURL
+	worker.Notify = notifier
+	worker.Presence = chathub.Presence
+
+	// Chat attachments, on the local disk unless STORAGE_DRIVER=s3
+	storage, err := core.StorageFromEnv()
+	if err != nil {
+		fatal("Failed to set up the attachment storage", err)
+	}
 
 	// set up git and transc service
 	git := handlers.NewGitService(os.Getenv("GIT_CLIENT_ID"), os.Getenv("GIT_CLIENT_SECRET"), os.Getenv("GIT_CALLBACK_URL"), db, embHub, client)
-	transc := handlers.NewTranscService(db, rdb, emailHub, os.Getenv("PAYSTACK_API_KEY"), client)
-	service := handlers.NewService(db, rdb, emailHub, git, transc, embHub, recHub, client, chathub, worker)
+	transc := handlers.NewTranscService(db, rdb, emailHub, notifier, os.Getenv("PAYSTACK_API_KEY"), client)
+	service := handlers.NewService(db, rdb, emailHub, git, transc, embHub, recHub, client, chathub, worker, storage, notifier, webhookHub)
+
+	core.RegisterHubMetrics("chat", chathub.Stats)
+	core.RegisterHubMetrics("email", emailHub.Stats)
+	core.RegisterHubMetrics("embedding", embHub.Stats)
+	core.RegisterHubMetrics("recommendation", recHub.Stats)
+	core.RegisterHubMetrics("webhook", webhookHub.Stats)
 
 	go chathub.Run()
 	go embHub.Run()
 	go emailHub.Run()
 	go recHub.Run()
+	go webhookHub.Run()
 	cron.Start()
 
 	var skills []model.Skill
 	if err := service.DB.FetchAllSkills(&skills); err != nil {
-		log.Fatalln("Failed to Fetch skills from DB exiting...")
+		fatal("Failed to fetch skills from DB exiting...", err)
 	}
 	service.RDB.CacheSkills(skills)
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.Recovery())
 
 	// Swagger UI
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swagFiles.Handler))
@@ -78,13 +146,51 @@ func main() {
 	// Cron Worker
 	err = service.Cron.TrialEndingReminders()
 	if err != nil {
-		log.Println("[CRON] An error occured err -> ", err.Error())
+		slog.Error("Failed to schedule the trial ending reminders", "component", "cron", "err", err)
+	}
+	if err := service.Cron.MessageDigests(); err != nil {
+		slog.Error("Failed to schedule the message digests", "component", "cron", "err", err)
 	}
 
 	handlers.SetupHandler(router, service)
 
+	// Metrics and the log level on an internal only address (e.g. 127.0.0.1:9090 or the docker network) without a token
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		mux.Handle("/log-level", core.LogLevelHandler())
+		go func() {
+			if err := http.ListenAndServe(addr, mux); err != nil {
+				slog.Error("The internal listener stopped", "component", "metrics", "err", err)
+			}
+		}()
+	}
+
 	err = router.Run("0.0.0.0:8080")
 	if err != nil {
-		log.Fatalln("Failed to start app -> ", err.Error())
+		fatal("Failed to start app", err)
+	}
+}
+
+// previewEmail -> Prints an email template rendered with its sample data, or the template names when none is given
+func previewEmail(args []string) {
+	if len(args) == 0 {
+		fmt.Println(strings.Join(core.EmailTemplates(), "\n"))
+		return
+	}
+
+	format := "html"
+	if len(args) > 1 {
+		format = args[1]
 	}
+	if err := core.PreviewEmail(os.Stdout, args[0], format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// fatal -> Logs the error that stops the app from starting and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
 }
@@ -3,6 +3,8 @@ package model
 
 import (
 	"math/rand"
+	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -22,9 +24,97 @@ type UserMessage struct {
 	ChatID  string `gorm:"not null"`
 	FromID  string `gorm:"not null"`
 	Message string `gorm:"not null"`
+	// The quoted message, replies to a message form its thread
+	ReplyToID *string `gorm:"index"`
+	// Set on edits, the previous texts are kept as revisions
+	EditedAt *time.Time
+	// Recorded by the app on membership and name changes of a group, FromID is the member who made the change
+	System bool `gorm:"not null;default:false"`
+	// Tombstone of a message deleted for everyone, by its sender or the group owner
+	RemovedAt   *time.Time
+	RemovedByID *string
 
 	// Relations:
-	FromUser *User `gorm:"foreignKey:FromID"`
+	FromUser    *User              `gorm:"foreignKey:FromID"`
+	ReplyTo     *UserMessage       `gorm:"foreignKey:ReplyToID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	Reactions   []*MessageReaction `gorm:"foreignKey:MsgID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Attachments []*Attachment      `gorm:"foreignKey:MsgID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Revisions   []*MessageRevision `gorm:"foreignKey:MsgID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// IsRemoved -> Whether the message was deleted for everyone
+func (u *UserMessage) IsRemoved() bool {
+	return u.RemovedAt != nil
+}
+
+// MessageRevision -> A previous text of a message, kept on each edit and when the message is deleted for everyone
+type MessageRevision struct {
+	GormModel
+	MsgID    string `gorm:"not null;index"`
+	EditorID string `gorm:"not null"`
+	Message  string `gorm:"not null"`
+}
+
+// MessageMention -> A member of a group mentioned with @username in a message
+type MessageMention struct {
+	MsgID     string `gorm:"primaryKey"`
+	UserID    string `gorm:"primaryKey;index"`
+	ChatID    string `gorm:"not null;index"`
+	CreatedAt time.Time
+
+	Msg  *UserMessage `gorm:"foreignKey:MsgID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User *User        `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// HiddenMessage -> A message a member deleted for themselves only
+type HiddenMessage struct {
+	MsgID     string `gorm:"primaryKey"`
+	UserID    string `gorm:"primaryKey"`
+	CreatedAt time.Time
+
+	Msg  *UserMessage `gorm:"foreignKey:MsgID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User *User        `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Attachment -> A file shared in a chat, uploaded first and then sent along a message (MsgID is nil until then)
+type Attachment struct {
+	GormModel
+	ChatID      string  `gorm:"not null;index"`
+	UploaderID  string  `gorm:"not null"`
+	MsgID       *string `gorm:"index"`
+	Name        string  `gorm:"not null"`
+	ContentType string  `gorm:"not null"`
+	Size        int64   `gorm:"not null"`
+	Key         string  `gorm:"not null"`
+	ThumbKey    *string
+	Width       int
+	Height      int
+
+	Chat *Chat `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// MessageReaction -> An emoji reaction to a message, a user reacts at most once with each emoji
+type MessageReaction struct {
+	MsgID     string `gorm:"primaryKey"`
+	UserID    string `gorm:"primaryKey"`
+	Emoji     string `gorm:"primaryKey;size:32"`
+	CreatedAt time.Time
+
+	User *User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// ReactionCount -> Number of reactions with an emoji to a message, Mine when the user asking reacted with it (not a table)
+type ReactionCount struct {
+	MsgID string
+	Emoji string
+	Count int64
+	Mine  bool
+}
+
+// ReplyCount -> Number of replies to a message (not a table)
+type ReplyCount struct {
+	MsgID string
+	Count int64
 }
 
 type Chat struct {
@@ -36,16 +126,192 @@ type Chat struct {
 	Group   bool `gorm:"not null"`
 	OwnerID *string
 	Owner   *User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// Users joining through an invite link wait for an admin to approve them
+	JoinApproval bool `gorm:"not null;default:false"`
+	PinnedMsgID  *string
+	PinnedMsg    *UserMessage `gorm:"foreignKey:PinnedMsgID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+}
+
+// Roles of the group chat members, the owner (Chat.OwnerID) is the only one with the owner role
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// IsAdminRole -> Whether a role can manage the group (rename, members, invites, pins)
+func IsAdminRole(role string) bool {
+	return role == RoleOwner || role == RoleAdmin
+}
+
+// Notification levels of a chat member
+const (
+	NotifyAll      = "all"
+	NotifyMentions = "mentions"
+	NotifyNone     = "none"
+)
+
+// MaxPinnedChats -> Number of chats a user can pin at the top of their chat list
+const MaxPinnedChats = 5
+
+// ShouldNotify -> Whether the member gets an email or push notification of a new message, with the mentions level
+// only the messages mentioning them get through. A muted chat notifies of nothing until the mute ends
+func (c *ChatUser) ShouldNotify(mentioned bool, now time.Time) bool {
+	if c.MutedUntil != nil && now.Before(*c.MutedUntil) {
+		return false
+	}
+	switch c.NotifyLevel {
+	case NotifyNone:
+		return false
+	case NotifyMentions:
+		return mentioned
+	}
+	return true
+}
+
+// ChatInvite -> A shareable link to join a group chat, it stops working once expired, revoked or used up
+type ChatInvite struct {
+	GormModel
+	ChatID    string `gorm:"not null;index"`
+	CreatorID string `gorm:"not null"`
+	Token     string `gorm:"not null;uniqueIndex"`
+	ExpiresAt *time.Time
+	// 0 for no limit
+	MaxUses   int `gorm:"not null;default:0"`
+	Uses      int `gorm:"not null;default:0"`
+	RevokedAt *time.Time
+
+	Chat    *Chat `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Creator *User `gorm:"foreignKey:CreatorID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// IsUsable -> Whether the invite can still be used to join
+func (i *ChatInvite) IsUsable(now time.Time) bool {
+	return i.RevokedAt == nil && (i.ExpiresAt == nil || now.Before(*i.ExpiresAt)) && (i.MaxUses == 0 || i.Uses < i.MaxUses)
+}
+
+// ChatJoinRequest -> A user that joined through an invite of a group needing approval
+type ChatJoinRequest struct {
+	ChatID    string `gorm:"primaryKey"`
+	UserID    string `gorm:"primaryKey"`
+	InviteID  string `gorm:"not null"`
+	CreatedAt time.Time
+
+	Chat *Chat `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User *User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
 
 type ChatUser struct {
 	UserID string `gorm:"primaryKey"`
 	ChatID string `gorm:"primaryKey"`
 
+	// Read cursor, the last message read by the member and when it was sent
+	LastReadID *string
+	LastReadAt *time.Time
+
+	Role string `gorm:"not null;default:member"`
+
+	// Preferences of the member, a muted chat or one with the none level sends no email or push notifications
+	MutedUntil  *time.Time
+	Archived    bool   `gorm:"not null;default:false"`
+	PinPosition *int   `gorm:"index"`
+	NotifyLevel string `gorm:"not null;default:all"`
+
 	User *User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	Chat *Chat `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
 
+// PendingMessage -> A message a member hasn't read since they were last online, along with their digest settings and
+// their preferences for the chat, read with a single query (not a table)
+type PendingMessage struct {
+	UserID           string
+	Username         string
+	Email            string
+	DigestFrequency  string
+	DigestSentAt     *time.Time
+	UnsubscribeToken *string
+	// The digest channel of the messages and mentions categories
+	MessagesDigest bool
+	MentionsDigest bool
+
+	ChatID      string
+	ChatName    string
+	IsGroup     bool
+	MutedUntil  *time.Time
+	NotifyLevel string
+
+	MsgID     string
+	Message   string
+	FromName  string
+	CreatedAt time.Time
+	Mentioned bool
+}
+
+// ChatSummary -> A chat of the chat list with its last message, unread count and the other member of a direct chat,
+// read with a single query (not a table)
+type ChatSummary struct {
+	ChatID     string
+	Name       string
+	IsGroup    bool
+	LastReadAt *time.Time
+	Unread     int64
+	// Unread messages mentioning the user
+	UnreadMentions int64
+
+	MutedUntil  *time.Time
+	Archived    bool
+	PinPosition *int
+	NotifyLevel string
+
+	LastMsgID     *string
+	LastMsg       *string
+	LastFromID    *string
+	LastFromName  *string
+	LastSent      *time.Time
+	LastEdited    *time.Time
+	LastEditedAt  *time.Time
+	LastRemovedAt *time.Time
+	PeerID        *string
+	PeerName      *string
+	PeerLastSeen  *time.Time
+	PeerHidden    *bool
+}
+
+// MessageSearchConfig -> Text search configuration of the message search vectors, no stemming as chats mix languages
+const MessageSearchConfig = "simple"
+
+// MessageHit -> A message matching a search, with the matched terms highlighted (not a table)
+type MessageHit struct {
+	ID        string
+	ChatID    string
+	FromID    string
+	FromName  string
+	Message   string
+	Highlight string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// IsValidEmoji -> Checks that a reaction is a single emoji (with its modifiers, flags and zero width joined sequences)
+func IsValidEmoji(emoji string) bool {
+	if emoji == "" || len(emoji) > 32 {
+		return false
+	}
+
+	symbol := false
+	for _, r := range emoji {
+		switch {
+		case unicode.Is(unicode.So, r):
+			symbol = true
+		case unicode.Is(unicode.Sk, r), unicode.Is(unicode.Mn, r), r == '\u200d':
+		default:
+			return false
+		}
+	}
+	return symbol
+}
+
 func (c *Chat) BeforeCreate(tx *gorm.DB) (err error) {
 	if c.ID == "" {
 		c.ID = uuid.NewString()
@@ -59,6 +325,27 @@ func (c *Chat) BeforeCreate(tx *gorm.DB) (err error) {
 	return err
 }
 
+func (a *Attachment) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == "" {
+		a.ID = uuid.NewString()
+	}
+	return err
+}
+
+func (i *ChatInvite) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.ID == "" {
+		i.ID = uuid.NewString()
+	}
+	return err
+}
+
+func (r *MessageRevision) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == "" {
+		r.ID = uuid.NewString()
+	}
+	return err
+}
+
 func (u *UserMessage) BeforeCreate(tx *gorm.DB) (err error) {
 	if u.ID == "" {
 		u.ID = uuid.NewString()
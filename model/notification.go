@@ -0,0 +1,135 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Notification -> An entry of the in-app notification center of a user
+type Notification struct {
+	GormModel
+	UserID string `gorm:"not null;index:idx_notification_user,priority:1"`
+	Type   string `gorm:"not null"`
+	Title  string `gorm:"not null"`
+	Body   string `gorm:"not null"`
+	// The friend request, project application or subscription the notification is about
+	RefID  *string
+	ReadAt *time.Time
+
+	User *User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Notification types
+const (
+	NotifyFriendRequest       = "friend.request"
+	NotifyApplicationReceived = "application.received"
+	NotifyApplicationAccepted = "application.accepted"
+	NotifyApplicationRejected = "application.rejected"
+	NotifySubscriptionCreated = "subscription.created"
+	NotifyPaymentFailed       = "subscription.payment_failed"
+	NotifySubscriptionEnded   = "subscription.cancelled"
+	NotifyTrialEnding         = "trial.ending"
+)
+
+// NotificationPreference -> The channels a user gets the notifications of a category on,
+// a category without a row has all of its channels on
+type NotificationPreference struct {
+	UserID    string `gorm:"primaryKey"`
+	Category  string `gorm:"primaryKey"`
+	Email     bool   `gorm:"not null"`
+	InApp     bool   `gorm:"not null"`
+	Digest    bool   `gorm:"not null"`
+	UpdatedAt time.Time
+
+	User *User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Notification categories, transactional mail (password resets, payment failures, requested exports) has none
+// and always goes out
+const (
+	CategoryFriendRequests = "friend_requests"
+	CategoryApplications   = "applications"
+	CategorySubscription   = "subscription"
+	CategoryTrial          = "trial"
+	CategoryMentions       = "mentions"
+	CategoryMessages       = "messages"
+)
+
+// Notification channels
+const (
+	ChannelEmail  = "email"
+	ChannelInApp  = "in_app"
+	ChannelDigest = "digest"
+)
+
+// NotifyCategories -> Every category in the order they are listed to the users
+var NotifyCategories = []string{CategoryFriendRequests, CategoryApplications, CategorySubscription, CategoryTrial, CategoryMentions, CategoryMessages}
+
+// categoryChannels -> The channels each category is delivered on, only those can be turned off
+var categoryChannels = map[string][]string{
+	CategoryFriendRequests: {ChannelEmail, ChannelInApp},
+	CategoryApplications:   {ChannelEmail, ChannelInApp},
+	CategorySubscription:   {ChannelEmail, ChannelInApp},
+	CategoryTrial:          {ChannelEmail, ChannelInApp},
+	CategoryMentions:       {ChannelEmail, ChannelDigest},
+	CategoryMessages:       {ChannelDigest},
+}
+
+// CategoryChannels -> Returns the channels of a category, none for an unknown one
+func CategoryChannels(category string) []string {
+	return categoryChannels[category]
+}
+
+// HasChannel -> Whether a category is delivered on the channel
+func HasChannel(category, channel string) bool {
+	return slices.Contains(categoryChannels[category], channel)
+}
+
+// NotifyCategory -> Returns the category of a notification type, empty for the transactional ones
+func NotifyCategory(notifyType string) string {
+	switch notifyType {
+	case NotifyFriendRequest:
+		return CategoryFriendRequests
+	case NotifyApplicationReceived, NotifyApplicationAccepted, NotifyApplicationRejected:
+		return CategoryApplications
+	case NotifySubscriptionCreated, NotifySubscriptionEnded:
+		return CategorySubscription
+	case NotifyTrialEnding:
+		return CategoryTrial
+	default:
+		return ""
+	}
+}
+
+// DefaultPreference -> The preference of a category the user never changed, every channel on
+func DefaultPreference(uid, category string) NotificationPreference {
+	return NotificationPreference{UserID: uid, Category: category, Email: true, InApp: true, Digest: true}
+}
+
+// Allows -> Whether the notifications go out on the channel, channels the category isn't delivered on are always off
+func (p *NotificationPreference) Allows(channel string) bool {
+	if !HasChannel(p.Category, channel) {
+		return false
+	}
+	switch channel {
+	case ChannelEmail:
+		return p.Email
+	case ChannelInApp:
+		return p.InApp
+	case ChannelDigest:
+		return p.Digest
+	default:
+		return false
+	}
+}
+
+func (n *Notification) BeforeCreate(tx *gorm.DB) (err error) {
+	if n.ID == "" {
+		n.ID = uuid.NewString()
+	}
+
+	return err
+}
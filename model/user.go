@@ -22,6 +22,15 @@ type User struct {
 	GitUser      bool           `gorm:"column:gituser"`
 	Availability bool
 
+	// Presence
+	LastSeen     *time.Time
+	HidePresence bool
+
+	// Email digests of the messages received while offline, sent up to DigestSentAt
+	DigestFrequency  string `gorm:"not null;default:instant"`
+	DigestSentAt     *time.Time
+	UnsubscribeToken *string `gorm:"uniqueIndex"`
+
 	// Sub Details
 	FreeTrial       time.Time `gorm:"column:freetrial"`
 	TrialReminder   bool      `gorm:"column:trialreminder"`
@@ -55,6 +64,23 @@ type User struct {
 	Transactions   []*Transactions  `gorm:"foreignKey:UserID"`
 }
 
+// Frequencies of the offline message digests, instant sends one as soon as a message stays unread for a while
+const (
+	DigestInstant = "instant"
+	DigestHourly  = "hourly"
+	DigestDaily   = "daily"
+	DigestOff     = "off"
+)
+
+// IsValidDigestFrequency -> Checks that the frequency is one of the digest frequencies
+func IsValidDigestFrequency(frequency string) bool {
+	switch frequency {
+	case DigestInstant, DigestHourly, DigestDaily, DigestOff:
+		return true
+	}
+	return false
+}
+
 type UserFriend struct {
 	UserID   string `gorm:"primaryKey"`
 	FriendID string `gorm:"primaryKey"`
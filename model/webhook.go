@@ -0,0 +1,98 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Webhook -> An endpoint of a user, or of one of their projects, receiving the events it subscribed to
+type Webhook struct {
+	GormModel
+	UserID string `gorm:"not null;index"`
+	// Set for the webhooks of a project, which only get the events of that project
+	ProjectID *string        `gorm:"index"`
+	URL       string         `gorm:"not null"`
+	Secret    string         `gorm:"not null"`
+	Events    pq.StringArray `gorm:"type:text[]"`
+	Active    bool           `gorm:"not null;default:true"`
+	// Deliveries failed in a row (after their retries), the webhook is disabled once it reaches WebhookMaxFailures
+	Failures   int `gorm:"not null;default:0"`
+	DisabledAt *time.Time
+
+	User    *User    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Project *Project `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// WebhookDelivery -> An event sent to a webhook, with the outcome of its last attempt
+type WebhookDelivery struct {
+	GormModel
+	WebhookID string `gorm:"not null;index"`
+	// Shared by the deliveries (and redeliveries) of the same event, so receivers can drop duplicates
+	EventID string `gorm:"not null"`
+	Event   string `gorm:"not null"`
+	Payload string `gorm:"not null"`
+	// The delivery redelivered by hand
+	RedeliveryOf *string
+	Attempts     int `gorm:"not null;default:0"`
+	// Response code of the last attempt, 0 when the endpoint couldn't be reached
+	StatusCode  int
+	Error       string
+	Status      string `gorm:"not null;default:'pending'"`
+	DeliveredAt *time.Time
+	// When a pending delivery is tried next, a queued one is leased until then so the other pollers leave it alone
+	NextAttemptAt *time.Time `gorm:"index"`
+
+	Webhook *Webhook `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Webhook events
+const (
+	WebhookApplicationReceived = "application.received"
+	WebhookApplicationAccepted = "application.accepted"
+	WebhookProjectUpdated      = "project.updated"
+	WebhookMessageMention      = "message.mention"
+)
+
+const (
+	// WebhookMaxFailures -> Failed deliveries in a row that disable a webhook
+	WebhookMaxFailures = 5
+
+	// MaxWebhooks -> Webhooks a user can have, those of their projects included
+	MaxWebhooks = 10
+)
+
+// WebhookEvents -> Every event a webhook can subscribe to
+var WebhookEvents = []string{WebhookApplicationReceived, WebhookApplicationAccepted, WebhookProjectUpdated, WebhookMessageMention}
+
+// IsValidWebhookEvent -> Whether a webhook can subscribe to the event, the webhooks of a project only get project events
+func IsValidWebhookEvent(event string, project bool) bool {
+	if project && event == WebhookMessageMention {
+		return false
+	}
+	return slices.Contains(WebhookEvents, event)
+}
+
+// Subscribed -> Whether the webhook gets the event
+func (w *Webhook) Subscribed(event string) bool {
+	return w.Active && slices.Contains(w.Events, event)
+}
+
+func (w *Webhook) BeforeCreate(tx *gorm.DB) (err error) {
+	if w.ID == "" {
+		w.ID = uuid.NewString()
+	}
+
+	return err
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.ID == "" {
+		d.ID = uuid.NewString()
+	}
+
+	return err
+}
@@ -83,10 +83,77 @@ type DocViewChatHistory struct {
 	Msg ViewChat `json:"msg"`
 }
 
+type DocAttachment struct {
+	Attachment ViewAttachment `json:"attachment"`
+}
+
+type DocViewThread struct {
+	Msg ViewThread `json:"msg"`
+}
+
+type DocViewEdits struct {
+	Msg ViewEdits `json:"msg"`
+}
+
+// DocExportChat -> Shape of a json transcript
+type DocExportChat struct {
+	ExportHeader
+	Messages []ExportMessage `json:"messages"`
+}
+
+type DocViewMembers struct {
+	Msg []ViewMember `json:"msg"`
+}
+
+type DocViewInvite struct {
+	Msg ViewInvite `json:"msg"`
+}
+
+type DocViewInvites struct {
+	Msg []ViewInvite `json:"msg"`
+}
+
+type DocJoinChat struct {
+	Msg    string `json:"msg"`
+	ChatID string `json:"chat_id"`
+}
+
+type DocViewJoinRequests struct {
+	Msg []ViewJoinRequest `json:"msg"`
+}
+
+type DocSearchMessages struct {
+	Msg []SearchMessage `json:"msg"`
+}
+
 type DocViewAllChats struct {
 	Msg []ViewChat `json:"msg"`
 }
 
+type DocUnreadNotifications struct {
+	Unread int64 `json:"unread"`
+}
+
+type DocNotificationPreferences struct {
+	Preferences []ViewNotificationPreference `json:"preferences"`
+}
+
+type DocWebhook struct {
+	Webhook ViewWebhook `json:"webhook"`
+}
+
+type DocViewWebhooks struct {
+	Webhooks []ViewWebhook `json:"webhooks"`
+}
+
+type DocWebhookDelivery struct {
+	Delivery ViewWebhookDelivery `json:"delivery"`
+}
+
+type DocWebhookDeliveries struct {
+	Deliveries []ViewWebhookDelivery `json:"deliveries"`
+}
+
 type DocViewRepos struct {
 	Repos []ViewRepo `json:"repos"`
 }
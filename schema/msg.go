@@ -4,8 +4,13 @@ package schema
 import "time"
 
 type NewMessage struct {
-	Message string `json:"msg" binding:"required"`
+	// The text can only be left empty when files are attached
+	Message string `json:"msg"`
 	ChatID  string `json:"chat_id" binding:"required"`
+	// The message replied to, in the same chat
+	ReplyToID string `json:"reply_to"`
+	// Ids of the files uploaded to the chat to send along
+	Attachments []string `json:"attachments" binding:"max=10"`
 }
 
 type EditMessage struct {
@@ -14,11 +19,77 @@ type EditMessage struct {
 }
 
 type ViewMessage struct {
-	ID      string    `json:"id"`
-	Message string    `json:"msg"`
-	UserID  string    `json:"uid"`
-	Sent    time.Time `json:"sent"`
-	Edited  time.Time `json:"edited"`
+	ID       string    `json:"id"`
+	Message  string    `json:"msg"`
+	UserID   string    `json:"uid"`
+	Username string    `json:"username,omitempty"`
+	Sent     time.Time `json:"sent"`
+	Edited   time.Time `json:"edited"`
+	IsEdited bool      `json:"is_edited,omitempty"`
+	// Recorded by the app on membership and name changes, uid is the member who made the change
+	System bool `json:"system,omitempty"`
+	// Deleted for everyone, only the tombstone is left
+	Deleted bool `json:"deleted,omitempty"`
+
+	// Members of a group chat that have read the message
+	SeenBy []string `json:"seen_by,omitempty"`
+
+	ReplyToID   *string          `json:"reply_to,omitempty"`
+	Replies     int64            `json:"replies,omitempty"`
+	Reactions   []ReactionCount  `json:"reactions,omitempty"`
+	Attachments []ViewAttachment `json:"attachments,omitempty"`
+
+	// Members mentioned with @username, mentioned is set when the current user is one of them
+	Mentions  []ViewMention `json:"mentions,omitempty"`
+	Mentioned bool          `json:"mentioned,omitempty"`
+}
+
+type ViewMention struct {
+	UserID   string `json:"uid"`
+	Username string `json:"username"`
+}
+
+// ViewAttachment -> A file sent in a chat, the urls are signed for the current user and expire (left out of the socket events)
+type ViewAttachment struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	ContentType string `json:"type"`
+	Size        int64  `json:"size"`
+	Width       int    `json:"width,omitempty"`
+	Height      int    `json:"height,omitempty"`
+	URL         string `json:"url,omitempty"`
+	ThumbURL    string `json:"thumb_url,omitempty"`
+}
+
+// ReactionCount -> Number of reactions with an emoji, Me when the current user reacted with it
+type ReactionCount struct {
+	Emoji string `json:"emoji"`
+	Count int64  `json:"count"`
+	Me    bool   `json:"me"`
+}
+
+type React struct {
+	MsgID string `json:"msg_id" binding:"required"`
+	Emoji string `json:"emoji" binding:"required"`
+}
+
+// ViewThread -> A message and its replies, oldest first
+// ViewEdits -> Edit history of a message, the revisions are the previous texts, oldest first
+type ViewEdits struct {
+	ID        string         `json:"id"`
+	Message   string         `json:"msg"`
+	Revisions []ViewRevision `json:"revisions"`
+}
+
+type ViewRevision struct {
+	Message  string    `json:"msg"`
+	EditorID string    `json:"editor_id"`
+	Replaced time.Time `json:"replaced"`
+}
+
+type ViewThread struct {
+	Root    ViewMessage   `json:"root"`
+	Replies []ViewMessage `json:"replies"`
 }
 
 type ViewChat struct {
@@ -26,6 +97,119 @@ type ViewChat struct {
 	CID     string
 	Message []ViewMessage
 	Group   bool
+	Unread  int64
+	// Unread messages mentioning the current user
+	UnreadMentions int64 `json:",omitempty"`
+	Preview        string
+	// More messages are left past the page of the history
+	HasMore bool `json:",omitempty"`
+
+	// Presence of the other member of a direct chat
+	Presence string     `json:",omitempty"`
+	LastSeen *time.Time `json:",omitempty"`
+
+	// Preferences of the current user
+	MutedUntil  *time.Time `json:",omitempty"`
+	Archived    bool       `json:",omitempty"`
+	PinPosition *int       `json:",omitempty"`
+	NotifyLevel string     `json:",omitempty"`
+
+	// Role of the current user and settings of a group
+	Role         string       `json:",omitempty"`
+	JoinApproval bool         `json:",omitempty"`
+	Pinned       *ViewMessage `json:",omitempty"`
+}
+
+type MuteChat struct {
+	ChatID string `json:"chat_id" binding:"required"`
+	// End of the mute, the chat is unmuted when empty
+	Until *time.Time `json:"until"`
+}
+
+type ArchiveChat struct {
+	ChatID   string `json:"chat_id" binding:"required"`
+	Archived *bool  `json:"archived" binding:"required"`
+}
+
+type PinChat struct {
+	ChatID string `json:"chat_id" binding:"required"`
+	// Position among the pinned chats (lowest first), the chat is unpinned when empty
+	Position *int `json:"position" binding:"omitempty,min=0,max=100"`
+}
+
+type NotifyLevel struct {
+	ChatID string `json:"chat_id" binding:"required"`
+	Level  string `json:"level" binding:"required,oneof=all mentions none"`
+}
+
+type ViewMember struct {
+	UserID   string `json:"uid"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+type SetRole struct {
+	ChatID string `json:"chat_id" binding:"required"`
+	UserID string `json:"user_id" binding:"required"`
+	Role   string `json:"role" binding:"required,oneof=admin member"`
+}
+
+type PinMessage struct {
+	ChatID string `json:"chat_id" binding:"required"`
+	// The message to pin, the pinned message is removed when empty
+	MsgID string `json:"msg_id"`
+}
+
+type JoinApproval struct {
+	ChatID  string `json:"chat_id" binding:"required"`
+	Enabled *bool  `json:"enabled" binding:"required"`
+}
+
+type NewInvite struct {
+	ChatID string `json:"chat_id" binding:"required"`
+	// Lifetime of the link in hours, 0 for a link that doesn't expire
+	ExpiresIn int `json:"expires_in" binding:"min=0,max=720"`
+	// Number of users that can join with the link, 0 for no limit
+	MaxUses int `json:"max_uses" binding:"min=0,max=1000"`
+}
+
+type ViewInvite struct {
+	ID        string     `json:"id"`
+	Token     string     `json:"token"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	MaxUses   int        `json:"max_uses"`
+	Uses      int        `json:"uses"`
+	Created   time.Time  `json:"created"`
+}
+
+type ViewJoinRequest struct {
+	UserID    string    `json:"uid"`
+	Username  string    `json:"username"`
+	Requested time.Time `json:"requested"`
+}
+
+type ReviewJoin struct {
+	ChatID  string `json:"chat_id" binding:"required"`
+	UserID  string `json:"user_id" binding:"required"`
+	Approve *bool  `json:"approve" binding:"required"`
+}
+
+// SearchMessage -> A message matching a search, Highlight is the html escaped message with the matches in <mark> tags
+type SearchMessage struct {
+	ID        string    `json:"id"`
+	ChatID    string    `json:"chat_id"`
+	UserID    string    `json:"uid"`
+	Username  string    `json:"username"`
+	Message   string    `json:"msg"`
+	Highlight string    `json:"highlight"`
+	Sent      time.Time `json:"sent"`
+	Edited    time.Time `json:"edited"`
+}
+
+type MarkRead struct {
+	ChatID string `json:"chat_id" binding:"required"`
+	// The last read message, the latest message of the chat when empty
+	MsgID string `json:"msg_id"`
 }
 
 type AddUserChat struct {
@@ -37,3 +221,33 @@ type RenameChat struct {
 	ChatID string `json:"chat_id" binding:"required"`
 	Name   string `json:"name" binding:"required"`
 }
+
+// ExportChat -> Requests the transcript of a chat by email, as a download link
+type ExportChat struct {
+	ChatID string `json:"chat_id" binding:"required"`
+	Format string `json:"format" binding:"required,oneof=json markdown html"`
+}
+
+// ExportHeader -> Opens a json transcript, followed by the messages of the chat
+type ExportHeader struct {
+	ChatID   string    `json:"chat_id"`
+	Name     string    `json:"name"`
+	Group    bool      `json:"group"`
+	Exported time.Time `json:"exported_at"`
+	By       string    `json:"exported_by"`
+}
+
+// ExportMessage -> A message of a transcript, the attachment urls are signed for the user exporting the chat
+type ExportMessage struct {
+	ID          string           `json:"id"`
+	UserID      string           `json:"uid"`
+	Username    string           `json:"username"`
+	Message     string           `json:"msg"`
+	Sent        time.Time        `json:"sent"`
+	EditedAt    *time.Time       `json:"edited_at,omitempty"`
+	System      bool             `json:"system,omitempty"`
+	Deleted     bool             `json:"deleted,omitempty"`
+	ReplyToID   *string          `json:"reply_to,omitempty"`
+	Revisions   []ViewRevision   `json:"revisions,omitempty"`
+	Attachments []ViewAttachment `json:"attachments,omitempty"`
+}
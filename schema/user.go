@@ -115,10 +115,45 @@ type FriendReqStatus struct {
 	Sent     time.Time
 }
 
+// ViewNotification -> An entry of the notification center, ref_id is the friend request, application or subscription it is about
+type ViewNotification struct {
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	RefID     *string    `json:"ref_id,omitempty"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type ViewNotifications struct {
+	Notifications []ViewNotification `json:"notifications"`
+	Unread        int64              `json:"unread"`
+	HasMore       bool               `json:"has_more"`
+}
+
+// ViewNotificationPreference -> The channels of a notification category, the ones it isn't delivered on are left out
+type ViewNotificationPreference struct {
+	Category string `json:"category"`
+	Email    *bool  `json:"email,omitempty"`
+	InApp    *bool  `json:"in_app,omitempty"`
+	Digest   *bool  `json:"digest,omitempty"`
+}
+
+// UpdateNotificationPreference -> The channels left out keep their value
+type UpdateNotificationPreference struct {
+	Category string `json:"category" binding:"required"`
+	Email    *bool  `json:"email"`
+	InApp    *bool  `json:"in_app"`
+	Digest   *bool  `json:"digest"`
+}
+
 type ViewFriends struct {
 	ID       string
 	Username string
 	Bio      string
+	Presence string
+	LastSeen *time.Time
 }
 
 type ViewRepo struct {
@@ -0,0 +1,86 @@
+package schema
+
+import "time"
+
+// CreateWebhook -> A webhook of the user, or of one of their projects with project_id. A secret is generated when
+// none is given
+type CreateWebhook struct {
+	URL       string   `json:"url" binding:"required,url,max=2048"`
+	Events    []string `json:"events" binding:"required,min=1"`
+	ProjectID string   `json:"project_id"`
+	Secret    string   `json:"secret" binding:"max=200"`
+}
+
+// UpdateWebhook -> The fields left out keep their value, enabling a disabled webhook clears its failures
+type UpdateWebhook struct {
+	ID     string   `json:"id" binding:"required"`
+	URL    *string  `json:"url" binding:"omitempty,url,max=2048"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// ViewWebhook -> A webhook without its secret, only shown when the webhook is created
+type ViewWebhook struct {
+	ID         string     `json:"id"`
+	URL        string     `json:"url"`
+	ProjectID  *string    `json:"project_id,omitempty"`
+	Events     []string   `json:"events"`
+	Active     bool       `json:"active"`
+	Failures   int        `json:"failures"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	Secret     string     `json:"secret,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ViewWebhookDelivery -> An entry of the delivery log of a webhook, status_code is 0 when the endpoint couldn't be reached
+type ViewWebhookDelivery struct {
+	ID           string     `json:"id"`
+	EventID      string     `json:"event_id"`
+	Event        string     `json:"event"`
+	Status       string     `json:"status"`
+	Attempts     int        `json:"attempts"`
+	StatusCode   int        `json:"status_code"`
+	Error        string     `json:"error,omitempty"`
+	RedeliveryOf *string    `json:"redelivery_of,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	DeliveredAt  *time.Time `json:"delivered_at,omitempty"`
+}
+
+// WebhookPayload -> The body of a webhook delivery, id is shared by every delivery of the event
+type WebhookPayload struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// WebhookApplication -> Data of the application.received and application.accepted events
+type WebhookApplication struct {
+	ApplicationID string `json:"application_id"`
+	ProjectID     string `json:"project_id"`
+	ProjectTitle  string `json:"project_title"`
+	Applicant     string `json:"applicant"`
+	Owner         string `json:"owner"`
+	Message       string `json:"message"`
+	Status        string `json:"status"`
+}
+
+// WebhookProject -> Data of the project.updated event
+type WebhookProject struct {
+	ProjectID   string    `json:"project_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Available   bool      `json:"available"`
+	Tags        []string  `json:"tags,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookMention -> Data of the message.mention event
+type WebhookMention struct {
+	ChatID    string `json:"chat_id"`
+	ChatName  string `json:"chat_name"`
+	MsgID     string `json:"msg_id"`
+	From      string `json:"from"`
+	Mentioned string `json:"mentioned"`
+	Preview   string `json:"preview"`
+}
@@ -0,0 +1,158 @@
+package schema
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WSProtocolVersion -> Version of the chat socket protocol, frames of other versions are rejected
+const WSProtocolVersion = 1
+
+// Frame types of the chat socket protocol
+const (
+	EventMessageNew      = "message.new"
+	EventMessageEdited   = "message.edited"
+	EventMessageDeleted  = "message.deleted"
+	EventMessageRead     = "message.read"
+	EventReactionAdded   = "reaction.added"
+	EventReactionRemoved = "reaction.removed"
+	EventTypingStart     = "typing.start"
+	EventTypingStop      = "typing.stop"
+	EventMemberJoined    = "member.joined"
+	EventMemberLeft      = "member.left"
+	EventChatRenamed     = "chat.renamed"
+	EventMemberRole      = "member.role"
+	EventMessagePinned   = "message.pinned"
+	EventJoinRequested   = "join.requested"
+	EventMentioned       = "mention.new"
+	EventChatClosed      = "chat.closed"
+	EventSubscribe       = "subscribe"
+	EventUnsubscribe     = "unsubscribe"
+	EventFriendRequest   = "friend.request"
+	EventFriendAccepted  = "friend.accepted"
+	EventNotification    = "notification.new"
+	EventNotifyRead      = "notification.read"
+	EventPresenceSet     = "presence.set"
+	EventResync          = "resync" // events may have been missed on a resumed stream, the client refetches its state
+	EventAck             = "ack"
+	EventError           = "error"
+)
+
+// WSEnvelope -> A frame of the user socket in both directions.
+// Chat frames carry the chat they belong to in chat_id, personal frames (friend requests...) have none.
+// Client frames carry a client generated id, answered with an ack (or error) frame whose ack field is that id.
+// Server frames carry a server correlation id, shared by the ack and the events caused by the same action,
+// and clients may acknowledge them by sending an ack frame with the event id in the ack field.
+type WSEnvelope struct {
+	V       int             `json:"v"`
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	ChatID  string          `json:"chat_id,omitempty"`
+	Ack     string          `json:"ack,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+	// Seq -> Position of a chat or personal event in the replay buffer, the id of the server-sent event.
+	// Acks, errors and typing events have none
+	Seq int64 `json:"seq,omitempty"`
+}
+
+// WSSendMessage -> Payload of a message.new frame sent by the client
+type WSSendMessage struct {
+	Message     string   `json:"msg"`
+	ReplyToID   string   `json:"reply_to,omitempty"`
+	Attachments []string `json:"attachments,omitempty"`
+}
+
+// WSReaction -> Payload of the reaction frames, the client leaves uid empty
+type WSReaction struct {
+	MsgID  string `json:"msg_id"`
+	UserID string `json:"uid,omitempty"`
+	Emoji  string `json:"emoji"`
+}
+
+// WSReadMessage -> Payload of a message.read frame sent by the client
+type WSReadMessage struct {
+	MsgID string `json:"msg_id"`
+}
+
+type WSMessageDeleted struct {
+	ID string `json:"id"`
+	// Who deleted it, the sender or the owner of the group
+	By string `json:"by"`
+	// Deleted only for the receiving user (sent to their own sockets)
+	ForMe bool `json:"for_me,omitempty"`
+}
+
+type WSReadReceipt struct {
+	UserID string    `json:"uid"`
+	MsgID  string    `json:"msg_id"`
+	ReadAt time.Time `json:"read_at"`
+}
+
+type WSTyping struct {
+	UserID string `json:"uid"`
+}
+
+type WSMember struct {
+	UserID   string `json:"uid"`
+	UserName string `json:"username"`
+	By       string `json:"by"`
+}
+
+type WSMemberRole struct {
+	UserID string `json:"uid"`
+	Role   string `json:"role"`
+	By     string `json:"by"`
+}
+
+// WSMessagePinned -> Payload of message.pinned, an empty msg_id means the pinned message was removed
+type WSMessagePinned struct {
+	MsgID string `json:"msg_id,omitempty"`
+	By    string `json:"by"`
+}
+
+// WSMention -> Payload sent to the sockets of a member mentioned in a group chat
+type WSMention struct {
+	MsgID   string `json:"msg_id"`
+	From    string `json:"from"`
+	Preview string `json:"preview"`
+}
+
+type WSChatRenamed struct {
+	Name string `json:"name"`
+	By   string `json:"by"`
+}
+
+// WSChatClosed -> Payload sent to a socket no longer receiving the events of a chat it didn't unsubscribe from
+type WSChatClosed struct {
+	Reason string `json:"reason"`
+}
+
+type WSFriendAccepted struct {
+	UserID   string `json:"uid"`
+	UserName string `json:"username"`
+	ChatID   string `json:"chat_id"`
+}
+
+// WSNotification -> Payload of notification.new, unread is the new badge count of the user
+type WSNotification struct {
+	Notification ViewNotification `json:"notification"`
+	Unread       int64            `json:"unread"`
+}
+
+// WSNotificationsRead -> Payload of notification.read, sent to the other sockets of a user when notifications are read
+// or deleted. An empty id means all of them were read
+type WSNotificationsRead struct {
+	ID      string `json:"id,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+	Unread  int64  `json:"unread"`
+}
+
+// WSPresence -> Payload of a presence.set frame, online or away
+type WSPresence struct {
+	Status string `json:"status"`
+}
+
+type WSError struct {
+	Code    int    `json:"code"`
+	Message string `json:"msg"`
+}
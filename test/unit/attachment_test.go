@@ -0,0 +1,180 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"findme/core"
+	"findme/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type AttachmentResponse struct {
+	Attachment schema.ViewAttachment `json:"attachment"`
+}
+
+func uploadFile(t *testing.T, token, chatID, name string, content []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	_ = form.WriteField("chat_id", chatID)
+	part, _ := form.CreateFormFile("file", name)
+	_, _ = part.Write(content)
+	_ = form.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/msg/upload", body)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func testImage() []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 800, 400))
+	for x := 0; x < 800; x++ {
+		img.Set(x, x%400, color.RGBA{R: 255, A: 255})
+	}
+	buf := &bytes.Buffer{}
+	_ = png.Encode(buf, img)
+	return buf.Bytes()
+}
+
+func TestAttachments(t *testing.T) {
+	w := uploadFile(t, tokenString, gid, "../../screenshot.png", testImage())
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var uploaded AttachmentResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &uploaded)
+	assert.Equal(t, "screenshot.png", uploaded.Attachment.Name)
+	assert.Equal(t, "image/png", uploaded.Attachment.ContentType)
+	assert.Equal(t, 800, uploaded.Attachment.Width)
+	assert.NotEmpty(t, uploaded.Attachment.ThumbURL)
+
+	// The type is sniffed from the content, and only members can upload
+	w = uploadFile(t, tokenString, gid, "spec.png", []byte("<html><script>alert(1)</script></html>"))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	w = uploadFile(t, tokenString1, gid, "spec.txt", []byte("the spec"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	body, _ := json.Marshal(map[string]any{"chat_id": gid, "attachments": []string{uploaded.Attachment.ID}})
+	req, _ := http.NewRequest(http.MethodPost, "/api/msg/send-message", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), uploaded.Attachment.ID)
+
+	// A file is sent along a single message
+	req, _ = http.NewRequest(http.MethodPost, "/api/msg/send-message", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var hist ViewChats
+	req, _ = http.NewRequest(http.MethodGet, "/api/msg/view-hist?id="+gid, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	_ = json.Unmarshal(w.Body.Bytes(), &hist)
+	if !assert.Len(t, hist.Message, 1) || !assert.Len(t, hist.Message[0].Attachments, 1) {
+		return
+	}
+	file := hist.Message[0].Attachments[0]
+
+	req, _ = http.NewRequest(http.MethodGet, file.ThumbURL, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	thumb, err := png.DecodeConfig(w.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, core.ThumbnailSize, thumb.Width)
+	assert.Equal(t, core.ThumbnailSize/2, thumb.Height)
+
+	req, _ = http.NewRequest(http.MethodGet, file.URL, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, testImage(), w.Body.Bytes())
+
+	// Urls can't be reused for another file or user
+	forged := strings.Replace(file.URL, id1, id2, 1)
+	req, _ = http.NewRequest(http.MethodGet, forged, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestS3Storage(t *testing.T) {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	s3 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=minio/") || !strings.Contains(auth, "/us-east-1/s3/aws4_request") ||
+			r.Header.Get("X-Amz-Content-Sha256") != "UNSIGNED-PAYLOAD" || r.Header.Get("X-Amz-Date") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(data)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer s3.Close()
+
+	store, err := core.NewS3Storage(s3.URL, "", "chats", "minio", "minio-secret", s3.Client())
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	assert.NoError(t, store.Put(ctx, "chats/1/file", strings.NewReader("spec"), 4, "text/plain"))
+	assert.Contains(t, objects, "/chats/chats/1/file")
+
+	body, err := store.Get(ctx, "chats/1/file")
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	_ = body.Close()
+	assert.Equal(t, "spec", string(data))
+
+	assert.NoError(t, store.Delete(ctx, "chats/1/file"))
+	_, err = store.Get(ctx, "chats/1/file")
+	assert.ErrorIs(t, err, core.ErrObjectNotFound)
+}
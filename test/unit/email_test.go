@@ -0,0 +1,80 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"findme/core"
+	"findme/handlers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func getEmailPreviewRouter() *gin.Engine {
+	r := gin.New()
+	r.GET("/email-preview", handlers.EmailTemplates)
+	r.GET("/email-preview/:name", handlers.PreviewEmail)
+	return r
+}
+
+func TestPreviewEmails(t *testing.T) {
+	r := getEmailPreviewRouter()
+
+	req, _ := http.NewRequest(http.MethodGet, "/email-preview", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var res struct {
+		Templates []string `json:"templates"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	assert.Len(t, res.Templates, 13)
+
+	for _, name := range res.Templates {
+		req, _ = http.NewRequest(http.MethodGet, "/email-preview/"+name, nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, name)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, w.Body.String(), "This is an automated email, please do not reply.", name)
+
+		req, _ = http.NewRequest(http.MethodGet, "/email-preview/"+name+"?format=text", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, name)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+		assert.Regexp(t, `^Subject: \S`, w.Body.String(), name)
+		assert.NotContains(t, w.Body.String(), "<p", name)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/email-preview/unknown", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestEmailEscaping(t *testing.T) {
+	email := core.NewEmailService("", "", "", 0)
+
+	content, err := email.RenderFriendReqEmail(`<img src=x onerror="alert(1)">`, "janedoe", "<script>alert('hi')</script>", "javascript:alert(1)")
+	assert.NoError(t, err)
+	assert.NotContains(t, content.HTML, "<script>")
+	assert.NotContains(t, content.HTML, "<img")
+	assert.Contains(t, content.HTML, "&lt;script&gt;")
+	assert.NotContains(t, content.HTML, `href="javascript:`)
+	// The plain text part isn't html, so it keeps the message as written
+	assert.Contains(t, content.Text, "<script>alert('hi')</script>")
+
+	content, err = email.RenderMentionEmail("john&doe", "janedoe", "Team <b>A</b>", "hey @janedoe")
+	assert.NoError(t, err)
+	assert.Equal(t, "john&doe mentioned you in Team <b>A</b>", content.Subject)
+	assert.Contains(t, content.HTML, "Team &lt;b&gt;A&lt;/b&gt;")
+	assert.Contains(t, content.HTML, "john&amp;doe")
+	// There's no link to the chat, so no button pointing nowhere
+	assert.NotContains(t, content.HTML, "Open Chat")
+	assert.NotContains(t, content.Text, "Open the chat")
+}
@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"findme/core"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakerOpens(t *testing.T) {
+	breaker := core.NewCircuitBreaker(3, time.Minute)
+
+	for range 2 {
+		assert.NoError(t, breaker.Allow())
+		assert.False(t, breaker.Failure())
+	}
+	assert.Equal(t, core.CircuitClosed, breaker.State())
+
+	assert.NoError(t, breaker.Allow())
+	assert.True(t, breaker.Failure())
+	assert.Equal(t, core.CircuitOpen, breaker.State())
+	assert.ErrorIs(t, breaker.Allow(), core.ErrCircuitOpen)
+}
+
+func TestCircuitBreakerSuccessResets(t *testing.T) {
+	breaker := core.NewCircuitBreaker(2, time.Minute)
+
+	breaker.Failure()
+	breaker.Success()
+	breaker.Failure()
+
+	assert.Equal(t, core.CircuitClosed, breaker.State())
+	assert.NoError(t, breaker.Allow())
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	breaker := core.NewCircuitBreaker(1, 10*time.Millisecond)
+
+	breaker.Failure()
+	assert.ErrorIs(t, breaker.Allow(), core.ErrCircuitOpen)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, core.CircuitHalfOpen, breaker.State())
+
+	// Only a single probe is let through while half open
+	assert.NoError(t, breaker.Allow())
+	assert.ErrorIs(t, breaker.Allow(), core.ErrCircuitOpen)
+
+	// A failed probe re-opens the circuit
+	assert.True(t, breaker.Failure())
+	assert.Equal(t, core.CircuitOpen, breaker.State())
+
+	time.Sleep(20 * time.Millisecond)
+	assert.NoError(t, breaker.Allow())
+	breaker.Success()
+	assert.Equal(t, core.CircuitClosed, breaker.State())
+}
@@ -0,0 +1,47 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveness(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/livez", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReadiness(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "ready")
+}
+
+func TestDetailedHealth(t *testing.T) {
+	for range 2 {
+		req, _ := http.NewRequest(http.MethodGet, "/health/detailed", nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "embedding")
+		assert.Contains(t, w.Body.String(), "recommendation")
+		assert.Contains(t, w.Body.String(), "email")
+		assert.Contains(t, w.Body.String(), "cron")
+		assert.Contains(t, w.Body.String(), "checked_at")
+	}
+
+	// The second request reuses the results of the first probe
+	assert.Equal(t, 1, mailer.HealthChecks())
+}
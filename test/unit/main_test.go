@@ -26,12 +26,22 @@ var (
 	gid = ""
 )
 
-var router *gin.Engine
+var (
+	router     *gin.Engine
+	chatHub    *core.ChatHub
+	storage    *core.LocalStorage
+	mailer     *EmailHub
+	webhookHub *core.WebhookHub
+)
 
 func getTestDB() *core.GormDB {
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
+	// Every connection to :memory: opens a new empty database, so the hub workers must share the only one
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.SetMaxOpenConns(1)
+	}
 
 	_ = db.AutoMigrate(
 		&model.User{},
@@ -43,11 +53,22 @@ func getTestDB() *core.GormDB {
 		&model.UserFriend{},
 		&model.FriendReq{},
 		&model.UserMessage{},
+		&model.MessageReaction{},
+		&model.Attachment{},
+		&model.MessageRevision{},
+		&model.HiddenMessage{},
+		&model.MessageMention{},
+		&model.Notification{},
+		&model.NotificationPreference{},
 		&model.UserSavedProject{},
 		&model.Chat{},
 		&model.ChatUser{},
+		&model.ChatInvite{},
+		&model.ChatJoinRequest{},
 		&model.Subscriptions{},
 		&model.Transactions{},
+		&model.Webhook{},
+		&model.WebhookDelivery{},
 	)
 
 	_ = db.SetupJoinTable(&model.Project{}, "Tags", &model.ProjectSkill{})
@@ -138,6 +159,7 @@ func superUser(db *core.GormDB) {
 	_ = db.DB.Model(&super).Association("Chats").Append(&chat)
 	_ = db.DB.Model(&super1).Association("Chats").Append(&chat)
 	_ = db.DB.Model(&super).Association("Chats").Append(&groupchat)
+	db.DB.Model(&model.ChatUser{}).Where("chat_id = ? AND user_id = ?", groupchat.ID, super.ID).Update("role", model.RoleOwner)
 
 	id1 = super.ID
 	id2 = super1.ID
@@ -151,14 +173,27 @@ func TestMain(m *testing.M) {
 	rdb := NewCacheMock()
 	git := NewGitMock()
 	transc := NewTranscMock()
-	chathub := core.NewChatHub(20)
+	chathub := core.NewChatHub(db, nil, 20)
+	chatHub = chathub
 	emailHub := NewEmailHubMock()
+	chathub.Email = emailHub
+	// The webhook tests deliver to local servers
+	core.AllowPrivateWebhooks(true)
+	webhookHub = core.NewWebhookHub(50, 2, db, core.NewWebhookClient(5*time.Second))
+	webhookHub.Backoff = 10 * time.Millisecond
+	webhookHub.PollInterval = 10 * time.Millisecond
+	chathub.Webhooks = webhookHub
+	mailer = emailHub
 	cron := NewCronMock()
 	embhub := NewEmbeddingMock()
 	recHub := NewRecommendationMock()
 
+	dir, _ := os.MkdirTemp("", "findme-uploads")
+	storage, _ = core.NewLocalStorage(dir)
+
 	go chathub.Run()
-	service := handlers.NewService(db, rdb, emailHub, git, transc, embhub, recHub, &http.Client{}, chathub, cron)
+	go webhookHub.Run()
+	service := handlers.NewService(db, rdb, emailHub, git, transc, embhub, recHub, &http.Client{}, chathub, cron, storage, core.NewNotificationService(db, chathub), webhookHub)
 
 	var skills []model.Skill
 	_ = service.DB.FetchAllSkills(&skills)
@@ -168,5 +203,6 @@ func TestMain(m *testing.M) {
 	tokenString1, _ = handlers.GenerateJWT(id2, "login", true, handlers.JWTExpiry) // User for saving post
 	code := m.Run()
 
+	_ = os.RemoveAll(dir)
 	os.Exit(code)
 }
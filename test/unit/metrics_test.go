@@ -0,0 +1,47 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"findme/handlers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func getMetricsRouter() *gin.Engine {
+	r := gin.New()
+	r.Use(handlers.Metrics())
+	r.GET("/metrics", handlers.MetricsHandler("metrics-token"))
+	return r
+}
+
+func TestMetricsUnauthorized(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer wrong-token")
+
+	w := httptest.NewRecorder()
+	getMetricsRouter().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestMetrics(t *testing.T) {
+	r := getMetricsRouter()
+
+	// Generate a request for the http metrics to pick up
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	r.ServeHTTP(w, req)
+
+	req, _ = http.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer metrics-token")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `findme_http_requests_total{method="GET",route="/metrics",status="401"}`)
+}
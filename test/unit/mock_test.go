@@ -1,8 +1,11 @@
 package unit
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"sync"
+	"time"
 
 	"findme/core"
 	"findme/model"
@@ -16,6 +19,10 @@ type CacheMock struct {
 	Otp   map[string]string
 }
 
+func (mock *CacheMock) WithContext(_ context.Context) core.Cache {
+	return mock
+}
+
 func (mock *CacheMock) CheckHealth() error {
 	return nil
 }
@@ -74,19 +81,90 @@ func NewCacheMock() *CacheMock {
 	}
 }
 
-type EmailHub struct{}
+type EmailHub struct {
+	mu           sync.Mutex
+	mentions     []string
+	exports      []string
+	digests      []Digest
+	healthChecks int
+}
+
+type Digest struct {
+	To             string
+	Chats          []core.DigestChat
+	UnsubscribeURL string
+}
+
+func (mock *EmailHub) QueueSubscriptionCreate(_ context.Context, _, _, _, _, _, _ string)     {}
+func (mock *EmailHub) QueueProjectApplicationReject(_ context.Context, _, _, _, _, _ string)  {}
+func (mock *EmailHub) QueueProjectApplicationAccept(_ context.Context, _, _, _, _, _ string)  {}
+func (mock *EmailHub) QueueTransactionFailedEmail(_ context.Context, _, _, _, _, _, _ string) {}
+func (mock *EmailHub) QueueProjectApplication(_ context.Context, _, _, _, _, _ string)        {}
+func (mock *EmailHub) QueueFriendReqEmail(_ context.Context, _, _, _, _, _ string)            {}
+func (mock *EmailHub) QueueForgotPassEmail(_ context.Context, _, _, _ string)                 {}
+func (mock *EmailHub) QueueSubscriptionReEnabled(_ context.Context, _, _, _ string)           {}
+func (mock *EmailHub) QueueSubscriptionCancelled(_ context.Context, _, _, _ string)           {}
+func (mock *EmailHub) QueueNotifyFreeTrialEnding(_ context.Context, _, _, _, _ string)        {}
+func (mock *EmailHub) QueueMentionEmail(_ context.Context, _, _, _, _, to string) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	mock.mentions = append(mock.mentions, to)
+}
 
-func (mock *EmailHub) QueueSubscriptionCreate(_, _, _, _, _, _ string)     {}
-func (mock *EmailHub) QueueProjectApplicationReject(_, _, _, _, _ string)  {}
-func (mock *EmailHub) QueueProjectApplicationAccept(_, _, _, _, _ string)  {}
-func (mock *EmailHub) QueueTransactionFailedEmail(_, _, _, _, _, _ string) {}
-func (mock *EmailHub) QueueProjectApplication(_, _, _, _, _ string)        {}
-func (mock *EmailHub) QueueFriendReqEmail(_, _, _, _, _ string)            {}
-func (mock *EmailHub) QueueForgotPassEmail(_, _, _ string)                 {}
-func (mock *EmailHub) QueueSubscriptionReEnabled(_, _, _ string)           {}
-func (mock *EmailHub) QueueSubscriptionCancelled(_, _, _ string)           {}
-func (mock *EmailHub) QueueNotifyFreeTrialEnding(_, _, _, _ string)        {}
-func (mock *EmailHub) Worker()                                             {}
+// Mentioned -> Returns the addresses mentions were emailed to since the last call
+func (mock *EmailHub) Mentioned() []string {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	to := mock.mentions
+	mock.mentions = nil
+	return to
+}
+
+func (mock *EmailHub) QueueChatExportEmail(_ context.Context, _, _, _, downloadURL, _, _ string) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	mock.exports = append(mock.exports, downloadURL)
+}
+
+// Exported -> Returns the export links emailed since the last call
+func (mock *EmailHub) Exported() []string {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	urls := mock.exports
+	mock.exports = nil
+	return urls
+}
+
+func (mock *EmailHub) QueueMessageDigest(_ context.Context, _ string, chats []core.DigestChat, unsubscribeURL, to string) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	mock.digests = append(mock.digests, Digest{To: to, Chats: chats, UnsubscribeURL: unsubscribeURL})
+}
+
+// Digests -> Returns the digests queued since the last call
+func (mock *EmailHub) Digests() []Digest {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	digests := mock.digests
+	mock.digests = nil
+	return digests
+}
+
+func (mock *EmailHub) Worker() {}
+func (mock *EmailHub) CheckHealth() error {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	mock.healthChecks++
+	return nil
+}
+
+func (mock *EmailHub) HealthChecks() int {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	return mock.healthChecks
+}
+
+func (mock *EmailHub) Stats() core.HubStats { return core.HubStats{} }
 
 func NewEmailHubMock() *EmailHub {
 	return &EmailHub{}
@@ -94,30 +172,61 @@ func NewEmailHubMock() *EmailHub {
 
 type EmailMock struct{}
 
-func (mock *EmailMock) SendForgotPassEmail(_, _ string) (string, string)               { return "", "" }
-func (mock *EmailMock) SendFriendReqEmail(_, _, _, _ string) (string, string)          { return "", "" }
-func (mock *EmailMock) SendProjectApplicationEmail(_, _, _, _ string) (string, string) { return "", "" }
-func (mock *EmailMock) SendProjectApplicationAccept(_, _, _, _ string) (string, string) {
-	return "", ""
+func (mock *EmailMock) RenderForgotPassEmail(_, _ string) (core.EmailContent, error) {
+	return core.EmailContent{}, nil
+}
+
+func (mock *EmailMock) RenderFriendReqEmail(_, _, _, _ string) (core.EmailContent, error) {
+	return core.EmailContent{}, nil
+}
+
+func (mock *EmailMock) RenderProjectApplicationEmail(_, _, _, _ string) (core.EmailContent, error) {
+	return core.EmailContent{}, nil
+}
+
+func (mock *EmailMock) RenderProjectApplicationAccept(_, _, _, _ string) (core.EmailContent, error) {
+	return core.EmailContent{}, nil
+}
+
+func (mock *EmailMock) RenderProjectApplicationReject(_, _, _, _ string) (core.EmailContent, error) {
+	return core.EmailContent{}, nil
+}
+
+func (mock *EmailMock) RenderSubscriptionCreateEmail(_, _, _, _, _ string) (core.EmailContent, error) {
+	return core.EmailContent{}, nil
+}
+
+func (mock *EmailMock) RenderTransactionFailedEmail(_, _, _, _, _ string) (core.EmailContent, error) {
+	return core.EmailContent{}, nil
+}
+
+func (mock *EmailMock) RenderSubscriptionCancelledEmail(_, _ string) (core.EmailContent, error) {
+	return core.EmailContent{}, nil
+}
+
+func (mock *EmailMock) RenderSubscriptionReEnabledEmail(_, _ string) (core.EmailContent, error) {
+	return core.EmailContent{}, nil
+}
+
+func (mock *EmailMock) RenderNotifyFreeTrialEnding(_, _, _ string) (core.EmailContent, error) {
+	return core.EmailContent{}, nil
 }
 
-func (mock *EmailMock) SendProjectApplicationReject(_, _, _, _ string) (string, string) {
-	return "", ""
+func (mock *EmailMock) RenderMentionEmail(_, _, _, _ string) (core.EmailContent, error) {
+	return core.EmailContent{}, nil
 }
 
-func (mock *EmailMock) SendSubscriptionCreateEmail(_, _, _, _, _ string) (string, string) {
-	return "", ""
+func (mock *EmailMock) RenderChatExportEmail(_, _, _, _, _ string) (core.EmailContent, error) {
+	return core.EmailContent{}, nil
 }
 
-func (mock *EmailMock) SendTransactionFailedEmail(_, _, _, _, _ string) (string, string) {
-	return "", ""
+func (mock *EmailMock) RenderMessageDigestEmail(_ string, _ []core.DigestChat, _ string) (core.EmailContent, error) {
+	return core.EmailContent{}, nil
 }
-func (mock *EmailMock) SendSubscriptionCancelledEmail(_, _ string) (string, string) { return "", "" }
-func (mock *EmailMock) SendSubscriptionReEnabledEmail(_, _ string) (string, string) { return "", "" }
 
-func (mock *EmailMock) SendNotifyFreeTrialEnding(_, _, _ string) (string, string) { return "", "" }
+func (mock *EmailMock) SendEmail(_, _, _, _, _ string) error { return nil }
 
-func (mock *EmailMock) SendEmail(_, _, _ string) error { return nil }
+func (mock *EmailMock) CheckHealth() error { return nil }
 
 func NewEmailMock() *EmailMock {
 	return &EmailMock{}
@@ -131,11 +240,11 @@ func (mock *GitMock) SelectCallback(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"token": "1234", "msg": "Logged in successfully."})
 }
 
-func (mock *GitMock) GitHubAddUserCallback(_, _, _, _ string) (string, string, error) {
+func (mock *GitMock) GitHubAddUserCallback(_ context.Context, _, _, _, _ string) (string, string, error) {
 	return "", "", nil
 }
 
-func (mock *GitMock) ConnectGitHubCallback(_, _, _, _ string) (string, error) {
+func (mock *GitMock) ConnectGitHubCallback(_ context.Context, _, _, _, _ string) (string, error) {
 	return "", nil
 }
 
@@ -156,7 +265,7 @@ func NewGitMock() *GitMock {
 type TranscMock struct{}
 
 func (mock *TranscMock) InitializeTransaction(_ *gin.Context) {}
-func (mock *TranscMock) FetchCustomerDetails(cusCode string) (*schema.UpdatedPaymentInfo, error) {
+func (mock *TranscMock) FetchCustomerDetails(_ context.Context, cusCode string) (*schema.UpdatedPaymentInfo, error) {
 	return nil, nil
 }
 func (mock *TranscMock) GetTransactions(_ *gin.Context)          {}
@@ -173,14 +282,21 @@ func NewTranscMock() *TranscMock {
 
 type EmbeddingMock struct{}
 
-func (e *EmbeddingMock) QueueUserCreate(id, bio string, skills, interests []string)             {}
-func (e *EmbeddingMock) QueueUserUpdate(id, bio string, skills, interest []string)              {}
-func (e *EmbeddingMock) QueueUserUpdateStatus(id string, status bool)                           {}
-func (e *EmbeddingMock) QueueUserDelete(id string)                                              {}
-func (e *EmbeddingMock) QueueProjectCreate(id, title, description, uid string, skills []string) {}
-func (e *EmbeddingMock) QueueProjectUpdate(id, title, description string, skills []string)      {}
-func (e *EmbeddingMock) QueueProjectUpdateStatus(id string, status bool)                        {}
-func (e *EmbeddingMock) QueueProjectDelete(id string)                                           {}
+func (e *EmbeddingMock) QueueUserCreate(_ context.Context, id, bio string, skills, interests []string) {
+}
+func (e *EmbeddingMock) QueueUserUpdate(_ context.Context, id, bio string, skills, interest []string) {
+}
+func (e *EmbeddingMock) QueueUserUpdateStatus(_ context.Context, id string, status bool) {}
+func (e *EmbeddingMock) QueueUserDelete(_ context.Context, id string)                    {}
+func (e *EmbeddingMock) QueueProjectCreate(_ context.Context, id, title, description, uid string, skills []string) {
+}
+func (e *EmbeddingMock) QueueProjectUpdate(_ context.Context, id, title, description string, skills []string) {
+}
+func (e *EmbeddingMock) QueueProjectUpdateStatus(_ context.Context, id string, status bool) {}
+func (e *EmbeddingMock) QueueProjectDelete(_ context.Context, id string)                    {}
+func (e *EmbeddingMock) CircuitState() string                                               { return core.CircuitClosed }
+func (e *EmbeddingMock) CheckHealth() error                                                 { return nil }
+func (e *EmbeddingMock) Stats() core.HubStats                                               { return core.HubStats{} }
 
 func NewEmbeddingMock() *EmbeddingMock {
 	return &EmbeddingMock{}
@@ -188,9 +304,12 @@ func NewEmbeddingMock() *EmbeddingMock {
 
 type RecommendationMock struct{}
 
-func (r *RecommendationMock) QueueUserRecommendation(_ string)    {}
-func (r *RecommendationMock) QueueProjectRecommendation(_ string) {}
-func (r *RecommendationMock) GetRecommendation(ID string, jobType core.RecommendationJobType) (*schema.RecResponse, error) {
+func (r *RecommendationMock) QueueUserRecommendation(_ context.Context, _ string)    {}
+func (r *RecommendationMock) QueueProjectRecommendation(_ context.Context, _ string) {}
+func (r *RecommendationMock) CircuitState() string                                   { return core.CircuitClosed }
+func (r *RecommendationMock) CheckHealth() error                                     { return nil }
+func (r *RecommendationMock) Stats() core.HubStats                                   { return core.HubStats{} }
+func (r *RecommendationMock) GetRecommendation(_ context.Context, ID string, jobType core.RecommendationJobType) (*schema.RecResponse, error) {
 	return &schema.RecResponse{}, nil
 }
 
@@ -204,4 +323,90 @@ func NewCronMock() *CronMock {
 	return &CronMock{}
 }
 
-func (mock *CronMock) TrialEndingReminders() error { return nil }
+func (mock *CronMock) TrialEndingReminders() error    { return nil }
+func (mock *CronMock) MessageDigests() error          { return nil }
+func (mock *CronMock) LastRuns() map[string]time.Time { return map[string]time.Time{} }
+
+// BusMock -> In memory stand-in for redis pub/sub and the presence registry, shared by the hubs of a test
+type BusMock struct {
+	mu       sync.Mutex
+	subs     []chan *core.BusMessage
+	presence map[string]map[string]string
+	replay   []*core.BusMessage
+}
+
+func NewBusMock() *BusMock {
+	return &BusMock{presence: make(map[string]map[string]string)}
+}
+
+func (mock *BusMock) PublishEvent(msg *core.BusMessage) error {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	for _, sub := range mock.subs {
+		sub <- msg
+	}
+	return nil
+}
+
+func (mock *BusMock) SubscribeEvents(_ context.Context) <-chan *core.BusMessage {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	sub := make(chan *core.BusMessage, 100)
+	mock.subs = append(mock.subs, sub)
+	return sub
+}
+
+func (mock *BusMock) Heartbeat(instance string, users map[string]string, _ time.Duration) error {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	for uid, status := range users {
+		if mock.presence[uid] == nil {
+			mock.presence[uid] = make(map[string]string)
+		}
+		mock.presence[uid][instance] = status
+	}
+	return nil
+}
+
+func (mock *BusMock) RemovePresence(instance string, users []string) error {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	for _, uid := range users {
+		delete(mock.presence[uid], instance)
+	}
+	return nil
+}
+
+func (mock *BusMock) AppendReplay(msg *core.BusMessage) error {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	mock.replay = append(mock.replay, msg)
+	return nil
+}
+
+func (mock *BusMock) Replay(after int64) ([]*core.BusMessage, error) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	var msgs []*core.BusMessage
+	for _, msg := range mock.replay {
+		if msg.Data.Seq > after {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs, nil
+}
+
+func (mock *BusMock) Presence(users []string) (map[string]string, error) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	presence := make(map[string]string, len(users))
+	for _, uid := range users {
+		presence[uid] = core.PresenceOffline
+		for _, status := range mock.presence[uid] {
+			if presence[uid] != core.PresenceOnline {
+				presence[uid] = status
+			}
+		}
+	}
+	return presence, nil
+}
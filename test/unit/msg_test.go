@@ -5,7 +5,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
+
+	"findme/core"
+	"findme/model"
+	"findme/schema"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -21,7 +28,8 @@ var (
 func TestCreateMessage(t *testing.T) {
 	payload := msgDefPayload
 	payload["chat_id"] = cid
-	body, _ := json.Marshal(payload)
+	// Sent padded, stored trimmed like the messages sent over the socket
+	body, _ := json.Marshal(map[string]string{"chat_id": cid, "msg": "  " + payload["msg"] + "\n"})
 
 	req, _ := http.NewRequest(http.MethodPost, "/api/msg/send-message", bytes.NewBuffer(body))
 	req.Header.Set("Authorization", "Bearer "+tokenString)
@@ -30,7 +38,7 @@ func TestCreateMessage(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusCreated, w.Code)
-	assert.Contains(t, w.Body.String(), payload["msg"])
+	assert.Contains(t, w.Body.String(), `"msg":"`+payload["msg"]+`"`)
 
 	_ = json.Unmarshal(w.Body.Bytes(), &msg)
 }
@@ -50,6 +58,18 @@ func TestCreateMessageInvalidChatID(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "Chat not found.")
 }
 
+func TestCreateMessageBlank(t *testing.T) {
+	body, _ := json.Marshal(map[string]string{"chat_id": cid, "msg": " \n\t "})
+	req, _ := http.NewRequest(http.MethodPost, "/api/msg/send-message", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "Message can't be empty.")
+}
+
 func TestViewHist(t *testing.T) {
 	req, _ := http.NewRequest(http.MethodGet, "/api/msg/view-hist?id="+cid, nil)
 	req.Header.Set("Authorization", "Bearer "+tokenString)
@@ -73,6 +93,46 @@ func TestViewChats(t *testing.T) {
 	assert.Contains(t, w.Body.String(), cid)
 }
 
+func TestMarkRead(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/api/msg/view-chats", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString1)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Unread":1`)
+	assert.Contains(t, w.Body.String(), `"Preview":"`+msgDefPayload["msg"]+`"`)
+
+	// Messages of another chat can't be used as a cursor
+	body, _ := json.Marshal(map[string]string{"chat_id": gid, "msg_id": msg.ID})
+	req, _ = http.NewRequest(http.MethodPatch, "/api/msg/mark-read", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	body, _ = json.Marshal(map[string]string{"chat_id": cid})
+	req, _ = http.NewRequest(http.MethodPatch, "/api/msg/mark-read", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString1)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/msg/view-chats", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString1)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Unread":0`)
+}
+
 func TestEditMessage(t *testing.T) {
 	payload := map[string]string{
 		"msg":    "Yo i really need your help i'm almost done with the project but i don't do mobile dev",
@@ -100,7 +160,236 @@ func TestDeleteMessage(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, w.Code)
 }
 
+func TestViewHistPages(t *testing.T) {
+	var sent []string
+	for _, text := range []string{"First page one", "First page two", "Last page"} {
+		m := model.UserMessage{ChatID: cid, FromID: id2, Message: text}
+		assert.NoError(t, chatHub.DB.AddMessage(&m))
+		sent = append(sent, m.ID)
+	}
+
+	var hist ViewChats
+	req, _ := http.NewRequest(http.MethodGet, "/api/msg/view-hist?limit=2&id="+cid, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	_ = json.Unmarshal(w.Body.Bytes(), &hist)
+	assert.True(t, hist.HasMore)
+	assert.Len(t, hist.Message, 2)
+	assert.Equal(t, sent[2], hist.Message[1].ID)
+	assert.Equal(t, id2, hist.Message[1].UserID)
+	assert.Equal(t, superUserName1, hist.Message[1].Username)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/msg/view-hist?limit=2&id="+cid+"&after="+sent[0], nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	hist = ViewChats{}
+	_ = json.Unmarshal(w.Body.Bytes(), &hist)
+	assert.False(t, hist.HasMore)
+	assert.Len(t, hist.Message, 2)
+	assert.Equal(t, sent[1], hist.Message[0].ID)
+
+	// Only members can read the history
+	req, _ = http.NewRequest(http.MethodGet, "/api/msg/view-hist?id="+gid, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString1)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestSearchMessages(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/api/msg/search?q=page+<b>&id="+cid, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "First page")
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/msg/search?q=first+PAGE", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString1)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `\u003cmark\u003eFirst\u003c/mark\u003e \u003cmark\u003epage\u003c/mark\u003e two`)
+	assert.NotContains(t, w.Body.String(), "Last page")
+}
+
+func TestRepliesAndReactions(t *testing.T) {
+	root := model.UserMessage{ChatID: cid, FromID: id2, Message: "Who takes the mobile app?"}
+	assert.NoError(t, chatHub.DB.AddMessage(&root))
+
+	body, _ := json.Marshal(map[string]string{"chat_id": cid, "msg": "I do", "reply_to": root.ID})
+	req, _ := http.NewRequest(http.MethodPost, "/api/msg/send-message", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"reply_to":"`+root.ID+`"`)
+
+	// Replies stay within the chat of the quoted message
+	body, _ = json.Marshal(map[string]string{"chat_id": gid, "msg": "I do", "reply_to": root.ID})
+	req, _ = http.NewRequest(http.MethodPost, "/api/msg/send-message", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	for _, tc := range []struct {
+		emoji string
+		code  int
+	}{
+		{"👍🏽", http.StatusCreated},
+		{"👍🏽", http.StatusConflict},
+		{"lol", http.StatusBadRequest},
+	} {
+		body, _ = json.Marshal(map[string]string{"msg_id": root.ID, "emoji": tc.emoji})
+		req, _ = http.NewRequest(http.MethodPost, "/api/msg/react", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tc.code, w.Code)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/msg/view-thread?id="+root.ID, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"replies":1,"reactions":[{"emoji":"👍🏽","count":1,"me":true}]`)
+	assert.Contains(t, w.Body.String(), `"msg":"I do"`)
+
+	req, _ = http.NewRequest(http.MethodDelete, "/api/msg/unreact?id="+root.ID+"&emoji="+url.QueryEscape("👍🏽"), nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestMessageEditsAndDeletes(t *testing.T) {
+	msg := model.UserMessage{ChatID: cid, FromID: id1, Message: "Standup at 10"}
+	assert.NoError(t, chatHub.DB.AddMessage(&msg))
+
+	body, _ := json.Marshal(map[string]string{"msg_id": msg.ID, "msg": "Standup at 11"})
+	req, _ := http.NewRequest(http.MethodPatch, "/api/msg/edit-message", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"is_edited":true`)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/msg/view-edits?id="+msg.ID, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString1)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"msg":"Standup at 11","revisions":[{"msg":"Standup at 10","editor_id":"`+id1+`"`)
+
+	// Deleted for the first member only
+	req, _ = http.NewRequest(http.MethodDelete, "/api/msg/delete-message?for=me&id="+msg.ID, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	for _, tc := range []struct {
+		token   string
+		visible bool
+	}{
+		{tokenString, false},
+		{tokenString1, true},
+	} {
+		req, _ = http.NewRequest(http.MethodGet, "/api/msg/view-hist?id="+cid, nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, tc.visible, strings.Contains(w.Body.String(), msg.ID))
+	}
+
+	// The owner of a group deletes the message of another member for everyone
+	other := model.UserMessage{ChatID: gid, FromID: id2, Message: "Off topic"}
+	assert.NoError(t, chatHub.DB.AddMessage(&other))
+
+	req, _ = http.NewRequest(http.MethodDelete, "/api/msg/delete-message?id="+other.ID, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/msg/view-hist?id="+gid, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"`+other.ID+`","msg":"Message deleted."`)
+	assert.NotContains(t, w.Body.String(), "Off topic")
+
+	// Tombstones can't be edited anymore, and their history is gone
+	req, _ = http.NewRequest(http.MethodGet, "/api/msg/view-edits?id="+other.ID, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
 func TestOpenChat(t *testing.T) {
+	hidden := model.UserMessage{ChatID: cid, FromID: id2, Message: "Only for the second member"}
+	assert.NoError(t, chatHub.DB.AddMessage(&hidden))
+	deleted := model.UserMessage{ChatID: cid, FromID: id2, Message: "Sent by mistake"}
+	assert.NoError(t, chatHub.DB.AddMessage(&deleted))
+
+	for _, tc := range []struct {
+		token string
+		query string
+	}{
+		{tokenString, "for=me&id=" + hidden.ID},
+		{tokenString1, "id=" + deleted.ID},
+	} {
+		req, _ := http.NewRequest(http.MethodDelete, "/api/msg/delete-message?"+tc.query, nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	}
+
 	req, _ := http.NewRequest(http.MethodGet, "/api/msg/open-chat?id="+id2, nil)
 	req.Header.Set("Authorization", "Bearer "+tokenString)
 
@@ -109,6 +398,402 @@ func TestOpenChat(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Contains(t, w.Body.String(), superUserName1)
+	assert.Contains(t, w.Body.String(), `"username":"`+superUserName1+`"`)
+	assert.Contains(t, w.Body.String(), `"id":"`+deleted.ID+`","msg":"Message deleted."`)
+	assert.NotContains(t, w.Body.String(), "Sent by mistake")
+	assert.NotContains(t, w.Body.String(), hidden.ID)
+
+	// The other member still sees the message hidden by the first one
+	req, _ = http.NewRequest(http.MethodGet, "/api/msg/open-chat?id="+id1, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString1)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), hidden.ID)
+}
+
+func TestGroupInvitesAndRoles(t *testing.T) {
+	body, _ := json.Marshal(map[string]any{"chat_id": gid, "expires_in": 1, "max_uses": 1})
+	req, _ := http.NewRequest(http.MethodPost, "/api/msg/create-invite", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var created struct {
+		Msg schema.ViewInvite `json:"msg"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.NotNil(t, created.Msg.ExpiresAt)
+
+	body, _ = json.Marshal(map[string]any{"chat_id": gid, "enabled": true})
+	req, _ = http.NewRequest(http.MethodPatch, "/api/msg/join-approval", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	// The link only has one use, taken by the join request
+	for _, code := range []int{http.StatusAccepted, http.StatusGone} {
+		req, _ = http.NewRequest(http.MethodPost, "/api/msg/join/"+url.PathEscape(created.Msg.Token), nil)
+		req.Header.Set("Authorization", "Bearer "+tokenString1)
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, code, w.Code)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/msg/view-join-requests?id="+gid, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"uid":"`+id2+`","username":"`+superUserName1+`"`)
+
+	body, _ = json.Marshal(map[string]any{"chat_id": gid, "user_id": id2, "approve": true})
+	req, _ = http.NewRequest(http.MethodPatch, "/api/msg/review-join", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	// Plain members can't manage the group, admins can
+	for _, tc := range []struct {
+		token  string
+		method string
+		path   string
+		body   map[string]any
+		code   int
+	}{
+		{tokenString1, http.MethodPatch, "/api/msg/rename-chat", map[string]any{"chat_id": gid, "name": "Mobile"}, http.StatusForbidden},
+		{tokenString, http.MethodPatch, "/api/msg/set-role", map[string]any{"chat_id": gid, "user_id": id2, "role": "owner"}, http.StatusUnprocessableEntity},
+		{tokenString, http.MethodPatch, "/api/msg/set-role", map[string]any{"chat_id": gid, "user_id": id2, "role": "admin"}, http.StatusAccepted},
+		{tokenString1, http.MethodPatch, "/api/msg/rename-chat", map[string]any{"chat_id": gid, "name": "Mobile"}, http.StatusAccepted},
+		{tokenString1, http.MethodPatch, "/api/msg/set-role", map[string]any{"chat_id": gid, "user_id": id1, "role": "member"}, http.StatusForbidden},
+		{tokenString1, http.MethodDelete, "/api/msg/remove-user", map[string]any{"chat_id": gid, "user_id": id1}, http.StatusForbidden},
+	} {
+		body, _ = json.Marshal(tc.body)
+		req, _ = http.NewRequest(tc.method, tc.path, bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tc.code, w.Code, tc.path)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/msg/view-members?id="+gid, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString1)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"uid":"`+id1+`"`)
+	assert.Contains(t, w.Body.String(), `"role":"owner"`)
+	assert.Contains(t, w.Body.String(), `"uid":"`+id2+`","username":"`+superUserName1+`","role":"admin"`)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/msg/view-hist?id="+gid, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString1)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Role":"admin"`)
+	assert.Contains(t, w.Body.String(), `"msg":"`+superUserName1+` renamed the group to \"Mobile\""`)
+	assert.Contains(t, w.Body.String(), `"system":true`)
+
+	// Leaves the group as it was for the tests below
+	req, _ = http.NewRequest(http.MethodDelete, "/api/msg/leave-chat?id="+gid, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString1)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestChatPreferences(t *testing.T) {
+	send := func(path string, body map[string]any) int {
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPatch, path, bytes.NewBuffer(payload))
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	chats := func(query string) []schema.ViewChat {
+		req, _ := http.NewRequest(http.MethodGet, "/api/msg/view-chats"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var res struct {
+			Msg []schema.ViewChat `json:"msg"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		return res.Msg
+	}
+
+	assert.Equal(t, http.StatusAccepted, send("/api/msg/pin-chat", map[string]any{"chat_id": gid, "position": 0}))
+	list := chats("")
+	if assert.NotEmpty(t, list) {
+		assert.Equal(t, gid, list[0].CID)
+		assert.Equal(t, 0, *list[0].PinPosition)
+	}
+
+	assert.Equal(t, http.StatusAccepted, send("/api/msg/archive-chat", map[string]any{"chat_id": cid, "archived": true}))
+	for _, chat := range chats("") {
+		assert.NotEqual(t, cid, chat.CID)
+	}
+	archived := chats("?archived=true")
+	if assert.Len(t, archived, 1) {
+		assert.Equal(t, cid, archived[0].CID)
+	}
+
+	until := time.Now().Add(time.Hour)
+	assert.Equal(t, http.StatusAccepted, send("/api/msg/mute-chat", map[string]any{"chat_id": gid, "until": until}))
+	assert.Equal(t, http.StatusBadRequest, send("/api/msg/mute-chat", map[string]any{"chat_id": gid, "until": time.Now().Add(-time.Hour)}))
+	assert.Equal(t, http.StatusAccepted, send("/api/msg/notify-level", map[string]any{"chat_id": gid, "level": "mentions"}))
+	assert.Equal(t, http.StatusUnprocessableEntity, send("/api/msg/notify-level", map[string]any{"chat_id": gid, "level": "loud"}))
+
+	list = chats("")
+	if assert.NotEmpty(t, list) {
+		assert.NotNil(t, list[0].MutedUntil)
+		assert.Equal(t, model.NotifyMentions, list[0].NotifyLevel)
+	}
+
+	member := model.ChatUser{MutedUntil: &until, NotifyLevel: model.NotifyMentions}
+	assert.False(t, member.ShouldNotify(true, time.Now()))
+	assert.True(t, member.ShouldNotify(true, until.Add(time.Minute)))
+	assert.False(t, member.ShouldNotify(false, until.Add(time.Minute)))
+
+	// Back to the defaults for the tests below
+	assert.Equal(t, http.StatusAccepted, send("/api/msg/pin-chat", map[string]any{"chat_id": gid, "position": nil}))
+	assert.Equal(t, http.StatusAccepted, send("/api/msg/archive-chat", map[string]any{"chat_id": cid, "archived": false}))
+	assert.Equal(t, http.StatusAccepted, send("/api/msg/mute-chat", map[string]any{"chat_id": gid, "until": nil}))
+	assert.Equal(t, http.StatusAccepted, send("/api/msg/notify-level", map[string]any{"chat_id": gid, "level": "all"}))
+}
+
+func TestMentions(t *testing.T) {
+	assert.Equal(t, []string{"Knightmares23", "x"}, core.ParseMentions("@Knightmares23 mail me at a@b.com, @knightmares23 and @x."))
+
+	do := func(token, method, path string, body map[string]any) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	unreadMentions := func() int64 {
+		w := do(tokenString1, http.MethodGet, "/api/msg/view-chats", nil)
+		var res struct {
+			Msg []schema.ViewChat `json:"msg"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		for _, chat := range res.Msg {
+			if chat.CID == gid {
+				return chat.UnreadMentions
+			}
+		}
+		return -1
+	}
+
+	assert.Equal(t, http.StatusAccepted, do(tokenString, http.MethodPut, "/api/msg/add-user", map[string]any{"chat_id": gid, "user_id": id2}).Code)
+	mailer.Mentioned()
+
+	// The sender and the usernames outside the group aren't mentioned
+	w := do(tokenString, http.MethodPost, "/api/msg/send-message", map[string]any{"chat_id": gid, "msg": "Can @Knightmares23 and @nobody review this? cc @" + superUserName})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"mentions":[{"uid":"`+id2+`","username":"`+superUserName1+`"}]`)
+	assert.Equal(t, int64(1), unreadMentions())
+	// Not connected, so the mention is emailed
+	assert.Equal(t, []string{"knightmares234@gmail.com"}, mailer.Mentioned())
+
+	w = do(tokenString1, http.MethodGet, "/api/msg/view-hist?id="+gid, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"mentioned":true`)
+
+	// Muted members still get the mention recorded but aren't notified
+	assert.Equal(t, http.StatusAccepted, do(tokenString1, http.MethodPatch, "/api/msg/notify-level", map[string]any{"chat_id": gid, "level": "none"}).Code)
+	assert.Equal(t, http.StatusCreated, do(tokenString, http.MethodPost, "/api/msg/send-message", map[string]any{"chat_id": gid, "msg": "@knightmares23 ping"}).Code)
+	assert.Equal(t, int64(2), unreadMentions())
+	assert.Empty(t, mailer.Mentioned())
+
+	// Mentions in direct chats aren't recorded
+	w = do(tokenString, http.MethodPost, "/api/msg/send-message", map[string]any{"chat_id": cid, "msg": "@knightmares23 ping"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NotContains(t, w.Body.String(), `"mentions"`)
+
+	// Leaves the group as it was for the tests below
+	assert.Equal(t, http.StatusNoContent, do(tokenString, http.MethodDelete, "/api/msg/remove-user", map[string]any{"chat_id": gid, "user_id": id2}).Code)
+}
+
+func TestExportChat(t *testing.T) {
+	link := model.UserMessage{ChatID: cid, FromID: id2, Message: "[Open the build](javascript:alert(1)) **now**"}
+	assert.NoError(t, chatHub.DB.AddMessage(&link))
+	msg := model.UserMessage{ChatID: cid, FromID: id2, Message: "<b>Ship it</b>\non friday"}
+	assert.NoError(t, chatHub.DB.AddMessage(&msg))
+
+	export := func(token, query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/api/msg/export"+query, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := export(tokenString, "?id="+cid)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	var transcript schema.DocExportChat
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &transcript))
+	assert.Equal(t, superUserName1, transcript.Name)
+	if assert.NotEmpty(t, transcript.Messages) {
+		last := transcript.Messages[len(transcript.Messages)-1]
+		assert.Equal(t, msg.ID, last.ID)
+		assert.Equal(t, superUserName1, last.Username)
+		assert.True(t, transcript.Messages[0].Sent.Before(last.Sent) || transcript.Messages[0].Sent.Equal(last.Sent))
+	}
+
+	w = export(tokenString, "?format=markdown&id="+cid)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Body.String(), "# "+superUserName1))
+	assert.Contains(t, w.Body.String(), "> &lt;b&gt;Ship it&lt;/b&gt;\n> on friday")
+	assert.NotContains(t, w.Body.String(), "<b>Ship it</b>")
+	assert.Contains(t, w.Body.String(), `> \[Open the build\]\(javascript:alert\(1\)\) \*\*now\*\*`)
+
+	w = export(tokenString, "?format=html&id="+cid)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "&lt;b&gt;Ship it&lt;/b&gt;")
+	assert.NotContains(t, w.Body.String(), "<b>Ship it</b>")
+
+	assert.Equal(t, http.StatusBadRequest, export(tokenString, "?format=pdf&id="+cid).Code)
+	assert.Equal(t, http.StatusForbidden, export(tokenString1, "?id="+gid).Code)
+
+	// The emailed link works without the auth header, as long as it isn't tampered with
+	body, _ := json.Marshal(map[string]string{"chat_id": cid, "format": "markdown"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/msg/export-email", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	links := mailer.Exported()
+	if assert.Len(t, links, 1) {
+		w = export("", strings.TrimPrefix(links[0], "/api/msg/export"))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Ship it")
+
+		w = export("", strings.Replace(strings.TrimPrefix(links[0], "/api/msg/export"), "format=markdown", "format=html", 1))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	}
+}
+
+func TestMessageDigests(t *testing.T) {
+	worker := core.NewCron(chatHub.DB, mailer, nil)
+	patch := func(path string) int {
+		req, _ := http.NewRequest(http.MethodPatch, path, nil)
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	digestOf := func(now time.Time) *Digest {
+		_, err := worker.SendDigests(now)
+		assert.NoError(t, err)
+		for _, digest := range mailer.Digests() {
+			if digest.To == "isongrichard234@gmail.com" {
+				return &digest
+			}
+		}
+		return nil
+	}
+	sent := func(text string, at time.Time) {
+		msg := model.UserMessage{ChatID: cid, FromID: id2, Message: text, GormModel: model.GormModel{CreatedAt: at}}
+		assert.NoError(t, chatHub.DB.AddMessage(&msg))
+	}
+
+	now := time.Now()
+	sent("Are you around?", now.Add(-2*core.DigestDelay))
+
+	// Too recent for a digest, a quick reply may still come
+	assert.Nil(t, digestOf(now.Add(-core.DigestDelay-time.Minute)))
+
+	digest := digestOf(now)
+	if assert.NotNil(t, digest) {
+		var direct *core.DigestChat
+		for i := range digest.Chats {
+			if digest.Chats[i].Name == superUserName1 {
+				direct = &digest.Chats[i]
+			}
+		}
+		if assert.NotNil(t, direct) {
+			assert.Equal(t, "Are you around?", direct.Messages[len(direct.Messages)-1].Message)
+			assert.LessOrEqual(t, len(direct.Messages), 3)
+		}
+		assert.Contains(t, digest.UnsubscribeURL, "/unsubscribe?token=")
+	}
+	// The messages are only sent once
+	assert.Nil(t, digestOf(now))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, patch("/api/user/update-digest/weekly"))
+	assert.Equal(t, http.StatusAccepted, patch("/api/user/update-digest/hourly"))
+	sent("Still there?", now.Add(-time.Minute))
+	assert.Nil(t, digestOf(now.Add(30*time.Minute)))
+	assert.NotNil(t, digestOf(now.Add(2*time.Hour)))
+
+	// The hour is counted from when the last digest went out
+	sent("Back?", now.Add(2*time.Hour+time.Minute))
+	assert.Nil(t, digestOf(now.Add(2*time.Hour+55*time.Minute)))
+	assert.NotNil(t, digestOf(now.Add(3*time.Hour)))
+
+	// The unsubscribe link works without logging in, opening it only asks to confirm so link scanners don't unsubscribe
+	if digest != nil {
+		req, _ := http.NewRequest(http.MethodGet, digest.UnsubscribeURL, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `<form method="post">`)
+
+		var user model.User
+		assert.NoError(t, chatHub.DB.FetchUser(&user, id1))
+		assert.Equal(t, model.DigestHourly, user.DigestFrequency)
+
+		req, _ = http.NewRequest(http.MethodPost, digest.UnsubscribeURL, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	sent("Ping", now.Add(3*time.Hour))
+	assert.Nil(t, digestOf(now.Add(5*time.Hour)))
+
+	req, _ := http.NewRequest(http.MethodGet, "/unsubscribe?token=nope", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	assert.Equal(t, http.StatusAccepted, patch("/api/user/update-digest/instant"))
 }
 
 func TestRenameChat(t *testing.T) {
@@ -181,12 +866,24 @@ func TestAddUserToChatToLeave(t *testing.T) {
 }
 
 func TestLeaveChat(t *testing.T) {
+	msg := model.UserMessage{ChatID: gid, FromID: id2, Message: "Heading out"}
+	assert.NoError(t, chatHub.DB.AddMessage(&msg))
+
 	req, _ := http.NewRequest(http.MethodDelete, "/api/msg/leave-chat?id="+gid, nil)
 	req.Header.Set("Authorization", "Bearer "+tokenString1)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	// Their old messages can't be edited once they left
+	body, _ := json.Marshal(map[string]string{"msg_id": msg.ID, "msg": "Back again"})
+	req, _ = http.NewRequest(http.MethodPatch, "/api/msg/edit-message", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString1)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
 }
 
 func TestAddUserToTestOwnership(t *testing.T) {
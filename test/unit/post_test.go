@@ -2,11 +2,16 @@ package unit
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"findme/core"
+	"findme/model"
 	"findme/schema"
 
 	"github.com/stretchr/testify/assert"
@@ -283,6 +288,40 @@ func TestUpdateProjectApplicationAccept(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "Application status updated successfully.")
 }
 
+func TestUpdateProjectApplicationAcceptToExistingChat(t *testing.T) {
+	body, _ := json.Marshal(defPayload)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/post/apply?id="+project.ID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString1)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	_ = json.Unmarshal(w.Body.Bytes(), &projectReq)
+
+	req, _ = http.NewRequest(http.MethodPatch, "/api/post/update-application?id="+projectReq.ReqID+"&status=accepted", bytes.NewBufferString(`{}`))
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	// The members of the project chat get a line about the applicant joining
+	var p model.Project
+	assert.NoError(t, chatHub.DB.FetchProject(&p, project.ID))
+	if assert.NotNil(t, p.ChatID) {
+		var msgs []model.UserMessage
+		assert.NoError(t, chatHub.DB.FetchChatMessages(*p.ChatID, id1, "", "", 10, &msgs))
+		if assert.NotEmpty(t, msgs) {
+			last := msgs[len(msgs)-1]
+			assert.True(t, last.System)
+			assert.Equal(t, superUserName+" accepted "+superUserName1+" to the project", last.Message)
+		}
+	}
+}
+
 func TestCreateProjectApplicationToDelete(t *testing.T) {
 	body, _ := json.Marshal(defPayload)
 
@@ -329,6 +368,172 @@ func TestClearProjectApplication(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, w.Code)
 }
 
+func TestWebhooks(t *testing.T) {
+	call := func(token, method, path string, payload any) *httptest.ResponseRecorder {
+		var body io.Reader
+		if payload != nil {
+			data, _ := json.Marshal(payload)
+			body = bytes.NewBuffer(data)
+		}
+		req, _ := http.NewRequest(method, path, body)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	deliveries := func(id string) []schema.ViewWebhookDelivery {
+		var res schema.DocWebhookDeliveries
+		_ = json.Unmarshal(call(tokenString, http.MethodGet, "/api/user/view-webhook-deliveries?id="+id, nil).Body.Bytes(), &res)
+		return res.Deliveries
+	}
+
+	secret := "a-webhook-secret"
+	received := make(chan schema.WebhookPayload, 10)
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(core.WebhookSignatureHeader) != core.SignWebhook(secret, r.Header.Get(core.WebhookTimestampHeader), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var payload schema.WebhookPayload
+		_ = json.Unmarshal(body, &payload)
+		received <- payload
+	}))
+	defer ok.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down for maintenance", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	redirect := httptest.NewServer(http.RedirectHandler(ok.URL, http.StatusFound))
+	defer redirect.Close()
+
+	// Internal addresses are refused when the webhook is created and again when connecting
+	core.AllowPrivateWebhooks(false)
+	for _, internal := range []string{ok.URL, "http://localhost:9090/metrics", "http://169.254.169.254/latest/meta-data", "http://10.0.0.5:6379", "http://[::1]:5432"} {
+		w := call(tokenString, http.MethodPost, "/api/user/create-webhook", map[string]any{"url": internal, "events": []string{model.WebhookProjectUpdated}})
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, internal)
+	}
+	_, err := core.NewWebhookClient(time.Second).Post(ok.URL, "application/json", nil)
+	assert.ErrorContains(t, err, "isn't public")
+	core.AllowPrivateWebhooks(true)
+
+	// Redirects aren't followed
+	res, err := core.NewWebhookClient(time.Second).Post(redirect.URL, "application/json", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+		_ = res.Body.Close()
+	}
+	assert.Empty(t, received)
+
+	hookPayload := map[string]any{"url": ok.URL, "events": []string{model.WebhookProjectUpdated}, "project_id": pid, "secret": secret}
+	assert.Equal(t, http.StatusForbidden, call(tokenString1, http.MethodPost, "/api/user/create-webhook", hookPayload).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, call(tokenString, http.MethodPost, "/api/user/create-webhook", map[string]any{
+		"url": ok.URL, "events": []string{model.WebhookMessageMention}, "project_id": pid,
+	}).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, call(tokenString, http.MethodPost, "/api/user/create-webhook", map[string]any{
+		"url": "ftp://example.com/hook", "events": []string{model.WebhookMessageMention},
+	}).Code)
+
+	w := call(tokenString, http.MethodPost, "/api/user/create-webhook", hookPayload)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created schema.DocWebhook
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	hook := created.Webhook
+	assert.Equal(t, secret, hook.Secret)
+	assert.True(t, hook.Active)
+
+	var list schema.DocViewWebhooks
+	_ = json.Unmarshal(call(tokenString, http.MethodGet, "/api/user/view-webhooks", nil).Body.Bytes(), &list)
+	if assert.Len(t, list.Webhooks, 1) {
+		assert.Empty(t, list.Webhooks[0].Secret)
+	}
+
+	// Updating the project sends a signed delivery
+	assert.Equal(t, http.StatusAccepted, call(tokenString, http.MethodPatch, "/api/post/edit-status?id="+pid+"&status=true", nil).Code)
+	select {
+	case payload := <-received:
+		assert.Equal(t, model.WebhookProjectUpdated, payload.Event)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the webhook wasn't delivered")
+	}
+	assert.Eventually(t, func() bool {
+		log := deliveries(hook.ID)
+		return len(log) == 1 && log[0].Status == model.StatusSuccess && log[0].StatusCode == http.StatusOK
+	}, 5*time.Second, 20*time.Millisecond)
+
+	first := deliveries(hook.ID)[0]
+	assert.Equal(t, http.StatusNotFound, call(tokenString1, http.MethodPost, "/api/user/redeliver-webhook?id="+first.ID, nil).Code)
+	w = call(tokenString, http.MethodPost, "/api/user/redeliver-webhook?id="+first.ID, nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	select {
+	case payload := <-received:
+		assert.Equal(t, first.EventID, payload.ID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the webhook wasn't redelivered")
+	}
+
+	// A delivery left pending by a restart is picked up by the poller
+	past := time.Now().Add(-time.Minute)
+	orphan := []model.WebhookDelivery{{
+		WebhookID: hook.ID, EventID: first.EventID, Event: model.WebhookProjectUpdated, Payload: `{"id":"` + first.EventID + `","event":"project.updated"}`,
+		Status: model.StatusPending, NextAttemptAt: &past,
+	}}
+	assert.NoError(t, chatHub.DB.AddWebhookDeliveries(orphan))
+	select {
+	case payload := <-received:
+		assert.Equal(t, first.EventID, payload.ID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the pending delivery wasn't picked up")
+	}
+
+	// Dispatching doesn't wait on a full queue, the deliveries left out stay leased in the db for the poller
+	full := core.NewWebhookHub(1, 1, chatHub.DB, webhookHub.Client)
+	dispatched := make(chan struct{})
+	go func() {
+		for range 3 {
+			full.Dispatch(context.Background(), model.WebhookProjectUpdated, []string{id1}, pid, schema.WebhookProject{ProjectID: pid})
+		}
+		close(dispatched)
+	}()
+	select {
+	case <-dispatched:
+		assert.Len(t, full.Jobs, 1)
+	case <-time.After(5 * time.Second):
+		t.Fatal("dispatching blocked on a full queue")
+	}
+
+	// Deliveries failing all of their attempts disable the webhook
+	assert.Equal(t, http.StatusAccepted, call(tokenString, http.MethodPatch, "/api/user/update-webhook", map[string]any{"id": hook.ID, "url": failing.URL}).Code)
+	for range model.WebhookMaxFailures {
+		webhookHub.Dispatch(context.Background(), model.WebhookProjectUpdated, []string{id1}, pid, schema.WebhookProject{ProjectID: pid})
+	}
+	assert.Eventually(t, func() bool {
+		_ = json.Unmarshal(call(tokenString, http.MethodGet, "/api/user/view-webhooks", nil).Body.Bytes(), &list)
+		return len(list.Webhooks) == 1 && !list.Webhooks[0].Active
+	}, 10*time.Second, 20*time.Millisecond)
+	assert.Equal(t, model.WebhookMaxFailures, list.Webhooks[0].Failures)
+	assert.NotNil(t, list.Webhooks[0].DisabledAt)
+
+	failed := deliveries(hook.ID)[0]
+	assert.Equal(t, model.StatusFailed, failed.Status)
+	assert.Equal(t, core.WebhookMaxAttempts, failed.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, failed.StatusCode)
+	assert.Contains(t, failed.Error, "down for maintenance")
+	assert.Equal(t, http.StatusConflict, call(tokenString, http.MethodPost, "/api/user/redeliver-webhook?id="+failed.ID, nil).Code)
+
+	// Enabling it again clears the failures
+	w = call(tokenString, http.MethodPatch, "/api/user/update-webhook", map[string]any{"id": hook.ID, "url": ok.URL, "active": true})
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	assert.True(t, created.Webhook.Active)
+	assert.Zero(t, created.Webhook.Failures)
+
+	assert.Equal(t, http.StatusNotFound, call(tokenString1, http.MethodDelete, "/api/user/delete-webhook?id="+hook.ID, nil).Code)
+	assert.Equal(t, http.StatusNoContent, call(tokenString, http.MethodDelete, "/api/user/delete-webhook?id="+hook.ID, nil).Code)
+	assert.Equal(t, http.StatusNotFound, call(tokenString, http.MethodGet, "/api/user/view-webhook-deliveries?id="+hook.ID, nil).Code)
+}
+
 func TestDeleteProject(t *testing.T) {
 	req, _ := http.NewRequest(http.MethodDelete, "/api/post/delete?id="+project.ID, nil)
 	req.Header.Set("Authorization", "Bearer "+tokenString)
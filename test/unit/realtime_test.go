@@ -0,0 +1,463 @@
+package unit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"findme/core"
+	"findme/handlers"
+	"findme/model"
+	"findme/schema"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func dialChat(t *testing.T, server *httptest.Server, token string) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/msg/ws/chat"
+	header := http.Header{"Authorization": []string{"Bearer " + token}}
+	conn, res, err := websocket.DefaultDialer.Dial(url, header)
+	if conn != nil {
+		t.Cleanup(func() { _ = conn.Close() })
+	}
+	return conn, res, err
+}
+
+func TestWSChatNotMember(t *testing.T) {
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := dialChat(t, server, tokenString)
+	assert.NoError(t, err)
+
+	assert.NoError(t, conn.WriteJSON(schema.WSEnvelope{V: schema.WSProtocolVersion, Type: schema.EventSubscribe, ID: "c-0", ChatID: uuid.NewString()}))
+	frame := readFrame(t, conn, schema.EventError)
+	assert.Equal(t, "c-0", frame.Ack)
+	assert.JSONEq(t, `{"code":403,"msg":"You aren't a member of this chat."}`, string(frame.Payload))
+}
+
+// readFrame -> Reads frames from the socket until one of the given type arrives
+func readFrame(t *testing.T, conn *websocket.Conn, frameType string) schema.WSEnvelope {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var env schema.WSEnvelope
+		if !assert.NoError(t, conn.ReadJSON(&env)) {
+			return env
+		}
+		if env.Type == frameType {
+			return env
+		}
+	}
+}
+
+func TestWSChatPersistsMessage(t *testing.T) {
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	sender, _, err := dialChat(t, server, tokenString)
+	assert.NoError(t, err)
+	receiver, _, err := dialChat(t, server, tokenString1)
+	assert.NoError(t, err)
+
+	// Give the hub time to register both sockets
+	time.Sleep(50 * time.Millisecond)
+
+	// The client supplied sender and message id are ignored
+	assert.NoError(t, sender.WriteJSON(map[string]any{
+		"v":       schema.WSProtocolVersion,
+		"type":    schema.EventMessageNew,
+		"id":      "c-1",
+		"chat_id": cid,
+		"payload": map[string]any{"msg": "Sent over the socket", "uid": id2, "id": "spoofed"},
+	}))
+
+	ack := readFrame(t, sender, schema.EventAck)
+	assert.Equal(t, "c-1", ack.Ack)
+
+	event := readFrame(t, receiver, schema.EventMessageNew)
+	assert.Equal(t, ack.ID, event.ID)
+	assert.Equal(t, cid, event.ChatID)
+
+	var got schema.ViewMessage
+	assert.NoError(t, json.Unmarshal(event.Payload, &got))
+	assert.Equal(t, "Sent over the socket", got.Message)
+	assert.Equal(t, id1, got.UserID)
+	assert.NotEqual(t, "spoofed", got.ID)
+	assert.False(t, got.Sent.IsZero())
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/msg/view-hist?id="+cid, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Contains(t, w.Body.String(), got.ID)
+}
+
+func TestWSChatFrames(t *testing.T) {
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	sender, _, err := dialChat(t, server, tokenString)
+	assert.NoError(t, err)
+	receiver, _, err := dialChat(t, server, tokenString1)
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+
+	// Typing indicators reach the other members only
+	assert.NoError(t, sender.WriteJSON(schema.WSEnvelope{V: schema.WSProtocolVersion, Type: schema.EventTypingStart, ID: "c-2", ChatID: cid}))
+	assert.Equal(t, "c-2", readFrame(t, sender, schema.EventAck).Ack)
+
+	typing := readFrame(t, receiver, schema.EventTypingStart)
+	assert.JSONEq(t, `{"uid":"`+id1+`"}`, string(typing.Payload))
+
+	// Unknown types and newer protocol versions are rejected without closing the socket
+	assert.NoError(t, sender.WriteJSON(schema.WSEnvelope{V: schema.WSProtocolVersion, Type: "chat.explode", ID: "c-3", ChatID: cid}))
+	frame := readFrame(t, sender, schema.EventError)
+	assert.Equal(t, "c-3", frame.Ack)
+
+	assert.NoError(t, sender.WriteJSON(schema.WSEnvelope{V: schema.WSProtocolVersion + 1, Type: schema.EventTypingStop, ID: "c-4", ChatID: cid}))
+	frame = readFrame(t, sender, schema.EventError)
+	assert.JSONEq(t, `{"code":426,"msg":"Unsupported protocol version."}`, string(frame.Payload))
+
+	// REST edits are pushed to the open sockets, correlated with the request id
+	msg := model.UserMessage{ChatID: cid, FromID: id1, Message: "Before the edit"}
+	assert.NoError(t, chatHub.DB.AddMessage(&msg))
+
+	body, _ := json.Marshal(schema.EditMessage{ID: msg.ID, Message: "After the edit"})
+	req, _ := http.NewRequest(http.MethodPatch, "/api/msg/edit-message", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+	req.Header.Set(handlers.RequestIDHeader, "edit-request-0001")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	edited := readFrame(t, receiver, schema.EventMessageEdited)
+	assert.Equal(t, "edit-request-0001", edited.ID)
+	assert.Contains(t, string(edited.Payload), "After the edit")
+
+	// Read receipts are only accepted for messages of the chat
+	assert.NoError(t, receiver.WriteJSON(schema.WSEnvelope{
+		V:       schema.WSProtocolVersion,
+		Type:    schema.EventMessageRead,
+		ID:      "c-5",
+		ChatID:  cid,
+		Payload: json.RawMessage(`{"msg_id":"` + msg.ID + `"}`),
+	}))
+	receipt := readFrame(t, sender, schema.EventMessageRead)
+	assert.Contains(t, string(receipt.Payload), id2)
+
+	// Reactions reach every member, the reacting user comes from the socket
+	assert.NoError(t, receiver.WriteJSON(schema.WSEnvelope{
+		V:       schema.WSProtocolVersion,
+		Type:    schema.EventReactionAdded,
+		ID:      "c-6",
+		ChatID:  cid,
+		Payload: json.RawMessage(`{"msg_id":"` + msg.ID + `","emoji":"🔥"}`),
+	}))
+	reaction := readFrame(t, sender, schema.EventReactionAdded)
+	assert.JSONEq(t, `{"msg_id":"`+msg.ID+`","uid":"`+id2+`","emoji":"🔥"}`, string(reaction.Payload))
+}
+
+func TestWSChatSubscriptions(t *testing.T) {
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// One socket receives the events of every chat of the user
+	conn, _, err := dialChat(t, server, tokenString)
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+
+	chatHub.Publish(core.NewEvent(schema.EventChatRenamed, gid, "", schema.WSChatRenamed{Name: "Builders", By: id1}))
+	assert.Equal(t, gid, readFrame(t, conn, schema.EventChatRenamed).ChatID)
+
+	// Personal events reach the socket without any subscription
+	chatHub.PublishUser(id1, core.NewEvent(schema.EventFriendRequest, "", "", schema.FriendReqStatus{Username: "someone"}))
+	assert.Empty(t, readFrame(t, conn, schema.EventFriendRequest).ChatID)
+
+	// Unsubscribed chats are muted until subscribed to again
+	assert.NoError(t, conn.WriteJSON(schema.WSEnvelope{V: schema.WSProtocolVersion, Type: schema.EventUnsubscribe, ID: "c-6", ChatID: gid}))
+	assert.Equal(t, "c-6", readFrame(t, conn, schema.EventAck).Ack)
+
+	chatHub.Publish(core.NewEvent(schema.EventChatRenamed, gid, "", schema.WSChatRenamed{Name: "Muted", By: id1}))
+	chatHub.Publish(core.NewEvent(schema.EventChatRenamed, cid, "", schema.WSChatRenamed{Name: "Heard", By: id1}))
+	assert.Equal(t, cid, readFrame(t, conn, schema.EventChatRenamed).ChatID)
+
+	assert.NoError(t, conn.WriteJSON(schema.WSEnvelope{V: schema.WSProtocolVersion, Type: schema.EventSubscribe, ID: "c-7", ChatID: gid}))
+	assert.Equal(t, "c-7", readFrame(t, conn, schema.EventAck).Ack)
+
+	chatHub.Publish(core.NewEvent(schema.EventChatRenamed, gid, "", schema.WSChatRenamed{Name: "Back", By: id1}))
+	assert.Equal(t, gid, readFrame(t, conn, schema.EventChatRenamed).ChatID)
+}
+
+func TestWSChatKick(t *testing.T) {
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := dialChat(t, server, tokenString1)
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+
+	chatHub.KickUser(cid, id2, "You were removed from this chat.")
+
+	// The socket stays open, only the chat is dropped from it
+	frame := readFrame(t, conn, schema.EventChatClosed)
+	assert.Equal(t, cid, frame.ChatID)
+	assert.JSONEq(t, `{"reason":"You were removed from this chat."}`, string(frame.Payload))
+
+	chatHub.Publish(core.NewEvent(schema.EventChatRenamed, cid, "", schema.WSChatRenamed{Name: "Gone", By: id1}))
+	chatHub.PublishUser(id2, core.NewEvent(schema.EventFriendRequest, "", "", schema.FriendReqStatus{Username: "someone"}))
+
+	var next schema.WSEnvelope
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	assert.NoError(t, conn.ReadJSON(&next))
+	assert.Equal(t, schema.EventFriendRequest, next.Type)
+}
+
+func TestWSChatRateLimit(t *testing.T) {
+	rate, burst := chatHub.MsgRate, chatHub.MsgBurst
+	chatHub.MsgRate, chatHub.MsgBurst = 0.01, 2
+	defer func() { chatHub.MsgRate, chatHub.MsgBurst = rate, burst }()
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := dialChat(t, server, tokenString)
+	assert.NoError(t, err)
+
+	// Rate limited frames are answered with errors until too many arrive in a row
+	for range 3 {
+		_ = conn.WriteJSON(schema.WSEnvelope{V: schema.WSProtocolVersion, Type: schema.EventTypingStart, ChatID: cid})
+	}
+	frame := readFrame(t, conn, schema.EventError)
+	assert.JSONEq(t, `{"code":429,"msg":"Rate limit exceeded."}`, string(frame.Payload))
+
+	for range 10 {
+		_ = conn.WriteJSON(schema.WSEnvelope{V: schema.WSProtocolVersion, Type: schema.EventTypingStart, ChatID: cid})
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		if _, _, err = conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation))
+}
+
+// newReplica -> Starts another app instance sharing the test db and the given bus
+func newReplica(t *testing.T, bus core.ChatBus) (*core.ChatHub, *httptest.Server) {
+	t.Helper()
+
+	hub := core.NewChatHub(chatHub.DB, bus, 20)
+	go hub.Run()
+
+	service := handlers.NewService(chatHub.DB, NewCacheMock(), NewEmailHubMock(), NewGitMock(), NewTranscMock(),
+		NewEmbeddingMock(), NewRecommendationMock(), &http.Client{}, hub, NewCronMock(), storage, core.NewNotificationService(chatHub.DB, hub), webhookHub)
+	server := httptest.NewServer(getTestRouter(service))
+	t.Cleanup(server.Close)
+	return hub, server
+}
+
+func TestWSChatReplicas(t *testing.T) {
+	bus := NewBusMock()
+	hubA, serverA := newReplica(t, bus)
+	hubB, serverB := newReplica(t, bus)
+
+	sender, _, err := dialChat(t, serverA, tokenString)
+	assert.NoError(t, err)
+	receiver, _, err := dialChat(t, serverB, tokenString1)
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+
+	// Both users show up in the presence registry, whichever replica is asked
+	presence, err := hubA.Presence([]string{id1, id2})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{id1: core.PresenceOnline, id2: core.PresenceOnline}, presence)
+
+	// Messages sent on a replica reach the sockets of the other one
+	assert.NoError(t, sender.WriteJSON(schema.WSEnvelope{
+		V:       schema.WSProtocolVersion,
+		Type:    schema.EventMessageNew,
+		ID:      "c-8",
+		ChatID:  cid,
+		Payload: json.RawMessage(`{"msg":"Across replicas"}`),
+	}))
+	event := readFrame(t, receiver, schema.EventMessageNew)
+	assert.Contains(t, string(event.Payload), "Across replicas")
+
+	// The replica that published the event ignores it when it comes back from the bus
+	readFrame(t, sender, schema.EventMessageNew)
+	hubA.PublishUser(id1, core.NewEvent(schema.EventFriendRequest, "", "", schema.FriendReqStatus{Username: "someone"}))
+
+	var next schema.WSEnvelope
+	_ = sender.SetReadDeadline(time.Now().Add(2 * time.Second))
+	assert.NoError(t, sender.ReadJSON(&next))
+	assert.Equal(t, schema.EventFriendRequest, next.Type)
+
+	// Kicks follow the user to the replica they are connected to
+	hubA.KickUser(cid, id2, "You were removed from this chat.")
+	assert.Equal(t, cid, readFrame(t, receiver, schema.EventChatClosed).ChatID)
+
+	_ = receiver.Close()
+	assert.Eventually(t, func() bool {
+		presence, _ := hubB.Presence([]string{id2})
+		return presence[id2] == core.PresenceOffline
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+// chatPresence -> Returns the presence shown to the second user for the chat they share with the first one
+func chatPresence(t *testing.T) schema.ViewChat {
+	t.Helper()
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/msg/view-chats", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString1)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var res struct {
+		Msg []schema.ViewChat `json:"msg"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	for _, chat := range res.Msg {
+		if chat.CID == cid {
+			return chat
+		}
+	}
+	t.Fatalf("chat %s not found", cid)
+	return schema.ViewChat{}
+}
+
+func TestWSChatPresence(t *testing.T) {
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := dialChat(t, server, tokenString)
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, core.PresenceOnline, chatPresence(t).Presence)
+
+	assert.NoError(t, conn.WriteJSON(schema.WSEnvelope{
+		V:       schema.WSProtocolVersion,
+		Type:    schema.EventPresenceSet,
+		ID:      "c-9",
+		Payload: json.RawMessage(`{"status":"away"}`),
+	}))
+	assert.Equal(t, "c-9", readFrame(t, conn, schema.EventAck).Ack)
+	assert.Equal(t, core.PresenceAway, chatPresence(t).Presence)
+
+	// Users hiding their presence look offline without a last seen time
+	req, _ := http.NewRequest(http.MethodPatch, "/api/user/update-presence-visibility/false", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, core.PresenceOffline, chatPresence(t).Presence)
+
+	req, _ = http.NewRequest(http.MethodPatch, "/api/user/update-presence-visibility/true", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Closing the socket records the last seen time
+	_ = conn.Close()
+	assert.Eventually(t, func() bool {
+		chat := chatPresence(t)
+		return chat.Presence == core.PresenceOffline && chat.LastSeen != nil
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+// openEvents -> Opens the event stream of the first user, resuming after lastID when set
+func openEvents(t *testing.T, server *httptest.Server, lastID string) *bufio.Reader {
+	t.Helper()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/msg/sse", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	res, err := client.Do(req)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { _ = res.Body.Close() })
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	// Once the retry delay comes the stream is registered on the hub
+	body := bufio.NewReader(res.Body)
+	line, _ := body.ReadString('\n')
+	assert.Equal(t, "retry: 3000\n", line)
+	return body
+}
+
+// readEvent -> Reads events from the stream until one of the given type arrives, returns it with its id
+func readEvent(t *testing.T, body *bufio.Reader, eventType string) (string, schema.WSEnvelope) {
+	t.Helper()
+
+	var id string
+	for {
+		line, err := body.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return "", schema.WSEnvelope{}
+		}
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id: "))
+		case strings.HasPrefix(line, "data: "):
+			var env schema.WSEnvelope
+			assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &env))
+			if env.Type == eventType {
+				return id, env
+			}
+			id = ""
+		}
+	}
+}
+
+func TestSSEChat(t *testing.T) {
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	send := func(text string) {
+		body, _ := json.Marshal(map[string]string{"chat_id": cid, "msg": text})
+		req, _ := http.NewRequest(http.MethodPost, "/api/msg/send-message", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer "+tokenString1)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	stream := openEvents(t, server, "")
+	send("Over the event stream")
+	id, event := readEvent(t, stream, schema.EventMessageNew)
+	assert.Contains(t, string(event.Payload), "Over the event stream")
+	assert.Equal(t, strconv.FormatInt(event.Seq, 10), id)
+
+	// Events sent while the stream was down are replayed once it resumes, without the ones already received
+	send("While reconnecting")
+	stream = openEvents(t, server, id)
+	_, event = readEvent(t, stream, schema.EventMessageNew)
+	assert.Contains(t, string(event.Payload), "While reconnecting")
+
+	// Past the replay window the client is told to refetch its state
+	stream = openEvents(t, server, "1")
+	readEvent(t, stream, schema.EventResync)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/msg/sse?last_event_id=abc", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,49 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"findme/core"
+	"findme/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormTracingSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(prev)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Use(core.GormTracing{}))
+	assert.NoError(t, db.AutoMigrate(&model.Skill{}))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	gdb := core.NewGormDB(db).WithContext(ctx)
+	assert.NoError(t, gdb.AddSkills(&[]*model.Skill{{Name: "go"}}))
+
+	var skills []*model.Skill
+	assert.NoError(t, gdb.FindExistingSkills(&skills, []string{"go"}))
+	parent.End()
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			continue
+		}
+		names = append(names, span.Name())
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+	}
+	assert.Contains(t, names, "gorm.create")
+	assert.Contains(t, names, "gorm.query")
+}
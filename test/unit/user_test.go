@@ -2,14 +2,20 @@ package unit
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"findme/core"
 	"findme/handlers"
+	"findme/model"
+	"findme/schema"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -266,6 +272,153 @@ func TestViewFriendReq(t *testing.T) {
 	assert.Contains(t, w.Body.String(), superUserName1)
 }
 
+func TestNotifications(t *testing.T) {
+	call := func(method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	list := func(path string) schema.ViewNotifications {
+		w := call(http.MethodGet, path)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res schema.ViewNotifications
+		_ = json.Unmarshal(w.Body.Bytes(), &res)
+		return res
+	}
+
+	// The friend request sent above is in the notification center
+	res := list("/api/user/view-notifications")
+	if !assert.NotEmpty(t, res.Notifications) {
+		return
+	}
+	latest := res.Notifications[0]
+	assert.Equal(t, model.NotifyFriendRequest, latest.Type)
+	assert.Contains(t, latest.Body, superUserName1)
+	assert.Equal(t, friendreq.ID, *latest.RefID)
+	assert.Nil(t, latest.ReadAt)
+	assert.Equal(t, int64(len(res.Notifications)), res.Unread)
+
+	w := call(http.MethodGet, "/api/user/unread-notifications")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`"unread":%d`, res.Unread))
+
+	if len(res.Notifications) > 1 {
+		page := list("/api/user/view-notifications?limit=1")
+		assert.True(t, page.HasMore)
+		assert.Equal(t, latest.ID, page.Notifications[0].ID)
+		next := list("/api/user/view-notifications?limit=1&before=" + latest.ID)
+		assert.Equal(t, res.Notifications[1].ID, next.Notifications[0].ID)
+	}
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodGet, "/api/user/view-notifications?before=nope").Code)
+
+	assert.Equal(t, http.StatusAccepted, call(http.MethodPatch, "/api/user/read-notification?id="+latest.ID).Code)
+	assert.Equal(t, res.Unread-1, list("/api/user/view-notifications").Unread)
+
+	// Other users can't touch the notification
+	req, _ := http.NewRequest(http.MethodDelete, "/api/user/delete-notification?id="+latest.ID, nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString1)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	assert.Equal(t, http.StatusNoContent, call(http.MethodDelete, "/api/user/delete-notification?id="+latest.ID).Code)
+	assert.Equal(t, http.StatusNotFound, call(http.MethodPatch, "/api/user/read-notification?id="+latest.ID).Code)
+
+	assert.Equal(t, http.StatusAccepted, call(http.MethodPatch, "/api/user/read-all-notifications").Code)
+	res = list("/api/user/view-notifications")
+	assert.Zero(t, res.Unread)
+	for _, n := range res.Notifications {
+		assert.NotNil(t, n.ReadAt)
+		assert.NotEqual(t, latest.ID, n.ID)
+	}
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	update := func(payload map[string]any) int {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(http.MethodPatch, "/api/user/update-notification-preference", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/user/view-notification-preferences", nil)
+	req.Header.Set("Authorization", "Bearer "+userToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res struct {
+		Preferences []schema.ViewNotificationPreference `json:"preferences"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	assert.Len(t, res.Preferences, len(model.NotifyCategories))
+	for _, pref := range res.Preferences {
+		if pref.Category == model.CategoryMessages {
+			assert.Nil(t, pref.Email)
+			assert.True(t, *pref.Digest)
+		}
+	}
+
+	assert.Equal(t, http.StatusUnprocessableEntity, update(map[string]any{"category": "newsletter", "email": false}))
+	assert.Equal(t, http.StatusUnprocessableEntity, update(map[string]any{"category": model.CategoryFriendRequests, "digest": false}))
+	assert.Equal(t, http.StatusAccepted, update(map[string]any{"category": model.CategoryFriendRequests, "in_app": false, "email": false}))
+
+	// The in-app notifications of the category are dropped, the transactional ones still come
+	ctx := context.Background()
+	notifier := core.NewNotificationService(chatHub.DB, nil)
+	before, _ := notifier.Unread(ctx, id1)
+	notifier.Notify(ctx, id1, model.NotifyFriendRequest, "New friend request", "Someone sent you a friend request.", nil)
+	after, _ := notifier.Unread(ctx, id1)
+	assert.Equal(t, before, after)
+	notifier.Notify(ctx, id1, model.NotifyPaymentFailed, "Payment failed", "The payment failed.", nil)
+	after, _ = notifier.Unread(ctx, id1)
+	assert.Equal(t, before+1, after)
+
+	hub := core.NewEmailHub(10, 1, &EmailMock{})
+	hub.DB = chatHub.DB
+	hub.AppURL = "http://findme.test"
+	queued := func() *core.EmailJob {
+		select {
+		case job := <-hub.Jobs:
+			return job
+		default:
+			return nil
+		}
+	}
+	email := "isongrichard234@gmail.com"
+
+	hub.QueueFriendReqEmail(ctx, superUserName1, superUserName, "Hey", "", email)
+	assert.Nil(t, queued())
+
+	// Transactional mail has no unsubscribe link and can't be turned off
+	hub.QueueForgotPassEmail(ctx, email, superUserName, "123456")
+	if job := queued(); assert.NotNil(t, job) {
+		assert.Empty(t, job.Unsubscribe)
+	}
+
+	hub.QueueProjectApplication(ctx, superUserName1, superUserName, "Let me in", "", email)
+	job := queued()
+	if assert.NotNil(t, job) {
+		assert.Contains(t, job.Unsubscribe, "category="+model.CategoryApplications)
+
+		// The one-click unsubscribe of the mail clients
+		req, _ = http.NewRequest(http.MethodPost, strings.TrimPrefix(job.Unsubscribe, hub.AppURL), strings.NewReader("List-Unsubscribe=One-Click"))
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		hub.QueueProjectApplication(ctx, superUserName1, superUserName, "Let me in", "", email)
+		assert.Nil(t, queued())
+	}
+
+	assert.Equal(t, http.StatusAccepted, update(map[string]any{"category": model.CategoryFriendRequests, "in_app": true, "email": true}))
+	assert.Equal(t, http.StatusAccepted, update(map[string]any{"category": model.CategoryApplications, "email": true}))
+	_ = chatHub.DB.MarkAllNotificationsRead(id1, time.Now())
+}
+
 func TestUpdateFriendReqInvalidStatus(t *testing.T) {
 	req, _ := http.NewRequest(http.MethodPatch, "/api/user/update-user-req?id="+friendreq.ID+"&status=invalid", nil)
 	req.Header.Set("Authorization", "Bearer "+userToken)
@@ -328,6 +481,7 @@ func TestViewUserFriends(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Contains(t, w.Body.String(), superUserName)
+	assert.Contains(t, w.Body.String(), `"Presence":"offline"`)
 }
 
 func TestViewUserFriendsByID(t *testing.T) {